@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/jedib0t/go-pretty/v6/table"
@@ -42,7 +43,7 @@ var clientCmd = &cobra.Command{
 			return fmt.Errorf("empty input")
 		}
 		repo := db.NewRemoteRepository(cfg.Client.RemoteAddr, cfg.Client.ServerToken, http.DefaultClient)
-		if err := repo.Ping(); err != nil {
+		if err := repo.Ping(cmd.Context()); err != nil {
 			log.Fatalf("cant connect to server: %s", err.Error())
 		}
 
@@ -52,61 +53,189 @@ var clientCmd = &cobra.Command{
 		}
 		switch parsedInput.Action {
 		case models.HumanActionInfo:
-			task, err := repo.Get(*parsedInput.ActionUUID)
+			task, err := repo.Get(cmd.Context(), *parsedInput.ActionUUID)
 			if err != nil {
 				log.Fatalf("cant fetch task: %s", err.Error())
 			}
+			if task == nil {
+				log.Fatalf("task not found: %s", *parsedInput.ActionUUID)
+			}
 			outputTasks([]*models.Task{task})
 			return nil
-		case models.HumanActionAdd:
-			task := models.NewTask()
-			parsedInput.Options.ModifyTask(task)
-			if err := repo.Insert(task); err != nil {
-				log.Fatalf("cant insert task: %s", err.Error())
+		case models.HumanActionAdd, models.HumanActionFromTemplate:
+			tasks, err := parsedInput.Options.NewTasks(time.Now())
+			if err != nil {
+				log.Fatalf("cant create task: %s", err.Error())
 			}
-			outputTasks([]*models.Task{task})
+			if err := repo.Insert(cmd.Context(), tasks...); err != nil {
+				log.Fatalf("cant insert tasks: %s", err.Error())
+			}
+			outputTasks(tasks)
 			return nil
-		case models.HumanActionModify, models.HumanActionDone:
-			task, err := repo.Get(*parsedInput.ActionUUID)
+		case models.HumanActionModify, models.HumanActionDone, models.HumanActionAnnotate:
+			task, err := repo.Get(cmd.Context(), *parsedInput.ActionUUID)
 			if err != nil {
 				log.Fatalf("cant fetch task: %s", err.Error())
 			}
+			if task == nil {
+				log.Fatalf("task not found: %s", *parsedInput.ActionUUID)
+			}
+			previousStatus := task.Status
 			parsedInput.Options.ModifyTask(task)
-			if err := repo.Insert(task); err != nil {
+			isClosing := models.IsClosing(previousStatus, task.Status)
+			var subtasks, subtasksNext []*models.Task
+			if isClosing && parsedInput.Options.CompleteSubtasks {
+				subtasks, subtasksNext, err = models.CompleteSubtasks(cmd.Context(), repo, task)
+				if err != nil {
+					log.Fatalf("cant complete subtasks: %s", err.Error())
+				}
+			}
+			next, err := models.InsertTask(cmd.Context(), repo, previousStatus, task, append(subtasks, subtasksNext...)...)
+			if err != nil {
 				log.Fatalf("cant insert task: %s", err.Error())
 			}
+			if isClosing {
+				if parsedInput.Options.CompleteSubtasks {
+					log.Printf("completed %d subtasks", len(subtasks))
+				} else {
+					subtasks, err := models.PendingSubtasks(cmd.Context(), repo, task.UUID)
+					if err != nil {
+						log.Fatalf("cant get subtasks: %s", err.Error())
+					}
+					if len(subtasks) > 0 {
+						log.Printf("task still has %d pending subtasks, use 'done %s subtasks:complete' to complete them", len(subtasks), task.UUID)
+					}
+				}
+			}
+			if next != nil {
+				outputTasks([]*models.Task{task, next})
+				return nil
+			}
 			outputTasks([]*models.Task{task})
 			return nil
 		case models.HumanActionCopy:
-			task, err := repo.Get(*parsedInput.ActionUUID)
+			task, err := repo.Get(cmd.Context(), *parsedInput.ActionUUID)
 			if err != nil {
 				log.Fatalf("cant fetch task: %s", err.Error())
 			}
+			if task == nil {
+				log.Fatalf("task not found: %s", *parsedInput.ActionUUID)
+			}
 			parsedInput.Options.ModifyTask(task)
 			task = task.Clone(true)
 			if task.Status != models.Pending && parsedInput.Options.Status == nil {
 				task.Status = models.Pending
 			}
-			if err := repo.Insert(task); err != nil {
+			if err := repo.Insert(cmd.Context(), task); err != nil {
 				log.Fatalf("cant insert task: %s", err.Error())
 			}
 			outputTasks([]*models.Task{task})
 			return nil
 		case models.HumanActionList:
-			tasks, err := repo.All()
+			filter, err := contextListFilter(cmd.Context(), repo, &parsedInput.Options)
+			if err != nil {
+				log.Fatalf("cant apply context: %s", err.Error())
+			}
+			result, err := repo.Find(cmd.Context(), filter, parsedInput.Options.ToPage(models.OrderDefault, 0))
 			if err != nil {
 				log.Fatalf("cant get tasks: %s", err.Error())
 			}
-			tasks = parsedInput.Options.ToListFilter().Apply(tasks)
-			outputTasks(tasks)
+			outputTasks(result.Tasks)
 			return nil
 		case models.HumanActionAgenda:
-			tasks, err := repo.All()
+			filter, err := contextListFilter(cmd.Context(), repo, &parsedInput.Options)
+			if err != nil {
+				log.Fatalf("cant apply context: %s", err.Error())
+			}
+			result, err := repo.Find(cmd.Context(), filter, models.Page{})
 			if err != nil {
 				log.Fatalf("cant get tasks: %s", err.Error())
 			}
-			tasks = models.NewDefaultListFilter().Apply(tasks)
-			outputAgenda(models.Agenda(tasks))
+			if err := models.ResolveBlockers(cmd.Context(), repo, result.Tasks...); err != nil {
+				log.Fatalf("cant resolve dependencies: %s", err.Error())
+			}
+			outputAgenda(models.Agenda(result.Tasks))
+			return nil
+		case models.HumanActionStart, models.HumanActionStop:
+			task, err := repo.Get(cmd.Context(), *parsedInput.ActionUUID)
+			if err != nil {
+				log.Fatalf("cant fetch task: %s", err.Error())
+			}
+			if task == nil {
+				log.Fatalf("task not found: %s", *parsedInput.ActionUUID)
+			}
+			if parsedInput.Action == models.HumanActionStart {
+				stopped, err := models.StartTracking(cmd.Context(), repo, task, time.Now())
+				if err != nil {
+					log.Fatalf("cant start task: %s", err.Error())
+				}
+				for _, t := range stopped {
+					log.Printf("stopped %s: %s", t.UUID, t.Description)
+				}
+			} else {
+				if err := task.StopTracking(time.Now()); err != nil {
+					log.Fatalf("cant stop task: %s", err.Error())
+				}
+				if err := repo.Insert(cmd.Context(), task); err != nil {
+					log.Fatalf("cant insert task: %s", err.Error())
+				}
+			}
+			outputTasks([]*models.Task{task})
+			return nil
+		case models.HumanActionHistory:
+			history, err := repo.History(cmd.Context(), *parsedInput.ActionUUID)
+			if err != nil {
+				log.Fatalf("cant get task history: %s", err.Error())
+			}
+			outputHistory(history)
+			return nil
+		case models.HumanActionRenameProject, models.HumanActionRenameTag:
+			preview, err := repo.Rename(cmd.Context(), parsedInput.Options.Rename)
+			if err != nil {
+				log.Fatalf("cant rename: %s", err.Error())
+			}
+			outputRename(preview)
+			return nil
+		case models.HumanActionFilters:
+			filters, err := repo.Filters(cmd.Context())
+			if err != nil {
+				log.Fatalf("cant get filters: %s", err.Error())
+			}
+			outputFilters(filters)
+			return nil
+		case models.HumanActionSaveFilter:
+			filters, err := repo.Filters(cmd.Context())
+			if err != nil {
+				log.Fatalf("cant get filters: %s", err.Error())
+			}
+			filter := parsedInput.Options.SavedFilter
+			if existing := models.FindSavedFilter(filters, filter.Name); existing != nil {
+				filter.Active = existing.Active
+			}
+			if err := repo.SaveFilter(cmd.Context(), filter); err != nil {
+				log.Fatalf("cant save filter: %s", err.Error())
+			}
+			outputFilters([]*models.SavedFilter{filter})
+			return nil
+		case models.HumanActionDeleteFilter:
+			if err := repo.DeleteFilter(cmd.Context(), parsedInput.Options.SavedFilter); err != nil {
+				log.Fatalf("cant delete filter: %s", err.Error())
+			}
+			return nil
+		case models.HumanActionContext:
+			filters, err := repo.Filters(cmd.Context())
+			if err != nil {
+				log.Fatalf("cant get filters: %s", err.Error())
+			}
+			filter, err := models.SwitchContext(filters, parsedInput.Options.Context)
+			if err != nil {
+				log.Fatalf("cant switch context: %s", err.Error())
+			}
+			if filter != nil {
+				if err := repo.SaveFilter(cmd.Context(), filter); err != nil {
+					log.Fatalf("cant save filter: %s", err.Error())
+				}
+			}
 			return nil
 		default:
 			log.Fatalf("unkown action: %s", parsedInput.Action)
@@ -115,6 +244,32 @@ var clientCmd = &cobra.Command{
 	},
 }
 
+// contextListFilter applies the saved filter of the context option or the active one.
+func contextListFilter(ctx context.Context, repo models.Repository, options *models.HumanInputOptions) (*models.ListFilter, error) {
+	filters, err := repo.Filters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cant get filters: %w", err)
+	}
+	return options.ContextListFilter(filters)
+}
+
+func outputFilters(filters []*models.SavedFilter) {
+	if clientOutput == "json" {
+		fmt.Println(prettyOutputJson(filters))
+		return
+	}
+	tableWriter := table.NewWriter()
+	tableWriter.AppendHeader(table.Row{"name", "query", "context"})
+	for _, filter := range filters {
+		active := ""
+		if filter.Active {
+			active = "active"
+		}
+		tableWriter.AppendRow(table.Row{filter.Name, filter.Query, active})
+	}
+	fmt.Println(outputTableWriter(tableWriter))
+}
+
 func outputAgenda(agenda []models.TaskGroup) {
 	if clientOutput == "json" {
 		fmt.Println(prettyOutputJson(agenda))
@@ -131,6 +286,30 @@ func outputTasks(tasks []*models.Task) {
 	}
 }
 
+func outputHistory(history []*models.TaskHistoryEntry) {
+	if clientOutput == "json" {
+		fmt.Println(prettyOutputJson(history))
+	} else {
+		fmt.Println(outputTableWriter(prettyOutputHistoryTable(history)))
+	}
+}
+
+func outputRename(preview *models.RenamePreview) {
+	if clientOutput == "json" {
+		fmt.Println(prettyOutputJson(preview))
+		return
+	}
+	fmt.Println(outputTableWriter(prettyOutputRenameTable(preview)))
+	if preview.Merged > 0 {
+		fmt.Printf("merged with %d tasks of %s %s\n", preview.Merged, preview.Rename.Kind, preview.Rename.To)
+	}
+	if preview.Rename.DryRun {
+		fmt.Printf("preview: %d tasks would be renamed\n", preview.Tasks())
+	} else {
+		fmt.Printf("renamed %d tasks\n", preview.Tasks())
+	}
+}
+
 func outputTableWriter(tableWriter table.Writer) string {
 	switch clientOutput {
 	case "table":
@@ -175,7 +354,7 @@ func prettyOutputTasksTable(tasks []*models.Task) table.Writer {
 }
 
 func tableGetTasksHeaderRow() table.Row {
-	return table.Row{"uuid", "status", "project", "tags", "description", "due", "notify"}
+	return table.Row{"uuid", "status", "project", "tags", "priority", "description", "checklist", "due", "notify", "scheduled", "wait", "recur", "parent", "uda", "tracked", "completed", "modified"}
 }
 
 func tableGetTasksBodyRows(tasks []*models.Task) []table.Row {
@@ -189,19 +368,71 @@ func tableGetTasksBodyRows(tasks []*models.Task) []table.Row {
 
 	result := []table.Row{}
 	for _, task := range tasks {
+		parent := ""
+		if task.Parent != nil {
+			parent = task.Parent.String()
+		}
+		tracked := task.TrackedTime()
+		if task.IsActive() {
+			tracked += " ▶"
+		}
+		description := task.Description
+		for _, annotation := range task.Annotations {
+			description += "\n  " + annotation.At.In(time.Local).Format("2006-01-02 15:04") + " " + annotation.Text
+		}
+		uda := []string{}
+		for _, value := range task.UDAValues() {
+			uda = append(uda, value.Definition.Name+": "+value.String())
+		}
 		result = append(result, table.Row{
 			task.UUID.String(),
 			task.Status,
 			task.Project,
 			strings.Join(task.Tags, ", "),
-			task.Description,
+			task.Priority,
+			description,
+			task.ChecklistProgress(),
 			mbDate(task.Due),
 			mbDate(task.Notify),
+			mbDate(task.Scheduled),
+			mbDate(task.Wait),
+			task.Recur,
+			parent,
+			strings.Join(uda, "\n"),
+			tracked,
+			mbDate(task.CompletedAt),
+			mbDate(task.ModifiedAt),
 		})
 	}
 	return result
 }
 
+func prettyOutputHistoryTable(history []*models.TaskHistoryEntry) table.Writer {
+	tableWriter := table.NewWriter()
+	tableWriter.AppendHeader(table.Row{"at", "actor", "field", "old", "new"})
+	for _, entry := range history {
+		for _, change := range entry.Changes {
+			tableWriter.AppendRow(table.Row{
+				entry.At.In(time.Local).Format("2006-01-02 15:04"),
+				entry.Actor,
+				change.Field,
+				change.Old,
+				change.New,
+			})
+		}
+	}
+	return tableWriter
+}
+
+func prettyOutputRenameTable(preview *models.RenamePreview) table.Writer {
+	tableWriter := table.NewWriter()
+	tableWriter.AppendHeader(table.Row{"old " + string(preview.Rename.Kind), "new " + string(preview.Rename.Kind), "tasks"})
+	for _, row := range preview.Rows {
+		tableWriter.AppendRow(table.Row{row.From, row.To, row.Tasks})
+	}
+	return tableWriter
+}
+
 func prettyOutputJson(value any) string {
 	data, err := json.MarshalIndent(value, "", "  ")
 	if err != nil {
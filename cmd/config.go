@@ -6,6 +6,7 @@ import (
 	"path"
 	"time"
 
+	"github.com/paragor/todo/pkg/models"
 	"github.com/spf13/cobra"
 	"github.com/zitadel/oidc/v3/pkg/oidc"
 	"gopkg.in/yaml.v3"
@@ -44,6 +45,9 @@ type Config struct {
 			Postgresql struct {
 				Url string `yaml:"url"`
 			} `yaml:"postgresql"`
+			Sqlite struct {
+				Path string `yaml:"path"`
+			} `yaml:"sqlite"`
 		} `yaml:"database"`
 		ListenAddr  string `yaml:"listen_addr"`
 		PublicUrl   string `yaml:"public_url"`
@@ -66,7 +70,12 @@ type Config struct {
 			CookieKey       string   `yaml:"cookie_key"`
 			WhitelistEmails []string `yaml:"whitelist_emails"`
 		} `yaml:"oidc_auth"`
-		Telegram struct {
+		// Users are accounts of the team in addition to the default user, which uses credentials of the auth
+		// sections above: userId of telegram, client_token, base_auth login and whitelist_emails of oidc.
+		Users models.Users `yaml:"users"`
+		// SharedProjects share projects of the owner with other users as viewer, editor or owner.
+		SharedProjects models.ProjectShares `yaml:"shared_projects"`
+		Telegram       struct {
 			Enabled        bool   `yaml:"enabled"`
 			Token          string `yaml:"token"`
 			UserId         int64  `yaml:"userId"`
@@ -80,6 +89,12 @@ type Config struct {
 		RemoteAddr  string `yaml:"remote_addr"`
 		ServerToken string `yaml:"server_token"`
 	}
+	// UDA is the schema of user defined attributes, it should be the same for server and client.
+	UDA models.UDASchema `yaml:"uda"`
+	// Statuses are added to pending, completed and deleted, it should be the same for server and client.
+	Statuses []models.StatusDefinition `yaml:"statuses"`
+	// Templates are used by the from-template action and template: option, it should be the same for server and client.
+	Templates []models.TaskTemplate `yaml:"templates"`
 }
 
 func newDefaultConfig() *Config {
@@ -87,6 +102,7 @@ func newDefaultConfig() *Config {
 	c.Server.ListenAddr = ":8080"
 	c.Server.Database.Type = "file"
 	c.Server.Database.File.Path = path.Join(homeDir, "database.json")
+	c.Server.Database.Sqlite.Path = path.Join(homeDir, "database.sqlite")
 	c.Server.DiagnosticEndpointsEnabled = true
 
 	c.Server.TokenAuth.ClientToken = "api_password"
@@ -12,7 +12,7 @@ var taskwarriorImportConfig = &taskwarrior.ImportConfig{
 	Filepath:      "",
 	SkipDeleted:   true,
 	SkipCompleted: true,
-	SkipRecur:     true,
+	SkipRecur:     false,
 }
 
 func init() {
@@ -28,17 +28,24 @@ var importTaskWarriorCmd = &cobra.Command{
 	Short: "import tasks from taskwarrior",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		repo := db.NewRemoteRepository(cfg.Client.RemoteAddr, cfg.Client.ServerToken, http.DefaultClient)
-		if err := repo.Ping(); err != nil {
+		if err := repo.Ping(cmd.Context()); err != nil {
 			log.Fatalf("error on connect to remote server: %s", err)
 		}
 
-		tasks, err := taskwarrior.Import(taskwarriorImportConfig)
+		tasks, err := taskwarrior.Import(cmd.Context(), taskwarriorImportConfig)
 		if err != nil {
 			log.Fatalf("error on export tasks: %s", err)
 		}
 
 		for _, t := range tasks {
-			if err := repo.Insert(t); err != nil {
+			existing, err := repo.Get(cmd.Context(), t.UUID)
+			if err != nil {
+				log.Fatalf("cant fetch existing task: (%s) %s", t.UUID.String(), err)
+			}
+			if existing != nil {
+				t.Revision = existing.Revision
+			}
+			if err := repo.Insert(cmd.Context(), t); err != nil {
 				log.Fatalf("cant import task: (%s) %s", t.UUID.String(), err)
 			}
 		}
@@ -52,20 +52,28 @@ var migrateCmd = &cobra.Command{
 		source := db.NewRemoteRepository(sourceConfig.Client.RemoteAddr, sourceConfig.Client.ServerToken, http.DefaultClient)
 		destination := db.NewRemoteRepository(destinationConfig.Client.RemoteAddr, destinationConfig.Client.ServerToken, http.DefaultClient)
 
-		if err := source.Ping(); err != nil {
+		if err := source.Ping(cmd.Context()); err != nil {
 			log.Fatalf("error on connect to source server: %s", err)
 		}
-		if err := destination.Ping(); err != nil {
+		if err := destination.Ping(cmd.Context()); err != nil {
 			log.Fatalf("error on connect to destination server: %s", err)
 		}
 
-		tasks, err := source.All()
+		tasks, err := source.All(cmd.Context())
 		if err != nil {
 			log.Fatalf("cant read tasks from source server: %s", err.Error())
 		}
 		log.Printf("readed %d tasks\n", len(tasks))
 		for _, t := range tasks {
-			if err := destination.Insert(t); err != nil {
+			existing, err := destination.Get(cmd.Context(), t.UUID)
+			if err != nil {
+				log.Fatalf("cant fetch task from destination server: (%s) %s", t.UUID.String(), err)
+			}
+			t.Revision = 0
+			if existing != nil {
+				t.Revision = existing.Revision
+			}
+			if err := destination.Insert(cmd.Context(), t); err != nil {
 				log.Fatalf("cant send task to destination server: (%s) %s", t.UUID.String(), err)
 			}
 		}
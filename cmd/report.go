@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/paragor/todo/pkg/db"
+	"github.com/paragor/todo/pkg/models"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+var reportConfig = struct {
+	From    string
+	To      string
+	GroupBy string
+	Project string
+	Output  string
+}{
+	GroupBy: string(models.ReportByProject),
+	Output:  "table",
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringVar(&reportConfig.From, "from", reportConfig.From, "first day of the report (2006-01-02), the first day of the current month by default")
+	reportCmd.Flags().StringVar(&reportConfig.To, "to", reportConfig.To, "last day of the report (2006-01-02), today by default")
+	reportCmd.Flags().StringVar(&reportConfig.GroupBy, "group-by", reportConfig.GroupBy, "sum tracked time per project, tag or day")
+	reportCmd.Flags().StringVar(&reportConfig.Project, "project", reportConfig.Project, "report only tasks of the project")
+	reportCmd.Flags().StringVarP(
+		&reportConfig.Output,
+		"output",
+		"o",
+		reportConfig.Output,
+		fmt.Sprintf("output format (%s, json)", strings.Join(models.ReportFormatsAllowed, ", ")),
+	)
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Show tracked time per project, tag or day",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if reportConfig.Output != "json" && !slices.Contains(models.ReportFormatsAllowed, reportConfig.Output) {
+			return fmt.Errorf("unknown output format")
+		}
+		groupBy, err := models.ParseReportGroupBy(reportConfig.GroupBy)
+		if err != nil {
+			return err
+		}
+		from, to, err := models.ParseReportRange(reportConfig.From, reportConfig.To, time.Now())
+		if err != nil {
+			return err
+		}
+		repo := db.NewRemoteRepository(cfg.Client.RemoteAddr, cfg.Client.ServerToken, http.DefaultClient)
+		if err := repo.Ping(cmd.Context()); err != nil {
+			log.Fatalf("cant connect to server: %s", err.Error())
+		}
+
+		filter := models.NewTimeReportFilter()
+		filter.Project = reportConfig.Project
+		report, err := models.FindTimeReport(cmd.Context(), repo, filter, groupBy, from, to)
+		if err != nil {
+			log.Fatalf("cant build report: %s", err.Error())
+		}
+		if reportConfig.Output == "json" {
+			fmt.Println(prettyOutputJson(report))
+		} else {
+			fmt.Println(report.Render(reportConfig.Output))
+		}
+		return nil
+	},
+}
@@ -1,13 +1,17 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"github.com/paragor/todo/pkg/models"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 	"io"
 	"os"
+	"os/signal"
 	"path"
 	"strings"
+	"syscall"
 )
 
 var homeDir = path.Join(Or(os.Getenv("TODOLIST_HOME"), os.Getenv("HOME")), ".config/todolist")
@@ -23,7 +27,9 @@ var rootCmd = &cobra.Command{
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -49,6 +55,15 @@ func initConfig() {
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		panic(fmt.Errorf("cant unmarshal config file: %w", err).Error())
 	}
+	if err := models.SetUDASchema(cfg.UDA); err != nil {
+		panic(fmt.Errorf("cant set uda schema: %w", err).Error())
+	}
+	if err := models.SetTaskStatuses(cfg.Statuses); err != nil {
+		panic(fmt.Errorf("cant set statuses: %w", err).Error())
+	}
+	if err := models.SetTaskTemplates(cfg.Templates); err != nil {
+		panic(fmt.Errorf("cant set task templates: %w", err).Error())
+	}
 }
 
 func Or[T comparable](value T, alternatives ...T) T {
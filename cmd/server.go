@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"slices"
 
 	"github.com/paragor/todo/pkg/cron"
 	"github.com/paragor/todo/pkg/db"
@@ -25,6 +27,7 @@ var serverCmd = &cobra.Command{
 		runner := service.NewRunner()
 		runnable := []service.Runnable{}
 		var repo models.Repository
+		var attachments models.AttachmentStorage
 		{
 			dbConfig := cfg.Server.Database
 			switch dbConfig.Type {
@@ -35,6 +38,7 @@ var serverCmd = &cobra.Command{
 				originRepo := db.NewInMemoryTasksRepository(cfg.Server.Database.File.Path)
 				runnable = append(runnable, originRepo)
 				repo = originRepo
+				attachments = db.NewFileAttachmentStorage(cfg.Server.Database.File.Path)
 			case "postgresql":
 				if dbConfig.Postgresql.Url == "" {
 					log.Fatalln("database config: type is set as postgresql, but url is not provided")
@@ -42,32 +46,44 @@ var serverCmd = &cobra.Command{
 				originRepo := db.NewPostgresqlTasksRepository(cfg.Server.Database.Postgresql.Url)
 				runnable = append(runnable, originRepo)
 				repo = originRepo
+				attachments = originRepo
+			case "sqlite":
+				if dbConfig.Sqlite.Path == "" {
+					log.Fatalln("database config: type is set as sqlite, but path is not provided")
+				}
+				originRepo := db.NewSqliteTasksRepository(cfg.Server.Database.Sqlite.Path)
+				runnable = append(runnable, originRepo)
+				repo = originRepo
+				attachments = db.NewFileAttachmentStorage(cfg.Server.Database.Sqlite.Path)
 			}
 		}
-		repo = events.NewSpyRepository(repo)
+		repo = db.NewUserScopedRepository(events.NewSpyRepository(repo), cfg.Server.SharedProjects)
 
+		defaultUser := &models.User{}
+		users := append(models.Users{defaultUser}, cfg.Server.Users...)
 		authConfig := &httpserver.AuthChainConfig{
 			AuthBaseConfig:     nil,
 			AuthTelegramConfig: nil,
 			AuthTokenConfig:    nil,
+			Users:              users,
 		}
 		if cfg.Server.Telegram.Enabled {
 			if cfg.Server.Telegram.Token == "" {
 				log.Fatalln("telegram token is empty")
 			}
-			if cfg.Server.Telegram.UserId == 0 {
+			defaultUser.TelegramId = cfg.Server.Telegram.UserId
+			if !slices.ContainsFunc(users, func(user *models.User) bool { return user.TelegramId != 0 }) {
 				log.Fatalln("telegram user id is empty")
 			}
 			authConfig.AuthTelegramConfig = &httpserver.AuthTelegramConfig{
-				Token:     cfg.Server.Telegram.Token,
-				TrustedId: cfg.Server.Telegram.UserId,
+				Token: cfg.Server.Telegram.Token,
 			}
-			telegramServer := telegram.NewTelegramServer(cfg.Server.Telegram.Token, cfg.Server.Telegram.UserId, cfg.Server.PublicUrl, repo)
+			telegramServer := telegram.NewTelegramServer(cfg.Server.Telegram.Token, users, cfg.Server.PublicUrl, repo, attachments)
 			runnable = append(runnable, telegramServer)
 
 			if cfg.Server.Telegram.EverydayAgenda.Enabled {
-				runnable = append(runnable, cron.NewRepeatableCron(func() error {
-					if err := telegramServer.TriggerAgenda(); err != nil {
+				runnable = append(runnable, cron.NewRepeatableCron(func(ctx context.Context) error {
+					if err := telegramServer.TriggerEverydayAgenda(ctx); err != nil {
 						return fmt.Errorf("cant trigger agenda: %w", err)
 					}
 					return nil
@@ -75,22 +91,22 @@ var serverCmd = &cobra.Command{
 			}
 		}
 		if cfg.Server.TokenAuth.Enabled {
-			if cfg.Server.TokenAuth.ClientToken == "" {
+			defaultUser.Token = cfg.Server.TokenAuth.ClientToken
+			if !slices.ContainsFunc(users, func(user *models.User) bool { return user.Token != "" }) {
 				log.Fatalln("TokenAuth.ClientToken is empty")
 			}
-			authConfig.AuthTokenConfig = &httpserver.AuthTokenConfig{Token: cfg.Server.TokenAuth.ClientToken}
+			authConfig.AuthTokenConfig = &httpserver.AuthTokenConfig{}
 		}
 		if cfg.Server.BaseAuth.Enabled {
-			if cfg.Server.BaseAuth.Login == "" {
-				log.Fatalln("BaseAuth.Login is empty")
-			}
-			if cfg.Server.BaseAuth.Password == "" {
+			if cfg.Server.BaseAuth.Login != "" && cfg.Server.BaseAuth.Password == "" {
 				log.Fatalln("BaseAuth.Password is empty")
 			}
-			authConfig.AuthBaseConfig = &httpserver.AuthBaseConfig{
-				Login:    cfg.Server.BaseAuth.Login,
-				Password: cfg.Server.BaseAuth.Password,
+			defaultUser.Login = cfg.Server.BaseAuth.Login
+			defaultUser.Password = cfg.Server.BaseAuth.Password
+			if !slices.ContainsFunc(users, func(user *models.User) bool { return user.Login != "" }) {
+				log.Fatalln("BaseAuth.Login is empty")
 			}
+			authConfig.AuthBaseConfig = &httpserver.AuthBaseConfig{}
 		}
 		if cfg.Server.OidcAuth.Enabled {
 			if cfg.Server.OidcAuth.ClientId == "" {
@@ -105,27 +121,35 @@ var serverCmd = &cobra.Command{
 			if cfg.Server.OidcAuth.CookieKey == "" {
 				log.Fatalln("OidcAuth.CookieKey is empty")
 			}
-			if len(cfg.Server.OidcAuth.WhitelistEmails) == 0 {
-				log.Fatalln("OidcAuth.CookieKey is empty")
+			defaultUser.Emails = cfg.Server.OidcAuth.WhitelistEmails
+			if !slices.ContainsFunc(users, func(user *models.User) bool { return len(user.Emails) > 0 }) {
+				log.Fatalln("OidcAuth.WhitelistEmails is empty")
 			}
 			if len([]byte(cfg.Server.OidcAuth.CookieKey)) != 32 {
 				log.Fatalln("OidcAuth.CookieKey should be base64 of 32 bytes. example: 'pwgen 32'")
 			}
 			authConfig.AuthOidcConfig = &httpserver.AuthOidcConfig{
-				ClientId:        cfg.Server.OidcAuth.ClientId,
-				ClientSecret:    cfg.Server.OidcAuth.ClientSecret,
-				IssuerUrl:       cfg.Server.OidcAuth.IssuerUrl,
-				CookieKey:       cfg.Server.OidcAuth.CookieKey,
-				WhitelistEmails: cfg.Server.OidcAuth.WhitelistEmails,
-				Scopes:          cfg.Server.OidcAuth.Scopes,
+				ClientId:     cfg.Server.OidcAuth.ClientId,
+				ClientSecret: cfg.Server.OidcAuth.ClientSecret,
+				IssuerUrl:    cfg.Server.OidcAuth.IssuerUrl,
+				CookieKey:    cfg.Server.OidcAuth.CookieKey,
+				Scopes:       cfg.Server.OidcAuth.Scopes,
 			}
 		}
+		if err := users.Validate(); err != nil {
+			log.Fatalln("invalid users:", err)
+		}
+		if err := cfg.Server.SharedProjects.Validate(users); err != nil {
+			log.Fatalln("invalid shared projects:", err)
+		}
 		if !cfg.Server.AuthEnabled {
 			authConfig = nil
 		}
 		httpServer, err := httpserver.NewHttpServer(
 			cfg.Server.ListenAddr,
 			repo,
+			attachments,
+			cfg.Server.SharedProjects,
 			authConfig,
 			cfg.Server.PublicUrl,
 			cfg.Server.DiagnosticEndpointsEnabled,
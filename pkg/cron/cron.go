@@ -17,7 +17,7 @@ var (
 
 type Cron struct {
 	triggerDate time.Time
-	fn          func() error
+	fn          func(ctx context.Context) error
 
 	m         sync.Mutex
 	isStarted bool
@@ -27,17 +27,17 @@ type Cron struct {
 	cancel func()
 }
 
-func NewCron(triggerDate time.Time, fn func() error) *Cron {
+func NewCron(triggerDate time.Time, fn func(ctx context.Context) error) *Cron {
 	return &Cron{triggerDate: triggerDate, fn: fn}
 }
 
-func (c *Cron) GoRun() (chan error, error) {
+func (c *Cron) GoRun(ctx context.Context) (chan error, error) {
 	if c.IsStarted() {
 		return nil, AlreadyRunningError
 	}
 	c.m.Lock()
 	c.isStarted = true
-	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.ctx, c.cancel = context.WithCancel(ctx)
 	c.m.Unlock()
 	now := time.Now()
 	if now.After(c.triggerDate) {
@@ -48,7 +48,7 @@ func (c *Cron) GoRun() (chan error, error) {
 	go func() {
 		select {
 		case <-triggerChan:
-			errChan <- c.fn()
+			errChan <- c.fn(c.ctx)
 		case <-c.ctx.Done():
 			errChan <- ForceStoppedError
 		}
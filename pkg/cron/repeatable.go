@@ -7,20 +7,20 @@ import (
 )
 
 type RepeatableCron struct {
-	fn             func() error
+	fn             func(ctx context.Context) error
 	nextNotifyTime func() time.Time
 
 	cancel func()
 }
 
-func NewRepeatableCron(fn func() error, nextNotifyTime func() time.Time) *RepeatableCron {
+func NewRepeatableCron(fn func(ctx context.Context) error, nextNotifyTime func() time.Time) *RepeatableCron {
 	return &RepeatableCron{fn: fn, nextNotifyTime: nextNotifyTime}
 }
 
 func (r *RepeatableCron) Start(ctx context.Context, stopper chan<- error) error {
 	ctx, r.cancel = context.WithCancel(ctx)
 	cron := NewCron(r.nextNotifyTime(), r.fn)
-	result, err := cron.GoRun()
+	result, err := cron.GoRun(ctx)
 	if err != nil {
 		return fmt.Errorf("cant start cron: %w", err)
 	}
@@ -33,7 +33,7 @@ func (r *RepeatableCron) Start(ctx context.Context, stopper chan<- error) error
 					return
 				}
 				cron = NewCron(r.nextNotifyTime(), r.fn)
-				result, err = cron.GoRun()
+				result, err = cron.GoRun(ctx)
 				if err != nil {
 					stopper <- fmt.Errorf("cron start error: %w", cronErr)
 					return
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"os"
+	"path/filepath"
+)
+
+// fileAttachmentStorage keeps every attachment in its own file named by uuid.
+type fileAttachmentStorage struct {
+	dir string
+}
+
+// NewFileAttachmentStorage stores attachments in the attachments directory next to the database file.
+func NewFileAttachmentStorage(databasePath string) *fileAttachmentStorage {
+	return &fileAttachmentStorage{dir: filepath.Join(filepath.Dir(databasePath), "attachments")}
+}
+
+func (s *fileAttachmentStorage) SaveAttachment(ctx context.Context, UUID uuid.UUID, content []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("cant create attachments dir: %w", err)
+	}
+	path := filepath.Join(s.dir, UUID.String())
+	tmpFileName := path + ".new"
+	if err := os.WriteFile(tmpFileName, content, 0644); err != nil {
+		return fmt.Errorf("cant write to file: %w", err)
+	}
+	if err := os.Rename(tmpFileName, path); err != nil {
+		return fmt.Errorf("cant rename tmp file to final: %w", err)
+	}
+	return nil
+}
+
+func (s *fileAttachmentStorage) LoadAttachment(ctx context.Context, UUID uuid.UUID) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(filepath.Join(s.dir, UUID.String()))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cant read attachment: %w", err)
+	}
+	return content, nil
+}
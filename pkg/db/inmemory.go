@@ -1,6 +1,8 @@
 package db
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -10,36 +12,80 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"slices"
 	"sync"
+	"time"
 )
 
 type DatabaseInternal struct {
-	Version int                       `json:"version"`
-	Tasks   map[uuid.UUID]models.Task `json:"tasks"`
+	Version int                                     `json:"version"`
+	Tasks   map[uuid.UUID]models.Task               `json:"tasks"`
+	History map[uuid.UUID][]models.TaskHistoryEntry `json:"history,omitempty"`
+	// Projects are keyed by projectKey.
+	Projects map[string]models.Project `json:"projects,omitempty"`
+	// Filters are keyed by filterKey.
+	Filters map[string]models.SavedFilter `json:"filters,omitempty"`
+}
+
+func projectKey(project *models.Project) string {
+	return project.Owner + "/" + project.Name
+}
+
+func filterKey(filter *models.SavedFilter) string {
+	return filter.Owner + "/" + filter.Name
+}
+
+// journalCompactionSize is the number of journal records after which the journal is compacted into the snapshot.
+const journalCompactionSize = 1000
+
+// journalRecord is a single change appended to the journal, Version is the database version after the change.
+// The change is either the task with its history, the project or the saved filter, DeletedProject and DeletedFilter
+// remove them. Batch groups changes written with a single flush, like renames.
+type journalRecord struct {
+	Version        int                      `json:"version"`
+	Task           *models.Task             `json:"task,omitempty"`
+	History        *models.TaskHistoryEntry `json:"history,omitempty"`
+	Project        *models.Project          `json:"project,omitempty"`
+	DeletedProject bool                     `json:"deleted_project,omitempty"`
+	Filter         *models.SavedFilter      `json:"filter,omitempty"`
+	DeletedFilter  bool                     `json:"deleted_filter,omitempty"`
+	Batch          []*journalRecord         `json:"batch,omitempty"`
 }
 
 type inMemoryTasksRepository struct {
 	filepath          string
 	db                *DatabaseInternal
+	journal           *os.File
+	journalSize       int
 	inProgressWriters sync.WaitGroup
 	ctx               context.Context
 	cancel            func()
 
-	writeMutex sync.Mutex
+	m sync.RWMutex
 }
 
 func NewInMemoryTasksRepository(filepath string) *inMemoryTasksRepository {
 	return &inMemoryTasksRepository{filepath: filepath}
 }
 
-func (r *inMemoryTasksRepository) Get(UUID uuid.UUID) (*models.Task, error) {
+func (r *inMemoryTasksRepository) journalPath() string {
+	return r.filepath + ".journal"
+}
+
+func (r *inMemoryTasksRepository) Get(ctx context.Context, UUID uuid.UUID) (*models.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.m.RLock()
+	defer r.m.RUnlock()
 	if task, ok := r.db.Tasks[UUID]; ok {
 		return task.Clone(false), nil
 	}
 	return nil, nil
 }
 
-func (r *inMemoryTasksRepository) Insert(task *models.Task) error {
+func (r *inMemoryTasksRepository) Insert(ctx context.Context, tasks ...*models.Task) error {
 	if r.ctx == nil {
 		return fmt.Errorf("repository is not started")
 	}
@@ -50,55 +96,481 @@ func (r *inMemoryTasksRepository) Insert(task *models.Task) error {
 		r.inProgressWriters.Add(1)
 		defer r.inProgressWriters.Done()
 	}
-	r.db.Version++
-	task.Unify()
-	if err := task.Validate(); err != nil {
-		return fmt.Errorf("invalid task: %w", err)
+	if len(tasks) == 0 {
+		return nil
+	}
+	inserted := cloneForInsert(tasks)
+	for _, task := range inserted {
+		task.Unify()
+		if err := task.Validate(); err != nil {
+			return fmt.Errorf("invalid task: %w", err)
+		}
 	}
 
-	r.db.Tasks[task.UUID] = *task.Clone(false)
-	return r.flush()
+	r.m.Lock()
+	defer r.m.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	version := r.db.Version + 1
+	record := &journalRecord{Version: version}
+	for _, task := range inserted {
+		change, err := r.insertRecord(record.Batch, task, version)
+		if err != nil {
+			return err
+		}
+		record.Batch = append(record.Batch, change)
+	}
+	if len(record.Batch) == 1 {
+		record = record.Batch[0]
+	}
+	if err := r.write(record); err != nil {
+		return err
+	}
+	applyInserted(tasks, inserted)
+	return nil
 }
 
-func (r *inMemoryTasksRepository) flush() error {
-	r.writeMutex.Lock()
-	defer r.writeMutex.Unlock()
-	data, err := json.Marshal(r.db)
+// insertRecord checks the task against the stored one and returns the journal record of the change,
+// the caller holds the write lock. Batch are records of previous tasks of the same insert, they are not applied yet.
+func (r *inMemoryTasksRepository) insertRecord(batch []*journalRecord, task *models.Task, version int) (*journalRecord, error) {
+	previous := r.batchTask(batch, task.UUID)
+	if err := task.ValidateChanges(previous); err != nil {
+		return nil, fmt.Errorf("invalid task: %w", err)
+	}
+	if err := task.NextRevision(previous); err != nil {
+		return nil, err
+	}
+	if err := task.UpdateTimestamps(previous, time.Now()); err != nil {
+		return nil, fmt.Errorf("cant update timestamps: %w", err)
+	}
+	err := models.CheckTaskParent(previous, task, func(UUID uuid.UUID) (*models.Task, error) {
+		return r.batchTask(batch, UUID), nil
+	})
 	if err != nil {
-		err := fmt.Errorf("cant marhshal database: %w", err)
-		log.Println(err)
-		r.Stop()
+		return nil, err
+	}
+	historyEntry, err := models.NewTaskHistoryEntry(previous, task, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("cant create history entry: %w", err)
+	}
+	return &journalRecord{
+		Version: version,
+		Task:    task.Clone(false),
+		History: historyEntry,
+	}, nil
+}
+
+// batchTask returns the latest task of the batch or the stored one, nil if it is not found.
+func (r *inMemoryTasksRepository) batchTask(batch []*journalRecord, UUID uuid.UUID) *models.Task {
+	for i := len(batch) - 1; i >= 0; i-- {
+		if batch[i].Task != nil && batch[i].Task.UUID == UUID {
+			return batch[i].Task.Clone(false)
+		}
+	}
+	if task, ok := r.db.Tasks[UUID]; ok {
+		return &task
+	}
+	return nil
+}
+
+// write appends the record to the journal and applies it, the caller holds the write lock.
+func (r *inMemoryTasksRepository) write(record *journalRecord) error {
+	if err := r.appendJournal(record); err != nil {
 		return err
 	}
+	r.db.apply(record)
+	if r.journalSize >= journalCompactionSize {
+		if err := r.compact(); err != nil {
+			log.Printf("cant compact journal: %s", err)
+		}
+	}
+	return nil
+}
+
+func (db *DatabaseInternal) apply(record *journalRecord) {
+	db.Version = record.Version
+	if record.Task != nil {
+		db.Tasks[record.Task.UUID] = *record.Task
+		if record.History != nil {
+			db.History[record.Task.UUID] = append(db.History[record.Task.UUID], *record.History)
+		}
+	}
+	if record.Project != nil {
+		if record.DeletedProject {
+			delete(db.Projects, projectKey(record.Project))
+		} else {
+			db.Projects[projectKey(record.Project)] = *record.Project
+		}
+	}
+	if record.Filter != nil {
+		if record.DeletedFilter {
+			delete(db.Filters, filterKey(record.Filter))
+		} else {
+			db.Filters[filterKey(record.Filter)] = *record.Filter
+		}
+	}
+	for _, change := range record.Batch {
+		db.apply(change)
+	}
+	db.Version = record.Version
+}
+
+func (record *journalRecord) validate() error {
+	if record.Task != nil {
+		if err := record.Task.Validate(); err != nil {
+			return fmt.Errorf("journal: invalid task: %s: %w", record.Task.UUID.String(), err)
+		}
+	}
+	for _, change := range record.Batch {
+		if err := change.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *inMemoryTasksRepository) appendJournal(record *journalRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("cant marshal journal record: %w", err)
+	}
+	if _, err := r.journal.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("cant write to journal: %w", err)
+	}
+	if err := r.journal.Sync(); err != nil {
+		return fmt.Errorf("cant sync journal: %w", err)
+	}
+	r.journalSize++
+	return nil
+}
+
+// compact writes the snapshot and truncates the journal. Records are replayed only if their version is newer
+// than the snapshot, so a crash between these steps is safe. The journal is truncated only after the snapshot
+// and its rename are synced to the disk.
+func (r *inMemoryTasksRepository) compact() error {
+	data, err := json.Marshal(r.db)
+	if err != nil {
+		return fmt.Errorf("cant marhshal database: %w", err)
+	}
 	tmpFileName := r.filepath + ".new"
-	if err := os.WriteFile(tmpFileName, data, 0644); err != nil {
-		return fmt.Errorf("cant write to file: %w", err)
+	if err := writeFileSync(tmpFileName, data); err != nil {
+		return err
 	}
 	if err := os.Rename(tmpFileName, r.filepath); err != nil {
 		return fmt.Errorf("cant rename tmp file to final: %w", err)
 	}
+	if err := syncDir(filepath.Dir(r.filepath)); err != nil {
+		return err
+	}
+	if err := r.journal.Truncate(0); err != nil {
+		return fmt.Errorf("cant truncate journal: %w", err)
+	}
+	r.journalSize = 0
+	return nil
+}
+
+func writeFileSync(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("cant open file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("cant write to file: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("cant sync file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("cant close file: %w", err)
+	}
 	return nil
 }
 
-func (r *inMemoryTasksRepository) All() ([]*models.Task, error) {
+// syncDir makes the rename of the file in the directory durable.
+func syncDir(name string) error {
+	dir, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("cant open dir: %w", err)
+	}
+	defer dir.Close()
+	if err := dir.Sync(); err != nil {
+		return fmt.Errorf("cant sync dir: %w", err)
+	}
+	return nil
+}
+
+func (r *inMemoryTasksRepository) replayJournal() error {
+	f, err := os.Open(r.journalPath())
+	if err != nil && errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cant open journal: %w", err)
+	}
+	defer f.Close()
+	reader := bufio.NewReader(f)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("cant read journal: %w", err)
+		}
+		if errors.Is(err, io.EOF) {
+			if len(bytes.TrimSpace(data)) > 0 {
+				log.Printf("journal: skip incomplete record at line %d", line)
+			}
+			return nil
+		}
+		record := &journalRecord{}
+		if err := json.Unmarshal(data, record); err != nil {
+			return fmt.Errorf("cant unmarshal journal record at line %d: %w", line, err)
+		}
+		if record.Version <= r.db.Version {
+			continue
+		}
+		if err := record.validate(); err != nil {
+			return err
+		}
+		r.db.apply(record)
+	}
+}
+
+func (r *inMemoryTasksRepository) All(ctx context.Context) ([]*models.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.m.RLock()
 	result := []*models.Task{}
 	for _, t := range r.db.Tasks {
 		result = append(result, t.Clone(false))
 	}
+	r.m.RUnlock()
 	models.SortTasks(result)
 	return result, nil
 }
 
+func (r *inMemoryTasksRepository) Find(ctx context.Context, filter *models.ListFilter, page models.Page) (*models.FindResult, error) {
+	tasks, err := r.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := r.Projects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	withArchived := *filter
+	withArchived.Archived = models.ArchivedScopes(projects)
+	return models.FindInTasks(tasks, &withArchived, page), nil
+}
+
+func (r *inMemoryTasksRepository) History(ctx context.Context, UUID uuid.UUID) ([]*models.TaskHistoryEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.m.RLock()
+	defer r.m.RUnlock()
+	result := []*models.TaskHistoryEntry{}
+	for _, entry := range r.db.History[UUID] {
+		result = append(result, &entry)
+	}
+	return result, nil
+}
+
+func (r *inMemoryTasksRepository) Projects(ctx context.Context) ([]*models.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.m.RLock()
+	result := []*models.Project{}
+	for _, project := range r.db.Projects {
+		project.DefaultTags = slices.Clone(project.DefaultTags)
+		result = append(result, &project)
+	}
+	r.m.RUnlock()
+	models.SortProjects(result)
+	return result, nil
+}
+
+func (r *inMemoryTasksRepository) SaveProject(ctx context.Context, project *models.Project) error {
+	project.Unify()
+	if err := project.Validate(); err != nil {
+		return fmt.Errorf("invalid project: %w", err)
+	}
+	return r.writeProject(ctx, project, false)
+}
+
+func (r *inMemoryTasksRepository) DeleteProject(ctx context.Context, project *models.Project) error {
+	project.Unify()
+	return r.writeProject(ctx, project, true)
+}
+
+func (r *inMemoryTasksRepository) writeProject(ctx context.Context, project *models.Project, deleted bool) error {
+	if r.ctx == nil {
+		return fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("repository is closed")
+	default:
+		r.inProgressWriters.Add(1)
+		defer r.inProgressWriters.Done()
+	}
+	r.m.Lock()
+	defer r.m.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	saved := *project
+	return r.write(&journalRecord{
+		Version:        r.db.Version + 1,
+		Project:        &saved,
+		DeletedProject: deleted,
+	})
+}
+
+func (r *inMemoryTasksRepository) Filters(ctx context.Context) ([]*models.SavedFilter, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.m.RLock()
+	result := []*models.SavedFilter{}
+	for _, filter := range r.db.Filters {
+		result = append(result, &filter)
+	}
+	r.m.RUnlock()
+	models.SortSavedFilters(result)
+	return result, nil
+}
+
+func (r *inMemoryTasksRepository) SaveFilter(ctx context.Context, filter *models.SavedFilter) error {
+	filter.Unify()
+	if err := filter.Validate(); err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+	return r.writeFilter(ctx, filter, false)
+}
+
+func (r *inMemoryTasksRepository) DeleteFilter(ctx context.Context, filter *models.SavedFilter) error {
+	filter.Unify()
+	return r.writeFilter(ctx, filter, true)
+}
+
+// writeFilter deactivates other filters of the owner in the same record, if the saved filter is active.
+func (r *inMemoryTasksRepository) writeFilter(ctx context.Context, filter *models.SavedFilter, deleted bool) error {
+	if r.ctx == nil {
+		return fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("repository is closed")
+	default:
+		r.inProgressWriters.Add(1)
+		defer r.inProgressWriters.Done()
+	}
+	r.m.Lock()
+	defer r.m.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	version := r.db.Version + 1
+	saved := *filter
+	record := &journalRecord{Version: version, Filter: &saved, DeletedFilter: deleted}
+	if filter.Active && !deleted {
+		for _, existing := range r.db.Filters {
+			if existing.Owner == filter.Owner && existing.Name != filter.Name && existing.Active {
+				existing.Active = false
+				record.Batch = append(record.Batch, &journalRecord{Version: version, Filter: &existing})
+			}
+		}
+	}
+	return r.write(record)
+}
+
+// Rename writes renamed tasks and projects as a single journal record.
+func (r *inMemoryTasksRepository) Rename(ctx context.Context, rename *models.Rename) (*models.RenamePreview, error) {
+	rename.Unify()
+	if err := rename.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rename: %w", err)
+	}
+	if r.ctx == nil {
+		return nil, fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return nil, fmt.Errorf("repository is closed")
+	default:
+		r.inProgressWriters.Add(1)
+		defer r.inProgressWriters.Done()
+	}
+	r.m.Lock()
+	defer r.m.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	tasks := []*models.Task{}
+	for _, task := range r.db.Tasks {
+		tasks = append(tasks, task.Clone(false))
+	}
+	preview := rename.Preview(tasks)
+	if rename.DryRun {
+		return preview, nil
+	}
+	renamed, history, err := rename.RenameTasks(tasks, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	projects := []*models.Project{}
+	for _, project := range r.db.Projects {
+		project.DefaultTags = slices.Clone(project.DefaultTags)
+		projects = append(projects, &project)
+	}
+	saved, deleted := rename.RenameProjects(projects)
+
+	version := r.db.Version + 1
+	record := &journalRecord{Version: version}
+	for _, project := range deleted {
+		record.Batch = append(record.Batch, &journalRecord{Version: version, Project: project, DeletedProject: true})
+	}
+	for _, project := range saved {
+		record.Batch = append(record.Batch, &journalRecord{Version: version, Project: project})
+	}
+	for i := range renamed {
+		record.Batch = append(record.Batch, &journalRecord{Version: version, Task: renamed[i], History: history[i]})
+	}
+	if len(record.Batch) == 0 {
+		return preview, nil
+	}
+	return preview, r.write(record)
+}
+
 func (r *inMemoryTasksRepository) Stop() {
 	if r.cancel != nil {
 		r.cancel()
 	}
 	r.inProgressWriters.Wait()
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.journal != nil {
+		if r.journalSize > 0 {
+			if err := r.compact(); err != nil {
+				log.Printf("cant compact journal: %s", err)
+			}
+		}
+		_ = r.journal.Close()
+		r.journal = nil
+	}
 }
 func (r *inMemoryTasksRepository) Start(ctx context.Context, stopper chan<- error) error {
 	f, err := os.Open(r.filepath)
 	if err != nil && errors.Is(err, os.ErrNotExist) {
-		r.db = &DatabaseInternal{Version: 0, Tasks: map[uuid.UUID]models.Task{}}
+		r.db = &DatabaseInternal{
+			Version:  0,
+			Tasks:    map[uuid.UUID]models.Task{},
+			History:  map[uuid.UUID][]models.TaskHistoryEntry{},
+			Projects: map[string]models.Project{},
+			Filters:  map[string]models.SavedFilter{},
+		}
 	} else if err != nil {
 		return fmt.Errorf("cant open file: %w", err)
 	} else {
@@ -120,9 +592,30 @@ func (r *inMemoryTasksRepository) Start(ctx context.Context, stopper chan<- erro
 				return fmt.Errorf("onload: task uuid and key in struct is not equal: %s", task.UUID.String())
 			}
 		}
+		if db.History == nil {
+			db.History = map[uuid.UUID][]models.TaskHistoryEntry{}
+		}
+		if db.Projects == nil {
+			db.Projects = map[string]models.Project{}
+		}
+		if db.Filters == nil {
+			db.Filters = map[string]models.SavedFilter{}
+		}
 		r.db = db
 		_ = f.Close()
 	}
+	if err := r.replayJournal(); err != nil {
+		return err
+	}
+	journal, err := os.OpenFile(r.journalPath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("cant open journal: %w", err)
+	}
+	r.journal = journal
+	if err := r.compact(); err != nil {
+		_ = journal.Close()
+		return fmt.Errorf("cant compact journal: %w", err)
+	}
 	r.ctx, r.cancel = context.WithCancel(ctx)
 	go func() {
 		select {
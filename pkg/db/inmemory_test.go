@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/paragor/todo/pkg/models"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func startInMemory(t *testing.T, path string) *inMemoryTasksRepository {
+	t.Helper()
+	repo := NewInMemoryTasksRepository(path)
+	if err := repo.Start(context.Background(), make(chan error, 1)); err != nil {
+		t.Fatalf("cant start repository: %s", err)
+	}
+	return repo
+}
+
+// crashInMemory closes the journal without compaction, like the process killed after the write.
+func crashInMemory(repo *inMemoryTasksRepository) {
+	repo.cancel()
+	_ = repo.journal.Close()
+	repo.journal = nil
+}
+
+func insertTestTask(t *testing.T, repo *inMemoryTasksRepository, description string) *models.Task {
+	t.Helper()
+	task := models.NewTask()
+	task.Description = description
+	if err := repo.Insert(context.Background(), task); err != nil {
+		t.Fatalf("cant insert task: %s", err)
+	}
+	return task
+}
+
+func assertTasks(t *testing.T, repo *inMemoryTasksRepository, count int) {
+	t.Helper()
+	tasks, err := repo.All(context.Background())
+	if err != nil {
+		t.Fatalf("cant get tasks: %s", err)
+	}
+	if len(tasks) != count {
+		t.Errorf("expected %d tasks, have %d", count, len(tasks))
+	}
+}
+
+func TestInMemoryJournalReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "database.json")
+	repo := startInMemory(t, path)
+	insertTestTask(t, repo, "first")
+	repo.Stop()
+
+	repo = startInMemory(t, path)
+	second := insertTestTask(t, repo, "second")
+	second.Description = "second modified"
+	if err := repo.Insert(context.Background(), second); err != nil {
+		t.Fatalf("cant update task: %s", err)
+	}
+	crashInMemory(repo)
+
+	repo = startInMemory(t, path)
+	defer repo.Stop()
+	assertTasks(t, repo, 2)
+	stored, err := repo.Get(context.Background(), second.UUID)
+	if err != nil || stored == nil || stored.Description != "second modified" || stored.Revision != 2 {
+		t.Errorf("journal should be replayed over the snapshot: %+v, %v", stored, err)
+	}
+	if history, err := repo.History(context.Background(), second.UUID); err != nil || len(history) != 2 {
+		t.Errorf("history should be replayed: %d, %v", len(history), err)
+	}
+}
+
+func TestInMemoryJournalTornRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "database.json")
+	repo := startInMemory(t, path)
+	insertTestTask(t, repo, "first")
+	crashInMemory(repo)
+	journal, err := os.OpenFile(path+".journal", os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("cant open journal: %s", err)
+	}
+	if _, err := journal.WriteString(`{"version":2,"task":{"uuid":`); err != nil {
+		t.Fatalf("cant write journal: %s", err)
+	}
+	_ = journal.Close()
+
+	repo = startInMemory(t, path)
+	assertTasks(t, repo, 1)
+	insertTestTask(t, repo, "second")
+	crashInMemory(repo)
+
+	repo = startInMemory(t, path)
+	defer repo.Stop()
+	assertTasks(t, repo, 2)
+}
+
+func TestInMemoryJournalCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "database.json")
+	repo := startInMemory(t, path)
+	for i := 0; i < journalCompactionSize-1; i++ {
+		insertTestTask(t, repo, "task")
+	}
+	if repo.journalSize != journalCompactionSize-1 {
+		t.Errorf("journal should not be compacted before the threshold: %d", repo.journalSize)
+	}
+	insertTestTask(t, repo, "task")
+	if repo.journalSize != 0 {
+		t.Errorf("journal should be compacted at the threshold: %d", repo.journalSize)
+	}
+	if info, err := os.Stat(path + ".journal"); err != nil || info.Size() != 0 {
+		t.Errorf("journal should be truncated: %v", err)
+	}
+	if _, err := os.Stat(path + ".new"); !os.IsNotExist(err) {
+		t.Errorf("tmp snapshot should be renamed: %v", err)
+	}
+	insertTestTask(t, repo, "task")
+	crashInMemory(repo)
+
+	repo = startInMemory(t, path)
+	defer repo.Stop()
+	assertTasks(t, repo, journalCompactionSize+1)
+}
+
+func TestInMemoryInsertBatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "database.json")
+	repo := startInMemory(t, path)
+	closed := insertTestTask(t, repo, "pay rent")
+	closed.Status = models.Completed
+	missingParent := uuid.New()
+	invalid := models.NewTask()
+	invalid.Description = "orphan"
+	invalid.Parent = &missingParent
+	if err := repo.Insert(context.Background(), closed.Clone(false), invalid); err == nil {
+		t.Fatalf("batch with invalid task should be rejected")
+	}
+	stored, err := repo.Get(context.Background(), closed.UUID)
+	if err != nil || stored.Status != models.Pending || stored.Revision != 1 {
+		t.Errorf("tasks of rejected batch should not be saved: %+v, %v", stored, err)
+	}
+
+	next := models.NewTask()
+	next.Description = "pay rent"
+	if err := repo.Insert(context.Background(), closed, next); err != nil {
+		t.Fatalf("cant insert batch: %s", err)
+	}
+	if repo.journalSize != 2 {
+		t.Errorf("batch should be written as a single journal record: %d", repo.journalSize)
+	}
+	crashInMemory(repo)
+
+	repo = startInMemory(t, path)
+	defer repo.Stop()
+	assertTasks(t, repo, 2)
+	stored, err = repo.Get(context.Background(), closed.UUID)
+	if err != nil || stored.Status != models.Completed || stored.Revision != 2 {
+		t.Errorf("batch should be replayed: %+v, %v", stored, err)
+	}
+}
+
+func TestInMemoryInsertRetry(t *testing.T) {
+	repo := startInMemory(t, filepath.Join(t.TempDir(), "database.json"))
+	defer repo.Stop()
+	task := insertTestTask(t, repo, "write report")
+	missingParent := uuid.New()
+	task.Description = "write the report"
+	task.Parent = &missingParent
+	if err := repo.Insert(context.Background(), task); err == nil {
+		t.Fatalf("task with missing parent should be rejected")
+	}
+	if task.Revision != 1 || task.ModifiedAt == nil {
+		t.Errorf("task of the caller should be kept after the failed insert: %+v", task)
+	}
+	task.Parent = nil
+	if err := repo.Insert(context.Background(), task); err != nil {
+		t.Fatalf("insert should be retried with the same task: %s", err)
+	}
+	if task.Revision != 2 {
+		t.Errorf("task of the caller should get the saved revision: %d", task.Revision)
+	}
+}
+
+func TestInMemoryUDASchemaChange(t *testing.T) {
+	setSchema := func(values ...string) {
+		if err := models.SetUDASchema(models.UDASchema{{Name: "size", Type: models.UDAEnum, Values: values}}); err != nil {
+			t.Fatalf("cant set schema: %s", err)
+		}
+	}
+	setSchema("S", "M", "L")
+	t.Cleanup(func() {
+		_ = models.SetUDASchema(nil)
+	})
+	path := filepath.Join(t.TempDir(), "database.json")
+	repo := startInMemory(t, path)
+	task := models.NewTask()
+	task.Description = "write report"
+	task.SetUDA("size", "L")
+	if err := repo.Insert(context.Background(), task); err != nil {
+		t.Fatalf("cant insert task: %s", err)
+	}
+	repo.Stop()
+
+	setSchema("S", "M")
+	repo = startInMemory(t, path)
+	defer repo.Stop()
+	stored, err := repo.Get(context.Background(), task.UUID)
+	if err != nil || stored == nil || stored.UDA["size"] != "L" {
+		t.Fatalf("task should be loaded after the schema change: %+v, %v", stored, err)
+	}
+	stored.Description = "write the report"
+	if err := repo.Insert(context.Background(), stored); err != nil {
+		t.Errorf("task with the stored value out of enum should be updated: %s", err)
+	}
+	stored.SetUDA("size", "XL")
+	if err := repo.Insert(context.Background(), stored); err == nil {
+		t.Errorf("changed value out of enum should be rejected")
+	}
+}
@@ -0,0 +1,21 @@
+package db
+
+import "github.com/paragor/todo/pkg/models"
+
+// cloneForInsert returns copies of tasks for the insert. Repositories bump revisions and timestamps of copies,
+// so tasks of the caller are kept as is if the insert fails and the insert can be retried with them.
+func cloneForInsert(tasks []*models.Task) []*models.Task {
+	result := make([]*models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		result = append(result, task.Clone(false))
+	}
+	return result
+}
+
+// applyInserted copies saved tasks to tasks of the caller after the successful insert.
+func applyInserted(tasks []*models.Task, inserted []*models.Task) {
+	for i := range tasks {
+		inserted[i].BlockedBy = tasks[i].BlockedBy
+		*tasks[i] = *inserted[i]
+	}
+}
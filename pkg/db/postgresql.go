@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -42,7 +43,7 @@ func NewPostgresqlTasksRepository(connString string) *postgresqlTasksRepository
 	}
 }
 
-func (r *postgresqlTasksRepository) Get(UUID uuid.UUID) (*models.Task, error) {
+func (r *postgresqlTasksRepository) Get(ctx context.Context, UUID uuid.UUID) (*models.Task, error) {
 	if r.ctx == nil {
 		return nil, fmt.Errorf("repository is not started")
 	}
@@ -54,7 +55,7 @@ func (r *postgresqlTasksRepository) Get(UUID uuid.UUID) (*models.Task, error) {
 		defer r.wg.Done()
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), r.readTimeout)
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
 	defer cancel()
 
 	task := &models.Task{}
@@ -73,7 +74,7 @@ func (r *postgresqlTasksRepository) Get(UUID uuid.UUID) (*models.Task, error) {
 	return task, nil
 }
 
-func (r *postgresqlTasksRepository) Insert(task *models.Task) error {
+func (r *postgresqlTasksRepository) Insert(ctx context.Context, tasks ...*models.Task) error {
 	if r.ctx == nil {
 		return fmt.Errorf("repository is not started")
 	}
@@ -84,32 +85,119 @@ func (r *postgresqlTasksRepository) Insert(task *models.Task) error {
 		r.wg.Add(1)
 		defer r.wg.Done()
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), r.writeTimeout)
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
 	defer cancel()
 
-	task.Unify()
-	if err := task.Validate(); err != nil {
-		return fmt.Errorf("invalid task: %w", err)
+	inserted := cloneForInsert(tasks)
+	for _, task := range inserted {
+		task.Unify()
+		if err := task.Validate(); err != nil {
+			return fmt.Errorf("invalid task: %w", err)
+		}
 	}
 
-	// 	if _, err := db.Exec(context.Background(), `insert into shortened_urls(id, url) values ($1, $2)
-	//	on conflict (id) do update set url=excluded.url`, id, url); err == nil {
-	_, err := r.conn.Exec(ctx, `
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error on begin transaction in postgresql: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, task := range inserted {
+		if err := postgresqlInsertTask(ctx, tx, task); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error on commit task into postgresql: %w", err)
+	}
+	applyInserted(tasks, inserted)
+	return nil
+}
+
+func postgresqlInsertTask(ctx context.Context, tx pgx.Tx, task *models.Task) error {
+	var previous *models.Task
+	existing := &models.Task{}
+	err := tx.QueryRow(
+		ctx,
+		"SELECT task_data FROM tasks WHERE uuid::uuid = $1::uuid FOR UPDATE",
+		task.UUID,
+	).Scan(existing)
+	if err == nil {
+		previous = existing
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("error on get previous task from postgresql: %w", err)
+	}
+
+	if err := task.ValidateChanges(previous); err != nil {
+		return fmt.Errorf("invalid task: %w", err)
+	}
+	if err := task.NextRevision(previous); err != nil {
+		return err
+	}
+	if err := task.UpdateTimestamps(previous, time.Now()); err != nil {
+		return fmt.Errorf("cant update timestamps: %w", err)
+	}
+	// FOR SHARE makes concurrent reparenting of two tasks to each other fail with deadlock instead of a cycle
+	err = models.CheckTaskParent(previous, task, func(UUID uuid.UUID) (*models.Task, error) {
+		parent := &models.Task{}
+		err := tx.QueryRow(ctx, "SELECT task_data FROM tasks WHERE uuid::uuid = $1::uuid FOR SHARE", UUID).Scan(parent)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return parent, err
+	})
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		tag, err := tx.Exec(ctx, `
 INSERT INTO
 	tasks(uuid, version, task_data)
 	values ($1, $2, $3)
 ON CONFLICT (uuid) 
-DO UPDATE 
-SET version = EXCLUDED.version, task_data = EXCLUDED.task_data
+DO NOTHING
 `, task.UUID, postgresqlCurrentVersion, task)
-	if err != nil {
-		return fmt.Errorf("error on insert task into postgresql: %w", err)
+		if err != nil {
+			return fmt.Errorf("error on insert task into postgresql: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			// the task was inserted concurrently, in read committed the new statement sees the committed row
+			actual := &models.Task{}
+			err := tx.QueryRow(ctx, "SELECT task_data FROM tasks WHERE uuid::uuid = $1::uuid", task.UUID).Scan(actual)
+			if err != nil {
+				return fmt.Errorf("error on get conflicting task from postgresql: %w", err)
+			}
+			return &models.TaskRevisionConflictError{UUID: task.UUID, Expected: 0, Actual: actual.Revision}
+		}
+	} else {
+		_, err := tx.Exec(
+			ctx,
+			"UPDATE tasks SET version = $2, task_data = $3 WHERE uuid::uuid = $1::uuid",
+			task.UUID, postgresqlCurrentVersion, task,
+		)
+		if err != nil {
+			return fmt.Errorf("error on update task in postgresql: %w", err)
+		}
 	}
 
+	historyEntry, err := models.NewTaskHistoryEntry(previous, task, time.Now())
+	if err != nil {
+		return fmt.Errorf("cant create history entry: %w", err)
+	}
+	if historyEntry != nil {
+		_, err = tx.Exec(
+			ctx,
+			"INSERT INTO task_history(task_uuid, changed_at, actor, changes) values ($1, $2, $3, $4)",
+			historyEntry.TaskUUID, historyEntry.At, historyEntry.Actor, historyEntry.Changes,
+		)
+		if err != nil {
+			return fmt.Errorf("error on insert task history into postgresql: %w", err)
+		}
+	}
 	return nil
 }
 
-func (r *postgresqlTasksRepository) All() ([]*models.Task, error) {
+func (r *postgresqlTasksRepository) All(ctx context.Context) ([]*models.Task, error) {
 	if r.ctx == nil {
 		return nil, fmt.Errorf("repository is not started")
 	}
@@ -121,7 +209,7 @@ func (r *postgresqlTasksRepository) All() ([]*models.Task, error) {
 		defer r.wg.Done()
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), r.readTimeout)
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
 	defer cancel()
 	result := []*models.Task{}
 	rows, err := r.conn.Query(ctx, "SELECT task_data FROM tasks")
@@ -144,6 +232,553 @@ func (r *postgresqlTasksRepository) All() ([]*models.Task, error) {
 	return result, nil
 }
 
+func (r *postgresqlTasksRepository) Find(ctx context.Context, filter *models.ListFilter, page models.Page) (*models.FindResult, error) {
+	if r.ctx == nil {
+		return nil, fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return nil, fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
+	defer cancel()
+
+	where, args := postgresqlFilterCondition(filter)
+	result := &models.FindResult{Tasks: []*models.Task{}}
+	if err := r.conn.QueryRow(ctx, "SELECT count(*) FROM tasks WHERE "+where, args...).Scan(&result.Total); err != nil {
+		return nil, fmt.Errorf("error on count tasks in postgresql: %w", err)
+	}
+
+	query := "SELECT task_data FROM tasks WHERE " + where + "\nORDER BY"
+	switch page.Order {
+	case models.OrderCompleted:
+		query += "\n\t(task_data->>'completed_at')::timestamptz DESC NULLS LAST,"
+	case models.OrderModified:
+		query += "\n\t(task_data->>'modified_at')::timestamptz DESC NULLS LAST,"
+	}
+	query += "\n\t" + postgresqlStatusRank() + ","
+	if page.Order == models.OrderUrgency {
+		args = append(args, time.Now())
+		query += "\n\t" + postgresqlUrgency(fmt.Sprintf("$%d::timestamptz", len(args))) + " DESC,"
+	}
+	query += `
+	(task_data->>'due')::timestamptz ASC NULLS LAST,
+	COALESCE(task_data->>'project', '') COLLATE "C" ASC,
+	(task_data->>'created_at')::timestamptz ASC,
+	uuid ASC`
+	if page.Offset > 0 {
+		args = append(args, page.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	if page.Limit > 0 {
+		args = append(args, page.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	rows, err := r.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error on find tasks in postgresql: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		task := &models.Task{}
+		if err := rows.Scan(task); err != nil {
+			return nil, fmt.Errorf("error on get another task from postgresql: %w", err)
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after find tasks in postgresql: %w", err)
+	}
+	return result, nil
+}
+
+// postgresqlUrgency translates models.Task.Urgency into sql expression over task_data.
+func postgresqlUrgency(now string) string {
+	days := func(column string) string {
+		return "EXTRACT(EPOCH FROM (" + now + " - (task_data->>'" + column + "')::timestamptz)) / 86400"
+	}
+	return `(
+		CASE task_data->>'priority' WHEN 'H' THEN 6.0 WHEN 'M' THEN 3.9 WHEN 'L' THEN 1.8 ELSE 0 END
+		+ CASE WHEN task_data->>'due' IS NULL THEN 0
+			ELSE 12.0 * LEAST(GREATEST((` + days("due") + ` + 14) * 0.8 / 21 + 0.2, 0.2), 1.0) END
+		+ 2.0 * LEAST(GREATEST(` + days("created_at") + ` / 365, 0), 1)
+		+ CASE jsonb_array_length(COALESCE(task_data->'tags', '[]'::jsonb)) WHEN 0 THEN 0 WHEN 1 THEN 0.8 WHEN 2 THEN 0.9 ELSE 1.0 END
+	)`
+}
+
+// postgresqlFilterCondition translates models.ListFilter.Apply into sql condition over task_data.
+func postgresqlFilterCondition(filter *models.ListFilter) (string, []any) {
+	conditions := []string{}
+	args := []any{}
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	statuses := []string{}
+	for _, status := range filter.Statuses() {
+		statuses = append(statuses, string(status))
+	}
+	conditions = append(conditions, "task_data->>'status' = ANY("+arg(statuses)+"::text[])")
+
+	if !filter.ShowWaiting {
+		conditions = append(conditions, "COALESCE((task_data->>'wait')::timestamptz <= "+arg(time.Now())+", true)")
+	}
+
+	if filter.Scopes != nil {
+		scopes := []string{"false"}
+		for _, scope := range filter.Scopes {
+			condition := "COALESCE(task_data->>'owner', '') = " + arg(scope.Owner)
+			if len(scope.Project) > 0 {
+				condition += " AND (lower(task_data->>'project') = " + arg(scope.Project) +
+					" OR starts_with(lower(task_data->>'project'), " + arg(scope.Project+".") + "))"
+			}
+			scopes = append(scopes, "("+condition+")")
+		}
+		conditions = append(conditions, "("+strings.Join(scopes, " OR ")+")")
+	}
+	if !filter.ShowArchived {
+		conditions = append(conditions, `NOT EXISTS (
+		SELECT 1 FROM projects
+		WHERE (projects.project_data->>'archived')::boolean
+			AND projects.owner = COALESCE(task_data->>'owner', '')
+			AND (lower(task_data->>'project') = projects.name OR starts_with(lower(task_data->>'project'), projects.name || '.'))
+	)`)
+	}
+	if filter.Parent != nil {
+		conditions = append(conditions, "task_data->>'parent' = "+arg(filter.Parent.String()))
+	}
+
+	timeRange := func(field string, timeRange models.TimeRange) {
+		if timeRange.After != nil {
+			conditions = append(conditions, "(task_data->>'"+field+"')::timestamptz >= "+arg(*timeRange.After))
+		}
+		if timeRange.Before != nil {
+			conditions = append(conditions, "(task_data->>'"+field+"')::timestamptz < "+arg(*timeRange.Before))
+		}
+	}
+	timeRange("completed_at", filter.Completed)
+	timeRange("modified_at", filter.Modified)
+
+	if len(filter.Project) > 0 {
+		if filter.Project == models.ProjectSelectorEmpty {
+			conditions = append(conditions, "COALESCE(task_data->>'project', '') = ''")
+		} else {
+			conditions = append(conditions, "lower(task_data->>'project') = "+arg(strings.ToLower(filter.Project)))
+		}
+	}
+
+	tags := []string{}
+	for _, tag := range filter.Tags {
+		tag = strings.ToLower(tag)
+		if tag == "project" {
+			conditions = append(conditions, "COALESCE(task_data->>'project', '') <> ''")
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) > 0 {
+		conditions = append(conditions, "COALESCE(task_data->'tags', '[]'::jsonb) @> "+arg(tags)+"::jsonb")
+	}
+
+	likeEscaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	for _, word := range filter.SearchWords {
+		conditions = append(conditions, "task_data->>'description' ILIKE '%' || "+arg(likeEscaper.Replace(word))+" || '%'")
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func (r *postgresqlTasksRepository) History(ctx context.Context, UUID uuid.UUID) ([]*models.TaskHistoryEntry, error) {
+	if r.ctx == nil {
+		return nil, fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return nil, fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
+	defer cancel()
+	result := []*models.TaskHistoryEntry{}
+	rows, err := r.conn.Query(
+		ctx,
+		"SELECT task_uuid, changed_at, actor, changes FROM task_history WHERE task_uuid = $1::uuid ORDER BY changed_at, id",
+		UUID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error on list task history from postgresql: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		entry := &models.TaskHistoryEntry{}
+		if err := rows.Scan(&entry.TaskUUID, &entry.At, &entry.Actor, &entry.Changes); err != nil {
+			return nil, fmt.Errorf("error on get another task history entry from postgresql: %w", err)
+		}
+		result = append(result, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after list task history from postgresql: %w", err)
+	}
+	return result, nil
+}
+
+func (r *postgresqlTasksRepository) Projects(ctx context.Context) ([]*models.Project, error) {
+	if r.ctx == nil {
+		return nil, fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return nil, fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
+	defer cancel()
+	result := []*models.Project{}
+	rows, err := r.conn.Query(ctx, "SELECT project_data FROM projects ORDER BY name, owner")
+	if err != nil {
+		return nil, fmt.Errorf("error on list projects from postgresql: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		project := &models.Project{}
+		if err := rows.Scan(project); err != nil {
+			return nil, fmt.Errorf("error on get another project from postgresql: %w", err)
+		}
+		result = append(result, project)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after list projects from postgresql: %w", err)
+	}
+	return result, nil
+}
+
+func (r *postgresqlTasksRepository) SaveProject(ctx context.Context, project *models.Project) error {
+	if r.ctx == nil {
+		return fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
+
+	project.Unify()
+	if err := project.Validate(); err != nil {
+		return fmt.Errorf("invalid project: %w", err)
+	}
+	_, err := r.conn.Exec(ctx, `
+INSERT INTO
+	projects(owner, name, project_data)
+	values ($1, $2, $3)
+ON CONFLICT (owner, name)
+DO UPDATE SET project_data = excluded.project_data
+`, project.Owner, project.Name, project)
+	if err != nil {
+		return fmt.Errorf("error on save project into postgresql: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresqlTasksRepository) DeleteProject(ctx context.Context, project *models.Project) error {
+	if r.ctx == nil {
+		return fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
+
+	project.Unify()
+	if _, err := r.conn.Exec(ctx, "DELETE FROM projects WHERE owner = $1 AND name = $2", project.Owner, project.Name); err != nil {
+		return fmt.Errorf("error on delete project from postgresql: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresqlTasksRepository) Filters(ctx context.Context) ([]*models.SavedFilter, error) {
+	if r.ctx == nil {
+		return nil, fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return nil, fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
+	defer cancel()
+	result := []*models.SavedFilter{}
+	rows, err := r.conn.Query(ctx, "SELECT filter_data FROM saved_filters ORDER BY name, owner")
+	if err != nil {
+		return nil, fmt.Errorf("error on list filters from postgresql: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		filter := &models.SavedFilter{}
+		if err := rows.Scan(filter); err != nil {
+			return nil, fmt.Errorf("error on get another filter from postgresql: %w", err)
+		}
+		result = append(result, filter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after list filters from postgresql: %w", err)
+	}
+	return result, nil
+}
+
+func (r *postgresqlTasksRepository) SaveFilter(ctx context.Context, filter *models.SavedFilter) error {
+	if r.ctx == nil {
+		return fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
+
+	filter.Unify()
+	if err := filter.Validate(); err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error on begin transaction in postgresql: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	if filter.Active {
+		_, err := tx.Exec(
+			ctx,
+			"UPDATE saved_filters SET filter_data = filter_data - 'active' WHERE owner = $1 AND name != $2",
+			filter.Owner, filter.Name,
+		)
+		if err != nil {
+			return fmt.Errorf("error on deactivate filters in postgresql: %w", err)
+		}
+	}
+	_, err = tx.Exec(ctx, `
+INSERT INTO
+	saved_filters(owner, name, filter_data)
+	values ($1, $2, $3)
+ON CONFLICT (owner, name)
+DO UPDATE SET filter_data = excluded.filter_data
+`, filter.Owner, filter.Name, filter)
+	if err != nil {
+		return fmt.Errorf("error on save filter into postgresql: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error on commit filter into postgresql: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresqlTasksRepository) DeleteFilter(ctx context.Context, filter *models.SavedFilter) error {
+	if r.ctx == nil {
+		return fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
+
+	filter.Unify()
+	if _, err := r.conn.Exec(ctx, "DELETE FROM saved_filters WHERE owner = $1 AND name = $2", filter.Owner, filter.Name); err != nil {
+		return fmt.Errorf("error on delete filter from postgresql: %w", err)
+	}
+	return nil
+}
+
+// Rename locks tasks with the old or the new name and all projects, so the rename is a single transaction.
+func (r *postgresqlTasksRepository) Rename(ctx context.Context, rename *models.Rename) (*models.RenamePreview, error) {
+	if r.ctx == nil {
+		return nil, fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return nil, fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
+
+	rename.Unify()
+	if err := rename.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rename: %w", err)
+	}
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error on begin transaction in postgresql: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	where := "task_data->'tags' ? $1 OR task_data->'tags' ? $2"
+	if rename.Kind == models.RenameKindProject {
+		where = "task_data->>'project' IN ($1, $2) OR starts_with(task_data->>'project', $1 || '.')"
+	}
+	rows, err := tx.Query(ctx, "SELECT task_data FROM tasks WHERE "+where+" FOR UPDATE", rename.From, rename.To)
+	if err != nil {
+		return nil, fmt.Errorf("error on list tasks from postgresql: %w", err)
+	}
+	tasks := []*models.Task{}
+	for rows.Next() {
+		task := &models.Task{}
+		if err := rows.Scan(task); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error on get another task from postgresql: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after list tasks from postgresql: %w", err)
+	}
+	preview := rename.Preview(tasks)
+	if rename.DryRun {
+		return preview, nil
+	}
+	renamed, history, err := rename.RenameTasks(tasks, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for i, task := range renamed {
+		_, err := tx.Exec(
+			ctx,
+			"UPDATE tasks SET version = $2, task_data = $3 WHERE uuid::uuid = $1::uuid",
+			task.UUID, postgresqlCurrentVersion, task,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error on update task in postgresql: %w", err)
+		}
+		_, err = tx.Exec(
+			ctx,
+			"INSERT INTO task_history(task_uuid, changed_at, actor, changes) values ($1, $2, $3, $4)",
+			history[i].TaskUUID, history[i].At, history[i].Actor, history[i].Changes,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error on insert task history into postgresql: %w", err)
+		}
+	}
+
+	rows, err = tx.Query(ctx, "SELECT project_data FROM projects FOR UPDATE")
+	if err != nil {
+		return nil, fmt.Errorf("error on list projects from postgresql: %w", err)
+	}
+	projects := []*models.Project{}
+	for rows.Next() {
+		project := &models.Project{}
+		if err := rows.Scan(project); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error on get another project from postgresql: %w", err)
+		}
+		projects = append(projects, project)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after list projects from postgresql: %w", err)
+	}
+	saved, deleted := rename.RenameProjects(projects)
+	for _, project := range deleted {
+		if _, err := tx.Exec(ctx, "DELETE FROM projects WHERE owner = $1 AND name = $2", project.Owner, project.Name); err != nil {
+			return nil, fmt.Errorf("error on delete project from postgresql: %w", err)
+		}
+	}
+	for _, project := range saved {
+		_, err := tx.Exec(ctx, `
+INSERT INTO
+	projects(owner, name, project_data)
+	values ($1, $2, $3)
+ON CONFLICT (owner, name)
+DO UPDATE SET project_data = excluded.project_data
+`, project.Owner, project.Name, project)
+		if err != nil {
+			return nil, fmt.Errorf("error on save project into postgresql: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error on commit rename into postgresql: %w", err)
+	}
+	return preview, nil
+}
+
+func (r *postgresqlTasksRepository) SaveAttachment(ctx context.Context, UUID uuid.UUID, content []byte) error {
+	if r.ctx == nil {
+		return fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
+	_, err := r.conn.Exec(ctx, "INSERT INTO attachments(uuid, content) VALUES ($1, $2)", UUID, content)
+	if err != nil {
+		return fmt.Errorf("error on insert attachment to postgresql: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresqlTasksRepository) LoadAttachment(ctx context.Context, UUID uuid.UUID) ([]byte, error) {
+	if r.ctx == nil {
+		return nil, fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return nil, fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
+	defer cancel()
+	var content []byte
+	err := r.conn.QueryRow(ctx, "SELECT content FROM attachments WHERE uuid = $1::uuid", UUID).Scan(&content)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error on get attachment from postgresql: %w", err)
+	}
+	return content, nil
+}
+
 func (r *postgresqlTasksRepository) Stop() {
 	r.wg.Wait()
 	if r.conn != nil {
@@ -210,3 +845,13 @@ func (r *postgresqlTasksRepository) Start(ctx context.Context, stopper chan<- er
 
 	return nil
 }
+
+// postgresqlStatusRank is the sql version of models.StatusRank, status names are validated by the config.
+func postgresqlStatusRank() string {
+	result := "CASE task_data->>'status'"
+	for _, definition := range models.GetTaskStatuses() {
+		status, _ := models.NewTaskStatus(definition.Name)
+		result += fmt.Sprintf(" WHEN '%s' THEN %d", definition.Name, models.StatusRank(status))
+	}
+	return result + fmt.Sprintf(" ELSE %d END", models.StatusRank(""))
+}
@@ -0,0 +1,8 @@
+CREATE TABLE task_history (
+    id          bigserial   PRIMARY KEY,
+    task_uuid   uuid        NOT NULL,
+    changed_at  timestamptz NOT NULL,
+    actor       text        NOT NULL,
+    changes     jsonb       NOT NULL
+);
+CREATE INDEX task_history_task_uuid_idx ON task_history (task_uuid, changed_at);
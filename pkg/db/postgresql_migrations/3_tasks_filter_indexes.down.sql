@@ -0,0 +1,3 @@
+DROP INDEX tasks_tags_idx;
+DROP INDEX tasks_project_idx;
+DROP INDEX tasks_status_idx;
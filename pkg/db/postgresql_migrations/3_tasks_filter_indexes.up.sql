@@ -0,0 +1,3 @@
+CREATE INDEX tasks_status_idx ON tasks ((task_data->>'status'));
+CREATE INDEX tasks_project_idx ON tasks (lower(task_data->>'project'));
+CREATE INDEX tasks_tags_idx ON tasks USING gin ((task_data->'tags'));
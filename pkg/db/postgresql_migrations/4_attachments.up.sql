@@ -0,0 +1,4 @@
+CREATE TABLE attachments (
+    uuid        uuid        PRIMARY KEY,
+    content     bytea       NOT NULL
+);
@@ -0,0 +1 @@
+DROP INDEX tasks_owner_idx;
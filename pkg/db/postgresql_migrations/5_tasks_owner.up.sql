@@ -0,0 +1 @@
+CREATE INDEX tasks_owner_idx ON tasks ((COALESCE(task_data->>'owner', '')));
@@ -0,0 +1,6 @@
+CREATE TABLE projects (
+    owner           text        NOT NULL,
+    name            text        NOT NULL,
+    project_data    jsonb       NOT NULL,
+    PRIMARY KEY (owner, name)
+);
@@ -2,12 +2,15 @@ package db
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/paragor/todo/pkg/models"
 	"io"
 	"net/http"
+	"strings"
 )
 
 type remoteRepository struct {
@@ -24,8 +27,8 @@ func (r *remoteRepository) addAuth(request *http.Request) {
 	request.Header.Set("Authorization", r.token)
 }
 
-func (r *remoteRepository) Ping() error {
-	request, err := http.NewRequest("GET", r.addr+"/api/ping", nil)
+func (r *remoteRepository) Ping(ctx context.Context) error {
+	request, err := http.NewRequestWithContext(ctx, "GET", r.addr+"/api/ping", nil)
 	if err != nil {
 		return fmt.Errorf("cant create request: %w", err)
 	}
@@ -42,13 +45,16 @@ func (r *remoteRepository) Ping() error {
 	return nil
 }
 
-func (r *remoteRepository) Get(UUID uuid.UUID) (*models.Task, error) {
-	request, err := http.NewRequest("GET", r.addr+"/api/get_task?uuid="+UUID.String(), nil)
+func (r *remoteRepository) Get(ctx context.Context, UUID uuid.UUID) (*models.Task, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", r.addr+"/api/get_task?uuid="+UUID.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("cant create request: %w", err)
 	}
 	r.addAuth(request)
 	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("cant connect to remote server: %w", err)
+	}
 	defer response.Body.Close()
 	if response.StatusCode == 204 {
 		return nil, nil
@@ -70,12 +76,24 @@ func (r *remoteRepository) Get(UUID uuid.UUID) (*models.Task, error) {
 	return task, nil
 }
 
-func (r *remoteRepository) Insert(t *models.Task) error {
-	requestData, err := json.Marshal(t)
+// Insert saves the single task with /api/insert_task, so older servers are supported, and several tasks at once
+// with /api/insert_tasks.
+func (r *remoteRepository) Insert(ctx context.Context, tasks ...*models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	t := tasks[0]
+	path := "/api/insert_task"
+	var requestValue any = t
+	if len(tasks) > 1 {
+		path = "/api/insert_tasks"
+		requestValue = tasks
+	}
+	requestData, err := json.Marshal(requestValue)
 	if err != nil {
 		return fmt.Errorf("cant marshal task: %w", err)
 	}
-	request, err := http.NewRequest("PUT", r.addr+"/api/insert_task", bytes.NewReader(requestData))
+	request, err := http.NewRequestWithContext(ctx, "PUT", r.addr+path, bytes.NewReader(requestData))
 	if err != nil {
 		return fmt.Errorf("cant create request: %w", err)
 	}
@@ -88,21 +106,44 @@ func (r *remoteRepository) Insert(t *models.Task) error {
 	defer response.Body.Close()
 	data, err := io.ReadAll(response.Body)
 	if err != nil {
-		if response.StatusCode == 200 {
-			return nil
-		}
 		return fmt.Errorf("cant read data from remote server: status code: %d", response.StatusCode)
 	}
 
+	if response.StatusCode == http.StatusConflict {
+		conflict := &models.TaskRevisionConflictError{}
+		if err := json.Unmarshal(data, conflict); err == nil && conflict.UUID != uuid.Nil {
+			return conflict
+		}
+		// older servers send only the message
+		return errors.New(strings.TrimSpace(string(data[:min(255, len(data))])))
+	}
 	if response.StatusCode != 200 {
 		return fmt.Errorf("unexpected status code from remote server: status code %d; pody part: %s", response.StatusCode, string(data[:min(255, len(data))]))
 	}
+	if len(data) == 0 {
+		return nil
+	}
+	// unmarshal into empty tasks, omitted fields (e.g. cleared timestamps) should not keep local values
+	inserted := []*models.Task{{}}
+	var responseValue any = inserted[0]
+	if len(tasks) > 1 {
+		responseValue = &inserted
+	}
+	if err := json.Unmarshal(data, responseValue); err != nil {
+		return fmt.Errorf("unmarshal error:%w, status code %d; pody part: %s", err, response.StatusCode, string(data[:min(255, len(data))]))
+	}
+	if len(inserted) != len(tasks) {
+		return fmt.Errorf("unexpected number of inserted tasks from remote server: %d", len(inserted))
+	}
+	for i := range tasks {
+		*tasks[i] = *inserted[i]
+	}
 
 	return nil
 }
 
-func (r *remoteRepository) All() ([]*models.Task, error) {
-	request, err := http.NewRequest("GET", r.addr+"/api/all", nil)
+func (r *remoteRepository) All(ctx context.Context) ([]*models.Task, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", r.addr+"/api/all", nil)
 	if err != nil {
 		return nil, fmt.Errorf("cant create request: %w", err)
 	}
@@ -128,3 +169,192 @@ func (r *remoteRepository) All() ([]*models.Task, error) {
 
 	return tasks, nil
 }
+
+func (r *remoteRepository) History(ctx context.Context, UUID uuid.UUID) ([]*models.TaskHistoryEntry, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", r.addr+"/api/task_history?uuid="+UUID.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cant create request: %w", err)
+	}
+	r.addAuth(request)
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("cant connect to remote server: %w", err)
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cant read data from remote server: status code: %d", response.StatusCode)
+	}
+
+	if response.StatusCode != 200 {
+		return nil, fmt.Errorf("unexpected status code from remote server: status code %d; pody part: %s", response.StatusCode, string(data[:min(255, len(data))]))
+	}
+
+	history := []*models.TaskHistoryEntry{}
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("unmarshal error:%w, status code %d; pody part: %s", err, response.StatusCode, string(data[:min(255, len(data))]))
+	}
+
+	return history, nil
+}
+
+func (r *remoteRepository) Find(ctx context.Context, filter *models.ListFilter, page models.Page) (*models.FindResult, error) {
+	query := models.PageToQuery(models.ListFilterToQuery(filter), page)
+	request, err := http.NewRequestWithContext(ctx, "GET", r.addr+"/api/find?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cant create request: %w", err)
+	}
+	r.addAuth(request)
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("cant connect to remote server: %w", err)
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cant read data from remote server: status code: %d", response.StatusCode)
+	}
+
+	if response.StatusCode != 200 {
+		return nil, fmt.Errorf("unexpected status code from remote server: status code %d; pody part: %s", response.StatusCode, string(data[:min(255, len(data))]))
+	}
+
+	result := &models.FindResult{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("unmarshal error:%w, status code %d; pody part: %s", err, response.StatusCode, string(data[:min(255, len(data))]))
+	}
+
+	return result, nil
+}
+
+func (r *remoteRepository) Projects(ctx context.Context) ([]*models.Project, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", r.addr+"/api/projects", nil)
+	if err != nil {
+		return nil, fmt.Errorf("cant create request: %w", err)
+	}
+	r.addAuth(request)
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("cant connect to remote server: %w", err)
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cant read data from remote server: status code: %d", response.StatusCode)
+	}
+
+	if response.StatusCode != 200 {
+		return nil, fmt.Errorf("unexpected status code from remote server: status code %d; pody part: %s", response.StatusCode, string(data[:min(255, len(data))]))
+	}
+
+	projects := []*models.Project{}
+	if err := json.Unmarshal(data, &projects); err != nil {
+		return nil, fmt.Errorf("unmarshal error:%w, status code %d; pody part: %s", err, response.StatusCode, string(data[:min(255, len(data))]))
+	}
+
+	return projects, nil
+}
+
+func (r *remoteRepository) Filters(ctx context.Context) ([]*models.SavedFilter, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", r.addr+"/api/filters", nil)
+	if err != nil {
+		return nil, fmt.Errorf("cant create request: %w", err)
+	}
+	r.addAuth(request)
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("cant connect to remote server: %w", err)
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cant read data from remote server: status code: %d", response.StatusCode)
+	}
+
+	if response.StatusCode != 200 {
+		return nil, fmt.Errorf("unexpected status code from remote server: status code %d; pody part: %s", response.StatusCode, string(data[:min(255, len(data))]))
+	}
+
+	filters := []*models.SavedFilter{}
+	if err := json.Unmarshal(data, &filters); err != nil {
+		return nil, fmt.Errorf("unmarshal error:%w, status code %d; pody part: %s", err, response.StatusCode, string(data[:min(255, len(data))]))
+	}
+
+	return filters, nil
+}
+
+func (r *remoteRepository) SaveFilter(ctx context.Context, filter *models.SavedFilter) error {
+	return r.put(ctx, "/api/save_filter", filter)
+}
+
+func (r *remoteRepository) DeleteFilter(ctx context.Context, filter *models.SavedFilter) error {
+	return r.put(ctx, "/api/delete_filter", filter)
+}
+
+func (r *remoteRepository) SaveProject(ctx context.Context, project *models.Project) error {
+	return r.put(ctx, "/api/save_project", project)
+}
+
+func (r *remoteRepository) DeleteProject(ctx context.Context, project *models.Project) error {
+	return r.put(ctx, "/api/delete_project", project)
+}
+
+// put sends the project or the saved filter to the api path.
+func (r *remoteRepository) put(ctx context.Context, path string, value any) error {
+	requestData, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("cant marshal %T: %w", value, err)
+	}
+	request, err := http.NewRequestWithContext(ctx, "PUT", r.addr+path, bytes.NewReader(requestData))
+	if err != nil {
+		return fmt.Errorf("cant create request: %w", err)
+	}
+	r.addAuth(request)
+
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("cant connect to remote server: %w", err)
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("cant read data from remote server: status code: %d", response.StatusCode)
+	}
+
+	if response.StatusCode != 200 {
+		return fmt.Errorf("unexpected status code from remote server: status code %d; pody part: %s", response.StatusCode, string(data[:min(255, len(data))]))
+	}
+	return nil
+}
+
+func (r *remoteRepository) Rename(ctx context.Context, rename *models.Rename) (*models.RenamePreview, error) {
+	requestData, err := json.Marshal(rename)
+	if err != nil {
+		return nil, fmt.Errorf("cant marshal rename: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, "PUT", r.addr+"/api/rename", bytes.NewReader(requestData))
+	if err != nil {
+		return nil, fmt.Errorf("cant create request: %w", err)
+	}
+	r.addAuth(request)
+
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("cant connect to remote server: %w", err)
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cant read data from remote server: status code: %d", response.StatusCode)
+	}
+
+	if response.StatusCode != 200 {
+		return nil, fmt.Errorf("unexpected status code from remote server: status code %d; pody part: %s", response.StatusCode, string(data[:min(255, len(data))]))
+	}
+
+	result := &models.RenamePreview{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("unmarshal error:%w, status code %d; pody part: %s", err, response.StatusCode, string(data[:min(255, len(data))]))
+	}
+	return result, nil
+}
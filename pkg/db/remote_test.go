@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/paragor/todo/pkg/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRemoteInsertConflict(t *testing.T) {
+	task := models.NewTask()
+	task.Description = "stale"
+	body := `{"uuid":"` + task.UUID.String() + `","expected":0,"actual":3}`
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusConflict)
+		_, _ = writer.Write([]byte(body))
+	}))
+	defer server.Close()
+	repo := NewRemoteRepository(server.URL, "", server.Client())
+
+	err := repo.Insert(context.Background(), task)
+	conflict := &models.TaskRevisionConflictError{}
+	if !errors.As(err, &conflict) || conflict.UUID != task.UUID || conflict.Actual != 3 {
+		t.Errorf("conflict from the server should be decoded, have %v", err)
+	}
+
+	body = "cant insert task: task conflict\n"
+	err = repo.Insert(context.Background(), task)
+	if err == nil || err.Error() != "cant insert task: task conflict" {
+		t.Errorf("message of the server should be returned as is, have %v", err)
+	}
+}
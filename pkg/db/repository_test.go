@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"github.com/paragor/todo/pkg/models"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testRepository interface {
+	models.Repository
+	Start(ctx context.Context, stopper chan<- error) error
+	Stop()
+}
+
+// testRepositories are backends started in the temp dir, postgresql is tested with TODO_TEST_POSTGRESQL connection string.
+func testRepositories(t *testing.T) map[string]func(t *testing.T) testRepository {
+	start := func(t *testing.T, repo testRepository) testRepository {
+		t.Helper()
+		if err := repo.Start(context.Background(), make(chan error, 1)); err != nil {
+			t.Fatalf("cant start repository: %s", err)
+		}
+		t.Cleanup(repo.Stop)
+		return repo
+	}
+	return map[string]func(t *testing.T) testRepository{
+		"inmemory": func(t *testing.T) testRepository {
+			return start(t, NewInMemoryTasksRepository(filepath.Join(t.TempDir(), "database.json")))
+		},
+		"sqlite": func(t *testing.T) testRepository {
+			return start(t, NewSqliteTasksRepository(filepath.Join(t.TempDir(), "database.sqlite")))
+		},
+		"postgresql": func(t *testing.T) testRepository {
+			connString := os.Getenv("TODO_TEST_POSTGRESQL")
+			if len(connString) == 0 {
+				t.Skip("TODO_TEST_POSTGRESQL is not set")
+			}
+			return start(t, NewPostgresqlTasksRepository(connString))
+		},
+	}
+}
+
+func TestRepositoryInsertBatchWithParent(t *testing.T) {
+	for name, newRepository := range testRepositories(t) {
+		t.Run(name, func(t *testing.T) {
+			repo := newRepository(t)
+			parent := models.NewTask()
+			parent.Description = "release"
+			child := models.NewTask()
+			child.Description = "write changelog"
+			child.Parent = &parent.UUID
+			if err := repo.Insert(context.Background(), parent, child); err != nil {
+				t.Fatalf("new parent with its child should be inserted at once: %s", err)
+			}
+			stored, err := repo.Get(context.Background(), child.UUID)
+			if err != nil || stored == nil || stored.Parent == nil || *stored.Parent != parent.UUID {
+				t.Errorf("child should be stored with the parent: %+v, %v", stored, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,708 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	migrateSqlite "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/google/uuid"
+	"github.com/paragor/todo/pkg/models"
+	_ "modernc.org/sqlite"
+)
+
+const sqliteCurrentVersion = 1
+
+type sqliteTasksRepository struct {
+	conn         *sql.DB
+	filepath     string
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+
+	wg sync.WaitGroup
+
+	ctx    context.Context
+	cancel func()
+}
+
+func NewSqliteTasksRepository(filepath string) *sqliteTasksRepository {
+	return &sqliteTasksRepository{
+		readTimeout:  time.Second * 5,
+		writeTimeout: time.Second * 5,
+		filepath:     filepath,
+	}
+}
+
+func (r *sqliteTasksRepository) dsn() string {
+	query := url.Values{}
+	query.Add("_pragma", "busy_timeout(5000)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Add("_txlock", "immediate")
+	return "file:" + r.filepath + "?" + query.Encode()
+}
+
+func (r *sqliteTasksRepository) Get(ctx context.Context, UUID uuid.UUID) (*models.Task, error) {
+	if r.ctx == nil {
+		return nil, fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return nil, fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
+	defer cancel()
+
+	task, err := sqliteGetTask(r.conn.QueryRowContext(ctx, "SELECT task_data FROM tasks WHERE uuid = ?", UUID.String()))
+	if err != nil {
+		return nil, fmt.Errorf("error on get task from database: %w", err)
+	}
+	return task, nil
+}
+
+func sqliteGetTask(row *sql.Row) (*models.Task, error) {
+	var data string
+	if err := row.Scan(&data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	task := &models.Task{}
+	if err := json.Unmarshal([]byte(data), task); err != nil {
+		return nil, fmt.Errorf("cant unmarshal task: %w", err)
+	}
+	return task, nil
+}
+
+func (r *sqliteTasksRepository) Insert(ctx context.Context, tasks ...*models.Task) error {
+	if r.ctx == nil {
+		return fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
+
+	inserted := cloneForInsert(tasks)
+	for _, task := range inserted {
+		task.Unify()
+		if err := task.Validate(); err != nil {
+			return fmt.Errorf("invalid task: %w", err)
+		}
+	}
+
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error on begin transaction in sqlite: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, task := range inserted {
+		if err := sqliteInsertTask(ctx, tx, task); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error on commit task into sqlite: %w", err)
+	}
+	applyInserted(tasks, inserted)
+	return nil
+}
+
+func sqliteInsertTask(ctx context.Context, tx *sql.Tx, task *models.Task) error {
+	previous, err := sqliteGetTask(tx.QueryRowContext(ctx, "SELECT task_data FROM tasks WHERE uuid = ?", task.UUID.String()))
+	if err != nil {
+		return fmt.Errorf("error on get previous task from sqlite: %w", err)
+	}
+	if err := task.ValidateChanges(previous); err != nil {
+		return fmt.Errorf("invalid task: %w", err)
+	}
+	if err := task.NextRevision(previous); err != nil {
+		return err
+	}
+	if err := task.UpdateTimestamps(previous, time.Now()); err != nil {
+		return fmt.Errorf("cant update timestamps: %w", err)
+	}
+	err = models.CheckTaskParent(previous, task, func(UUID uuid.UUID) (*models.Task, error) {
+		return sqliteGetTask(tx.QueryRowContext(ctx, "SELECT task_data FROM tasks WHERE uuid = ?", UUID.String()))
+	})
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("cant marshal task: %w", err)
+	}
+	if previous == nil {
+		_, err = tx.ExecContext(
+			ctx,
+			"INSERT INTO tasks(uuid, version, task_data) VALUES (?, ?, ?)",
+			task.UUID.String(), sqliteCurrentVersion, string(data),
+		)
+		if err != nil {
+			return fmt.Errorf("error on insert task into sqlite: %w", err)
+		}
+	} else {
+		_, err = tx.ExecContext(
+			ctx,
+			"UPDATE tasks SET version = ?, task_data = ? WHERE uuid = ?",
+			sqliteCurrentVersion, string(data), task.UUID.String(),
+		)
+		if err != nil {
+			return fmt.Errorf("error on update task in sqlite: %w", err)
+		}
+	}
+
+	historyEntry, err := models.NewTaskHistoryEntry(previous, task, time.Now())
+	if err != nil {
+		return fmt.Errorf("cant create history entry: %w", err)
+	}
+	if historyEntry != nil {
+		if err := sqliteInsertHistory(ctx, tx, historyEntry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sqliteInsertHistory(ctx context.Context, tx *sql.Tx, historyEntry *models.TaskHistoryEntry) error {
+	changes, err := json.Marshal(historyEntry.Changes)
+	if err != nil {
+		return fmt.Errorf("cant marshal history changes: %w", err)
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO task_history(task_uuid, changed_at, actor, changes) VALUES (?, ?, ?, ?)",
+		historyEntry.TaskUUID.String(), historyEntry.At.UTC().Format(time.RFC3339Nano), historyEntry.Actor, string(changes),
+	)
+	if err != nil {
+		return fmt.Errorf("error on insert task history into sqlite: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteTasksRepository) All(ctx context.Context) ([]*models.Task, error) {
+	if r.ctx == nil {
+		return nil, fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return nil, fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
+	defer cancel()
+	result, err := r.queryTasks(ctx, r.conn, "SELECT task_data FROM tasks")
+	if err != nil {
+		return nil, fmt.Errorf("error on list tasks from sqlite: %w", err)
+	}
+	return result, nil
+}
+
+// Find narrows tasks by status in sql, the rest of the filter and the ordering are done by models.FindInTasks,
+// that is cheap enough for a single user database.
+func (r *sqliteTasksRepository) Find(ctx context.Context, filter *models.ListFilter, page models.Page) (*models.FindResult, error) {
+	if r.ctx == nil {
+		return nil, fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return nil, fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
+	defer cancel()
+
+	statuses := []any{}
+	for _, status := range filter.Statuses() {
+		statuses = append(statuses, string(status))
+	}
+	if len(statuses) == 0 {
+		return &models.FindResult{Tasks: []*models.Task{}}, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(statuses)), ", ")
+	tasks, err := r.queryTasks(
+		ctx,
+		r.conn,
+		"SELECT task_data FROM tasks WHERE json_extract(task_data, '$.status') IN ("+placeholders+")",
+		statuses...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error on find tasks in sqlite: %w", err)
+	}
+	projects, err := r.queryProjects(ctx, r.conn)
+	if err != nil {
+		return nil, fmt.Errorf("error on list projects from sqlite: %w", err)
+	}
+	withArchived := *filter
+	withArchived.Archived = models.ArchivedScopes(projects)
+	return models.FindInTasks(tasks, &withArchived, page), nil
+}
+
+// sqliteQuerier is the connection or the transaction.
+type sqliteQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func (r *sqliteTasksRepository) queryTasks(ctx context.Context, conn sqliteQuerier, query string, args ...any) ([]*models.Task, error) {
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := []*models.Task{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		task := &models.Task{}
+		if err := json.Unmarshal([]byte(data), task); err != nil {
+			return nil, fmt.Errorf("cant unmarshal task: %w", err)
+		}
+		result = append(result, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *sqliteTasksRepository) History(ctx context.Context, UUID uuid.UUID) ([]*models.TaskHistoryEntry, error) {
+	if r.ctx == nil {
+		return nil, fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return nil, fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
+	defer cancel()
+	result := []*models.TaskHistoryEntry{}
+	rows, err := r.conn.QueryContext(
+		ctx,
+		"SELECT changed_at, actor, changes FROM task_history WHERE task_uuid = ? ORDER BY id",
+		UUID.String(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error on list task history from sqlite: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var changedAt, changes string
+		entry := &models.TaskHistoryEntry{TaskUUID: UUID}
+		if err := rows.Scan(&changedAt, &entry.Actor, &changes); err != nil {
+			return nil, fmt.Errorf("error on get another task history entry from sqlite: %w", err)
+		}
+		if entry.At, err = time.Parse(time.RFC3339Nano, changedAt); err != nil {
+			return nil, fmt.Errorf("cant parse task history date: %w", err)
+		}
+		if err := json.Unmarshal([]byte(changes), &entry.Changes); err != nil {
+			return nil, fmt.Errorf("cant unmarshal task history changes: %w", err)
+		}
+		result = append(result, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after list task history from sqlite: %w", err)
+	}
+	return result, nil
+}
+
+func (r *sqliteTasksRepository) Projects(ctx context.Context) ([]*models.Project, error) {
+	if r.ctx == nil {
+		return nil, fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return nil, fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
+	defer cancel()
+	result, err := r.queryProjects(ctx, r.conn)
+	if err != nil {
+		return nil, fmt.Errorf("error on list projects from sqlite: %w", err)
+	}
+	return result, nil
+}
+
+func (r *sqliteTasksRepository) queryProjects(ctx context.Context, conn sqliteQuerier) ([]*models.Project, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT project_data FROM projects ORDER BY name, owner")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := []*models.Project{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		project := &models.Project{}
+		if err := json.Unmarshal([]byte(data), project); err != nil {
+			return nil, fmt.Errorf("cant unmarshal project: %w", err)
+		}
+		result = append(result, project)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *sqliteTasksRepository) SaveProject(ctx context.Context, project *models.Project) error {
+	if r.ctx == nil {
+		return fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
+
+	project.Unify()
+	if err := project.Validate(); err != nil {
+		return fmt.Errorf("invalid project: %w", err)
+	}
+	data, err := json.Marshal(project)
+	if err != nil {
+		return fmt.Errorf("cant marshal project: %w", err)
+	}
+	_, err = r.conn.ExecContext(
+		ctx,
+		"INSERT INTO projects(owner, name, project_data) VALUES (?, ?, ?) ON CONFLICT (owner, name) DO UPDATE SET project_data = excluded.project_data",
+		project.Owner, project.Name, string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("error on save project into sqlite: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteTasksRepository) DeleteProject(ctx context.Context, project *models.Project) error {
+	if r.ctx == nil {
+		return fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
+
+	project.Unify()
+	if _, err := r.conn.ExecContext(ctx, "DELETE FROM projects WHERE owner = ? AND name = ?", project.Owner, project.Name); err != nil {
+		return fmt.Errorf("error on delete project from sqlite: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteTasksRepository) Filters(ctx context.Context) ([]*models.SavedFilter, error) {
+	if r.ctx == nil {
+		return nil, fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return nil, fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
+	defer cancel()
+	rows, err := r.conn.QueryContext(ctx, "SELECT filter_data FROM saved_filters ORDER BY name, owner")
+	if err != nil {
+		return nil, fmt.Errorf("error on list filters from sqlite: %w", err)
+	}
+	defer rows.Close()
+	result := []*models.SavedFilter{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("error on get another filter from sqlite: %w", err)
+		}
+		filter := &models.SavedFilter{}
+		if err := json.Unmarshal([]byte(data), filter); err != nil {
+			return nil, fmt.Errorf("cant unmarshal filter: %w", err)
+		}
+		result = append(result, filter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error after list filters from sqlite: %w", err)
+	}
+	return result, nil
+}
+
+func (r *sqliteTasksRepository) SaveFilter(ctx context.Context, filter *models.SavedFilter) error {
+	if r.ctx == nil {
+		return fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
+
+	filter.Unify()
+	if err := filter.Validate(); err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return fmt.Errorf("cant marshal filter: %w", err)
+	}
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error on begin transaction in sqlite: %w", err)
+	}
+	defer tx.Rollback()
+	if filter.Active {
+		_, err = tx.ExecContext(
+			ctx,
+			"UPDATE saved_filters SET filter_data = json_remove(filter_data, '$.active') WHERE owner = ? AND name != ?",
+			filter.Owner, filter.Name,
+		)
+		if err != nil {
+			return fmt.Errorf("error on deactivate filters in sqlite: %w", err)
+		}
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO saved_filters(owner, name, filter_data) VALUES (?, ?, ?) ON CONFLICT (owner, name) DO UPDATE SET filter_data = excluded.filter_data",
+		filter.Owner, filter.Name, string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("error on save filter into sqlite: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error on commit filter into sqlite: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteTasksRepository) DeleteFilter(ctx context.Context, filter *models.SavedFilter) error {
+	if r.ctx == nil {
+		return fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
+
+	filter.Unify()
+	if _, err := r.conn.ExecContext(ctx, "DELETE FROM saved_filters WHERE owner = ? AND name = ?", filter.Owner, filter.Name); err != nil {
+		return fmt.Errorf("error on delete filter from sqlite: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteTasksRepository) Rename(ctx context.Context, rename *models.Rename) (*models.RenamePreview, error) {
+	if r.ctx == nil {
+		return nil, fmt.Errorf("repository is not started")
+	}
+	select {
+	case <-r.ctx.Done():
+		return nil, fmt.Errorf("repository is closed")
+	default:
+		r.wg.Add(1)
+		defer r.wg.Done()
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
+
+	rename.Unify()
+	if err := rename.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rename: %w", err)
+	}
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error on begin transaction in sqlite: %w", err)
+	}
+	defer tx.Rollback()
+
+	tasks, err := r.queryTasks(ctx, tx, "SELECT task_data FROM tasks")
+	if err != nil {
+		return nil, fmt.Errorf("error on list tasks from sqlite: %w", err)
+	}
+	preview := rename.Preview(tasks)
+	if rename.DryRun {
+		return preview, nil
+	}
+	renamed, history, err := rename.RenameTasks(tasks, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for i, task := range renamed {
+		data, err := json.Marshal(task)
+		if err != nil {
+			return nil, fmt.Errorf("cant marshal task: %w", err)
+		}
+		_, err = tx.ExecContext(
+			ctx,
+			"UPDATE tasks SET version = ?, task_data = ? WHERE uuid = ?",
+			sqliteCurrentVersion, string(data), task.UUID.String(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error on update task in sqlite: %w", err)
+		}
+		if err := sqliteInsertHistory(ctx, tx, history[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	projects, err := r.queryProjects(ctx, tx)
+	if err != nil {
+		return nil, fmt.Errorf("error on list projects from sqlite: %w", err)
+	}
+	saved, deleted := rename.RenameProjects(projects)
+	for _, project := range deleted {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM projects WHERE owner = ? AND name = ?", project.Owner, project.Name); err != nil {
+			return nil, fmt.Errorf("error on delete project from sqlite: %w", err)
+		}
+	}
+	for _, project := range saved {
+		data, err := json.Marshal(project)
+		if err != nil {
+			return nil, fmt.Errorf("cant marshal project: %w", err)
+		}
+		_, err = tx.ExecContext(
+			ctx,
+			"INSERT INTO projects(owner, name, project_data) VALUES (?, ?, ?) ON CONFLICT (owner, name) DO UPDATE SET project_data = excluded.project_data",
+			project.Owner, project.Name, string(data),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error on save project into sqlite: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error on commit rename into sqlite: %w", err)
+	}
+	return preview, nil
+}
+
+func (r *sqliteTasksRepository) Stop() {
+	r.wg.Wait()
+	if r.conn != nil {
+		_ = r.conn.Close()
+	}
+}
+
+//go:embed sqlite_migrations/*
+var sqliteMigrationsFS embed.FS
+
+func (r *sqliteTasksRepository) Start(ctx context.Context, stopper chan<- error) error {
+	if err := r.migrate(); err != nil {
+		return err
+	}
+
+	conn, err := sql.Open("sqlite", r.dsn())
+	if err != nil {
+		return fmt.Errorf("error on open sqlite: %w", err)
+	}
+	// sqlite allows only one writer, so one connection saves from "database is locked" errors
+	conn.SetMaxOpenConns(1)
+	{
+		pingCtx, pingCtxCancel := context.WithTimeout(ctx, min(r.writeTimeout, r.readTimeout))
+		defer pingCtxCancel()
+		if err := conn.PingContext(pingCtx); err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("cant ping sqlite: %w", err)
+		}
+	}
+
+	r.conn = conn
+	r.ctx, r.cancel = context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-r.ctx.Done():
+			err := r.ctx.Err()
+			stopper <- fmt.Errorf("stop db.sqlite: %w", err)
+		}
+	}()
+
+	return nil
+}
+
+func (r *sqliteTasksRepository) migrate() error {
+	migrationsSource, err := iofs.New(sqliteMigrationsFS, "sqlite_migrations")
+	if err != nil {
+		return fmt.Errorf("error on read migrations sqlite: %w", err)
+	}
+	conn, err := sql.Open("sqlite", r.dsn())
+	if err != nil {
+		return fmt.Errorf("error on open sqlite for migrations: %w", err)
+	}
+	driver, err := migrateSqlite.WithInstance(conn, &migrateSqlite.Config{})
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("error on create migrations driver of sqlite: %w", err)
+	}
+	migrations, err := migrate.NewWithInstance("iofs", migrationsSource, "sqlite", driver)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("error on create migrations instance of sqlite: %w", err)
+	}
+	// closes conn too
+	defer migrations.Close()
+
+	log.Println("start sqlite migrations")
+	if err := migrations.Up(); err != nil {
+		if !errors.Is(err, migrate.ErrNoChange) {
+			return fmt.Errorf("error on run migrations sqlite: %w", err)
+		}
+		log.Println(err.Error())
+	}
+	log.Println("finish sqlite migrations")
+	return nil
+}
@@ -0,0 +1,14 @@
+CREATE TABLE tasks (
+    uuid        text        PRIMARY KEY,
+    version     integer     NOT NULL,
+    task_data   text        NOT NULL
+);
+CREATE INDEX tasks_status_idx ON tasks (json_extract(task_data, '$.status'));
+CREATE TABLE task_history (
+    id          integer     PRIMARY KEY AUTOINCREMENT,
+    task_uuid   text        NOT NULL,
+    changed_at  text        NOT NULL,
+    actor       text        NOT NULL,
+    changes     text        NOT NULL
+);
+CREATE INDEX task_history_task_uuid_idx ON task_history (task_uuid, id);
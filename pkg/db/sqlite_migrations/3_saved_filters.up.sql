@@ -0,0 +1,6 @@
+CREATE TABLE saved_filters (
+    owner           text        NOT NULL,
+    name            text        NOT NULL,
+    filter_data     text        NOT NULL,
+    PRIMARY KEY (owner, name)
+);
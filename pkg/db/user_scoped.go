@@ -0,0 +1,232 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/paragor/todo/pkg/models"
+	"slices"
+)
+
+// userScopedRepository restricts the repository to tasks visible to the user from the context, see models.WithUser.
+// The user sees own tasks and projects shared with the user, changes are checked against the project role.
+type userScopedRepository struct {
+	db     models.Repository
+	shares models.ProjectShares
+}
+
+func NewUserScopedRepository(db models.Repository, shares models.ProjectShares) *userScopedRepository {
+	return &userScopedRepository{db: db, shares: shares}
+}
+
+func (r *userScopedRepository) user(ctx context.Context) (*models.User, error) {
+	user := models.UserFromContext(ctx)
+	if user == nil {
+		return nil, models.NoUserError
+	}
+	return user, nil
+}
+
+func (r *userScopedRepository) Get(ctx context.Context, UUID uuid.UUID) (*models.Task, error) {
+	user, err := r.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	task, err := r.db.Get(ctx, UUID)
+	if err != nil || task == nil || !r.shares.Role(user, task).Allows(models.ProjectViewer) {
+		return nil, err
+	}
+	return task, nil
+}
+
+// Insert keeps the owner of the changed task, new tasks of shared projects are owned by the sharing user
+// and get default tags of the project.
+// Editors can change tasks within shared projects, only owners can delete them.
+func (r *userScopedRepository) Insert(ctx context.Context, tasks ...*models.Task) error {
+	user, err := r.user(ctx)
+	if err != nil {
+		return err
+	}
+	inserted := cloneForInsert(tasks)
+	for i, t := range inserted {
+		if err := r.checkInsert(ctx, user, inserted[:i], t); err != nil {
+			return err
+		}
+	}
+	if err := r.db.Insert(ctx, inserted...); err != nil {
+		return err
+	}
+	applyInserted(tasks, inserted)
+	return nil
+}
+
+// checkInsert sets the owner of the task and checks the user can save it, batch are previous tasks of the same insert.
+func (r *userScopedRepository) checkInsert(ctx context.Context, user *models.User, batch []*models.Task, t *models.Task) error {
+	previous, err := r.db.Get(ctx, t.UUID)
+	if err != nil {
+		return fmt.Errorf("cant get previous task: %w", err)
+	}
+	if previous != nil {
+		if !r.shares.Role(user, previous).Allows(models.ProjectEditor) {
+			return fmt.Errorf("%w: cant change task %s", models.TaskPermissionError, t.UUID)
+		}
+		t.Owner = previous.Owner
+	} else {
+		t.Owner = r.shares.OwnerOf(user, t.Project)
+		projects, err := r.db.Projects(ctx)
+		if err != nil {
+			return fmt.Errorf("cant get projects: %w", err)
+		}
+		if project := models.FindProject(projects, t.Owner, t.Project); project != nil {
+			project.ApplyDefaults(t)
+		}
+	}
+	role := r.shares.Role(user, t)
+	if !role.Allows(models.ProjectEditor) {
+		return fmt.Errorf("%w: cant move task %s to project %q", models.TaskPermissionError, t.UUID, t.Project)
+	}
+	if t.Status.IsRemoved() && (previous == nil || !previous.Status.IsRemoved()) && !role.Allows(models.ProjectOwner) {
+		return fmt.Errorf("%w: only owner can delete task %s", models.TaskPermissionError, t.UUID)
+	}
+	inBatch := slices.ContainsFunc(batch, func(task *models.Task) bool {
+		return t.Parent != nil && task.UUID == *t.Parent
+	})
+	if t.Parent != nil && !inBatch {
+		parent, err := r.Get(ctx, *t.Parent)
+		if err != nil {
+			return fmt.Errorf("cant get parent task: %w", err)
+		}
+		if parent == nil {
+			return fmt.Errorf("%w: task %s not found", models.TaskParentError, *t.Parent)
+		}
+	}
+	return nil
+}
+
+func (r *userScopedRepository) All(ctx context.Context) ([]*models.Task, error) {
+	user, err := r.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := r.db.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(tasks, func(task *models.Task) bool {
+		return !r.shares.Role(user, task).Allows(models.ProjectViewer)
+	}), nil
+}
+
+func (r *userScopedRepository) Find(ctx context.Context, filter *models.ListFilter, page models.Page) (*models.FindResult, error) {
+	user, err := r.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scoped := *filter
+	scoped.Scopes = r.shares.Scopes(user)
+	return r.db.Find(ctx, &scoped, page)
+}
+
+func (r *userScopedRepository) History(ctx context.Context, UUID uuid.UUID) ([]*models.TaskHistoryEntry, error) {
+	task, err := r.Get(ctx, UUID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return []*models.TaskHistoryEntry{}, nil
+	}
+	return r.db.History(ctx, UUID)
+}
+
+// Projects returns projects of tasks visible to the user.
+func (r *userScopedRepository) Projects(ctx context.Context) ([]*models.Project, error) {
+	user, err := r.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := r.db.Projects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(projects, func(project *models.Project) bool {
+		return !r.shares.Role(user, projectTask(project)).Allows(models.ProjectViewer)
+	}), nil
+}
+
+// SaveProject saves the project of the user or the shared project, only owners change projects.
+func (r *userScopedRepository) SaveProject(ctx context.Context, project *models.Project) error {
+	if err := r.checkProjectOwner(ctx, project); err != nil {
+		return err
+	}
+	return r.db.SaveProject(ctx, project)
+}
+
+func (r *userScopedRepository) DeleteProject(ctx context.Context, project *models.Project) error {
+	if err := r.checkProjectOwner(ctx, project); err != nil {
+		return err
+	}
+	return r.db.DeleteProject(ctx, project)
+}
+
+// Filters returns saved filters of the user, they are not shared.
+func (r *userScopedRepository) Filters(ctx context.Context) ([]*models.SavedFilter, error) {
+	user, err := r.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filters, err := r.db.Filters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(filters, func(filter *models.SavedFilter) bool {
+		return filter.Owner != user.Name
+	}), nil
+}
+
+func (r *userScopedRepository) SaveFilter(ctx context.Context, filter *models.SavedFilter) error {
+	user, err := r.user(ctx)
+	if err != nil {
+		return err
+	}
+	filter.Owner = user.Name
+	return r.db.SaveFilter(ctx, filter)
+}
+
+func (r *userScopedRepository) DeleteFilter(ctx context.Context, filter *models.SavedFilter) error {
+	user, err := r.user(ctx)
+	if err != nil {
+		return err
+	}
+	filter.Owner = user.Name
+	return r.db.DeleteFilter(ctx, filter)
+}
+
+// Rename renames only own tasks and projects of the user, shared projects are renamed by their owners.
+func (r *userScopedRepository) Rename(ctx context.Context, rename *models.Rename) (*models.RenamePreview, error) {
+	user, err := r.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scoped := *rename
+	scoped.Scopes = []models.TaskScope{{Owner: user.Name}}
+	return r.db.Rename(ctx, &scoped)
+}
+
+// checkProjectOwner sets the owner of the project the same way as the owner of new tasks of the project.
+func (r *userScopedRepository) checkProjectOwner(ctx context.Context, project *models.Project) error {
+	user, err := r.user(ctx)
+	if err != nil {
+		return err
+	}
+	project.Unify()
+	project.Owner = r.shares.OwnerOf(user, project.Name)
+	if !r.shares.Role(user, projectTask(project)).Allows(models.ProjectOwner) {
+		return fmt.Errorf("%w: only owner can change project %s", models.TaskPermissionError, project.Name)
+	}
+	return nil
+}
+
+// projectTask is a task of the project to check roles of the project.
+func projectTask(project *models.Project) *models.Task {
+	return &models.Task{Owner: project.Owner, Project: project.Name}
+}
@@ -0,0 +1,35 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/paragor/todo/pkg/models"
+	"path/filepath"
+	"testing"
+)
+
+func TestUserScopedInsertKeepsTasksOnError(t *testing.T) {
+	repo := NewUserScopedRepository(startInMemory(t, filepath.Join(t.TempDir(), "database.json")), models.ProjectShares{})
+	ctx := models.WithUser(context.Background(), &models.User{Name: "alice"})
+	task := models.NewTask()
+	task.Description = "new"
+	orphan := models.NewTask()
+	orphan.Description = "orphan"
+	missing := uuid.New()
+	orphan.Parent = &missing
+
+	if err := repo.Insert(ctx, task, orphan); !errors.Is(err, models.TaskParentError) {
+		t.Fatalf("task with missing parent should be rejected, have %v", err)
+	}
+	if task.Owner != "" || task.Revision != 0 {
+		t.Errorf("task of the caller should be kept on error, have owner %q, revision %d", task.Owner, task.Revision)
+	}
+
+	if err := repo.Insert(ctx, task); err != nil {
+		t.Fatalf("cant insert task: %s", err)
+	}
+	if task.Owner != "alice" || task.Revision != 1 {
+		t.Errorf("saved task should be copied back, have owner %q, revision %d", task.Owner, task.Revision)
+	}
+}
@@ -1,6 +1,7 @@
 package events
 
 import (
+	"context"
 	"github.com/google/uuid"
 	"github.com/paragor/todo/pkg/models"
 	"slices"
@@ -36,20 +37,72 @@ func NewSpyRepository(db models.Repository) *spyRepository {
 	return &spyRepository{db: db}
 }
 
-func (s *spyRepository) Get(UUID uuid.UUID) (*models.Task, error) {
-	return s.db.Get(UUID)
+func (s *spyRepository) Get(ctx context.Context, UUID uuid.UUID) (*models.Task, error) {
+	return s.db.Get(ctx, UUID)
 }
 
-func (s *spyRepository) Insert(t *models.Task) error {
-	err := s.db.Insert(t)
+func (s *spyRepository) Insert(ctx context.Context, tasks ...*models.Task) error {
+	err := s.db.Insert(ctx, tasks...)
 	if err == nil {
-		for _, subscriber := range onDatabaseChangeSubscribers {
-			subscriber.OnDatabaseChange()
-		}
+		s.notify()
 	}
 	return err
 }
 
-func (s *spyRepository) All() ([]*models.Task, error) {
-	return s.db.All()
+func (s *spyRepository) notify() {
+	for _, subscriber := range onDatabaseChangeSubscribers {
+		subscriber.OnDatabaseChange()
+	}
+}
+
+func (s *spyRepository) All(ctx context.Context) ([]*models.Task, error) {
+	return s.db.All(ctx)
+}
+
+func (s *spyRepository) History(ctx context.Context, UUID uuid.UUID) ([]*models.TaskHistoryEntry, error) {
+	return s.db.History(ctx, UUID)
+}
+
+func (s *spyRepository) Find(ctx context.Context, filter *models.ListFilter, page models.Page) (*models.FindResult, error) {
+	return s.db.Find(ctx, filter, page)
+}
+
+func (s *spyRepository) Projects(ctx context.Context) ([]*models.Project, error) {
+	return s.db.Projects(ctx)
+}
+
+func (s *spyRepository) SaveProject(ctx context.Context, project *models.Project) error {
+	err := s.db.SaveProject(ctx, project)
+	if err == nil {
+		s.notify()
+	}
+	return err
+}
+
+func (s *spyRepository) DeleteProject(ctx context.Context, project *models.Project) error {
+	err := s.db.DeleteProject(ctx, project)
+	if err == nil {
+		s.notify()
+	}
+	return err
+}
+
+func (s *spyRepository) Filters(ctx context.Context) ([]*models.SavedFilter, error) {
+	return s.db.Filters(ctx)
+}
+
+func (s *spyRepository) SaveFilter(ctx context.Context, filter *models.SavedFilter) error {
+	return s.db.SaveFilter(ctx, filter)
+}
+
+func (s *spyRepository) DeleteFilter(ctx context.Context, filter *models.SavedFilter) error {
+	return s.db.DeleteFilter(ctx, filter)
+}
+
+func (s *spyRepository) Rename(ctx context.Context, rename *models.Rename) (*models.RenamePreview, error) {
+	result, err := s.db.Rename(ctx, rename)
+	if err == nil && !rename.DryRun {
+		s.notify()
+	}
+	return result, err
 }
@@ -2,6 +2,7 @@ package httpserver
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/paragor/todo/pkg/models"
 	"io"
@@ -28,15 +29,72 @@ func (h *httpServer) apiInsertTask(writer http.ResponseWriter, request *http.Req
 		http.Error(writer, "invalid task: "+err.Error(), 400)
 		return
 	}
-	if err := h.repository.Insert(task); err != nil {
-		http.Error(writer, "cant insert task: "+err.Error(), 500)
+	task.ModifiedBy = actorFromRequest(request)
+	if err := h.repository.Insert(request.Context(), task); err != nil {
+		apiInsertError(writer, "cant insert task: ", err)
 		return
 	}
+	response, err := json.Marshal(task)
+	if err != nil {
+		http.Error(writer, "cant marshal task: "+err.Error(), 500)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(200)
+	_, _ = writer.Write(response)
+}
+
+// apiInsertTasks saves tasks at once, see models.Repository.Insert.
+func (h *httpServer) apiInsertTasks(writer http.ResponseWriter, request *http.Request) {
+	defer request.Body.Close()
+	data, err := io.ReadAll(request.Body)
+	if err != nil {
+		http.Error(writer, "cant read request body: "+err.Error(), 400)
+		return
+	}
+	tasks := []*models.Task{}
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		http.Error(writer, "cant unmarshal tasks: "+err.Error(), 400)
+		return
+	}
+	actor := actorFromRequest(request)
+	for _, task := range tasks {
+		if err := task.Validate(); err != nil {
+			http.Error(writer, "invalid task: "+err.Error(), 400)
+			return
+		}
+		task.ModifiedBy = actor
+	}
+	if err := h.repository.Insert(request.Context(), tasks...); err != nil {
+		apiInsertError(writer, "cant insert tasks: ", err)
+		return
+	}
+	response, err := json.Marshal(tasks)
+	if err != nil {
+		http.Error(writer, "cant marshal tasks: "+err.Error(), 500)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
 	writer.WriteHeader(200)
+	_, _ = writer.Write(response)
+}
+
+// apiInsertError writes a revision conflict as json, so the remote repository knows the conflicting task.
+func apiInsertError(writer http.ResponseWriter, message string, err error) {
+	conflict := &models.TaskRevisionConflictError{}
+	if errors.As(err, &conflict) {
+		if response, err := json.Marshal(conflict); err == nil {
+			writer.Header().Set("Content-Type", "application/json")
+			writer.WriteHeader(http.StatusConflict)
+			_, _ = writer.Write(response)
+			return
+		}
+	}
+	http.Error(writer, message+err.Error(), insertErrorStatus(err))
 }
 
 func (h *httpServer) apiAllTask(writer http.ResponseWriter, request *http.Request) {
-	tasks, err := h.repository.All()
+	tasks, err := h.repository.All(request.Context())
 	if err != nil {
 		http.Error(writer, "cant get tasks: "+err.Error(), 500)
 		return
@@ -50,6 +108,23 @@ func (h *httpServer) apiAllTask(writer http.ResponseWriter, request *http.Reques
 	writer.WriteHeader(200)
 	_, _ = writer.Write(response)
 }
+func (h *httpServer) apiFindTasks(writer http.ResponseWriter, request *http.Request) {
+	_ = request.ParseForm()
+	result, err := h.repository.Find(request.Context(), models.QueryToListFilter(request.Form), models.QueryToPage(request.Form, models.Page{}))
+	if err != nil {
+		http.Error(writer, "cant find tasks: "+err.Error(), 500)
+		return
+	}
+	response, err := json.Marshal(result)
+	if err != nil {
+		http.Error(writer, "cant marshal tasks: "+err.Error(), 500)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(200)
+	_, _ = writer.Write(response)
+}
+
 func (h *httpServer) apiGetTask(writer http.ResponseWriter, request *http.Request) {
 	_ = request.ParseForm()
 	UUID := request.Form.Get("uuid")
@@ -62,7 +137,7 @@ func (h *httpServer) apiGetTask(writer http.ResponseWriter, request *http.Reques
 		http.Error(writer, "cant parse UUID: %s"+err.Error(), 400)
 		return
 	}
-	task, err := h.repository.Get(parsedUUID)
+	task, err := h.repository.Get(request.Context(), parsedUUID)
 	if err != nil {
 		http.Error(writer, "cant get task: "+err.Error(), 500)
 		return
@@ -80,3 +155,190 @@ func (h *httpServer) apiGetTask(writer http.ResponseWriter, request *http.Reques
 	writer.WriteHeader(200)
 	_, _ = writer.Write(response)
 }
+
+func (h *httpServer) apiTaskHistory(writer http.ResponseWriter, request *http.Request) {
+	_ = request.ParseForm()
+	UUID := request.Form.Get("uuid")
+	if len(UUID) == 0 {
+		http.Error(writer, "uuid cant not be empty", 400)
+		return
+	}
+	parsedUUID, err := uuid.Parse(UUID)
+	if err != nil {
+		http.Error(writer, "cant parse UUID: "+err.Error(), 400)
+		return
+	}
+	history, err := h.repository.History(request.Context(), parsedUUID)
+	if err != nil {
+		http.Error(writer, "cant get task history: "+err.Error(), 500)
+		return
+	}
+	response, err := json.Marshal(history)
+	if err != nil {
+		http.Error(writer, "cant marshal task history: "+err.Error(), 500)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(200)
+	_, _ = writer.Write(response)
+}
+
+func (h *httpServer) apiProjects(writer http.ResponseWriter, request *http.Request) {
+	projects, err := h.repository.Projects(request.Context())
+	if err != nil {
+		http.Error(writer, "cant get projects: "+err.Error(), 500)
+		return
+	}
+	response, err := json.Marshal(projects)
+	if err != nil {
+		http.Error(writer, "cant marshal projects: "+err.Error(), 500)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(200)
+	_, _ = writer.Write(response)
+}
+
+func (h *httpServer) apiSaveProject(writer http.ResponseWriter, request *http.Request) {
+	project, ok := apiReadProject(writer, request)
+	if !ok {
+		return
+	}
+	if err := project.Validate(); err != nil {
+		http.Error(writer, "invalid project: "+err.Error(), 400)
+		return
+	}
+	if err := h.repository.SaveProject(request.Context(), project); err != nil {
+		http.Error(writer, "cant save project: "+err.Error(), projectErrorStatus(err))
+		return
+	}
+	writer.WriteHeader(200)
+}
+
+func (h *httpServer) apiDeleteProject(writer http.ResponseWriter, request *http.Request) {
+	project, ok := apiReadProject(writer, request)
+	if !ok {
+		return
+	}
+	if err := h.repository.DeleteProject(request.Context(), project); err != nil {
+		http.Error(writer, "cant delete project: "+err.Error(), projectErrorStatus(err))
+		return
+	}
+	writer.WriteHeader(200)
+}
+
+func (h *httpServer) apiFilters(writer http.ResponseWriter, request *http.Request) {
+	filters, err := h.repository.Filters(request.Context())
+	if err != nil {
+		http.Error(writer, "cant get filters: "+err.Error(), 500)
+		return
+	}
+	response, err := json.Marshal(filters)
+	if err != nil {
+		http.Error(writer, "cant marshal filters: "+err.Error(), 500)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(200)
+	_, _ = writer.Write(response)
+}
+
+func (h *httpServer) apiSaveFilter(writer http.ResponseWriter, request *http.Request) {
+	filter, ok := apiReadFilter(writer, request)
+	if !ok {
+		return
+	}
+	if err := filter.Validate(); err != nil {
+		http.Error(writer, "invalid filter: "+err.Error(), 400)
+		return
+	}
+	if err := h.repository.SaveFilter(request.Context(), filter); err != nil {
+		http.Error(writer, "cant save filter: "+err.Error(), 500)
+		return
+	}
+	writer.WriteHeader(200)
+}
+
+func (h *httpServer) apiDeleteFilter(writer http.ResponseWriter, request *http.Request) {
+	filter, ok := apiReadFilter(writer, request)
+	if !ok {
+		return
+	}
+	if err := h.repository.DeleteFilter(request.Context(), filter); err != nil {
+		http.Error(writer, "cant delete filter: "+err.Error(), 500)
+		return
+	}
+	writer.WriteHeader(200)
+}
+
+func apiReadFilter(writer http.ResponseWriter, request *http.Request) (*models.SavedFilter, bool) {
+	defer request.Body.Close()
+	data, err := io.ReadAll(request.Body)
+	if err != nil {
+		http.Error(writer, "cant read request body: "+err.Error(), 400)
+		return nil, false
+	}
+	filter := &models.SavedFilter{}
+	if err := json.Unmarshal(data, filter); err != nil {
+		http.Error(writer, "cant unmarshal filter: "+err.Error(), 400)
+		return nil, false
+	}
+	filter.Unify()
+	return filter, true
+}
+
+func (h *httpServer) apiRename(writer http.ResponseWriter, request *http.Request) {
+	defer request.Body.Close()
+	data, err := io.ReadAll(request.Body)
+	if err != nil {
+		http.Error(writer, "cant read request body: "+err.Error(), 400)
+		return
+	}
+	rename := &models.Rename{}
+	if err := json.Unmarshal(data, rename); err != nil {
+		http.Error(writer, "cant unmarshal rename: "+err.Error(), 400)
+		return
+	}
+	rename.Unify()
+	if err := rename.Validate(); err != nil {
+		http.Error(writer, "invalid rename: "+err.Error(), 400)
+		return
+	}
+	rename.ModifiedBy = actorFromRequest(request)
+	result, err := h.repository.Rename(request.Context(), rename)
+	if err != nil {
+		http.Error(writer, "cant rename: "+err.Error(), 500)
+		return
+	}
+	response, err := json.Marshal(result)
+	if err != nil {
+		http.Error(writer, "cant marshal rename result: "+err.Error(), 500)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(200)
+	_, _ = writer.Write(response)
+}
+
+func apiReadProject(writer http.ResponseWriter, request *http.Request) (*models.Project, bool) {
+	defer request.Body.Close()
+	data, err := io.ReadAll(request.Body)
+	if err != nil {
+		http.Error(writer, "cant read request body: "+err.Error(), 400)
+		return nil, false
+	}
+	project := &models.Project{}
+	if err := json.Unmarshal(data, project); err != nil {
+		http.Error(writer, "cant unmarshal project: "+err.Error(), 400)
+		return nil, false
+	}
+	project.Unify()
+	return project, true
+}
+
+func projectErrorStatus(err error) int {
+	if errors.Is(err, models.TaskPermissionError) {
+		return http.StatusForbidden
+	}
+	return 500
+}
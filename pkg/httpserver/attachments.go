@@ -0,0 +1,93 @@
+package httpserver
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/paragor/todo/pkg/models"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// inlineContentTypes are shown by the browser, other attachments are downloaded,
+// so uploaded html cant run scripts on the todolist origin.
+var inlineContentTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf", "text/plain"}
+
+func (h *httpServer) getAttachment(writer http.ResponseWriter, request *http.Request) {
+	vars := mux.Vars(request)
+	taskUUID, err := uuid.Parse(vars["task"])
+	if err != nil {
+		http.Error(writer, "cant parse task UUID: "+err.Error(), 400)
+		return
+	}
+	attachmentUUID, err := uuid.Parse(vars["attachment"])
+	if err != nil {
+		http.Error(writer, "cant parse attachment UUID: "+err.Error(), 400)
+		return
+	}
+	task, err := h.repository.Get(request.Context(), taskUUID)
+	if err != nil {
+		http.Error(writer, "cant fetch task: "+err.Error(), 500)
+		return
+	}
+	if task == nil {
+		http.Error(writer, "task not found", 404)
+		return
+	}
+	attachment := task.FindAttachment(attachmentUUID)
+	if attachment == nil {
+		http.Error(writer, "attachment not found", 404)
+		return
+	}
+	content, err := h.attachments.LoadAttachment(request.Context(), attachment.UUID)
+	if err != nil {
+		http.Error(writer, "cant load attachment: "+err.Error(), 500)
+		return
+	}
+	if content == nil {
+		http.Error(writer, "attachment content not found", 404)
+		return
+	}
+	disposition := "attachment"
+	mediaType, _, _ := mime.ParseMediaType(attachment.ContentType)
+	for _, inline := range inlineContentTypes {
+		if mediaType == inline {
+			disposition = "inline"
+		}
+	}
+	writer.Header().Set("Content-Type", attachment.ContentType)
+	writer.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Name}))
+	writer.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	writer.Header().Set("X-Content-Type-Options", "nosniff")
+	writer.Header().Set("Cache-Control", "private, max-age=86400")
+	writer.WriteHeader(200)
+	_, _ = writer.Write(content)
+}
+
+func (h *httpServer) attachFormFile(request *http.Request, task *models.Task, header *multipart.FileHeader) error {
+	if header.Size > models.MaxAttachmentSize {
+		return fmt.Errorf("%w: %s is larger than %d MB", models.TaskAttachmentError, header.Filename, models.MaxAttachmentSize>>20)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return fmt.Errorf("cant open %s: %w", header.Filename, err)
+	}
+	defer file.Close()
+	content, err := io.ReadAll(io.LimitReader(file, models.MaxAttachmentSize+1))
+	if err != nil {
+		return fmt.Errorf("cant read %s: %w", header.Filename, err)
+	}
+	contentType := strings.TrimSpace(header.Header.Get("Content-Type"))
+	if contentType == "application/octet-stream" {
+		contentType = ""
+	}
+	if _, err := models.Attach(request.Context(), h.attachments, task, header.Filename, contentType, content, time.Now()); err != nil {
+		return err
+	}
+	return nil
+}
@@ -1,17 +1,19 @@
 package httpserver
 
 import (
+	"github.com/paragor/todo/pkg/models"
 	"net/http"
 )
 
-type AuthBaseConfig struct {
-	Login    string
-	Password string
-}
+// AuthBaseConfig enables basic auth with logins and passwords of AuthChainConfig.Users.
+type AuthBaseConfig struct{}
 
-func isAuthorizedByBaseAuth(request *http.Request, cfg AuthBaseConfig) bool {
+func isAuthorizedByBaseAuth(request *http.Request, users models.Users) (*models.User, string) {
 	inUser, inPassword, ok := request.BasicAuth()
-	return ok && inUser == cfg.Login && inPassword == cfg.Password
+	if !ok {
+		return nil, ""
+	}
+	return users.ByLogin(inUser, inPassword), "base:" + inUser
 }
 func isRequireForceBaseAuth(request *http.Request) bool {
 	cookie, err := request.Cookie("base_auth_challenge")
@@ -1,7 +1,9 @@
 package httpserver
 
 import (
+	"context"
 	"github.com/gorilla/mux"
+	"github.com/paragor/todo/pkg/models"
 	"html/template"
 	"net/http"
 )
@@ -11,22 +13,47 @@ type AuthChainConfig struct {
 	*AuthTelegramConfig
 	*AuthTokenConfig
 	*AuthOidcConfig
+	// Users are accounts of the deployment, every auth method resolves the request to one of them.
+	Users models.Users
+}
+
+type actorContextKey struct{}
+
+// withUser places the user in the context of the request, repository queries are scoped to the user.
+func withUser(request *http.Request, user *models.User, actor string) *http.Request {
+	ctx := models.WithUser(request.Context(), user)
+	return request.WithContext(context.WithValue(ctx, actorContextKey{}, actor))
+}
+
+func actorFromRequest(request *http.Request) string {
+	actor, _ := request.Context().Value(actorContextKey{}).(string)
+	return actor
 }
 
 func (h *httpServer) AuthChainMiddleware() mux.MiddlewareFunc {
 	return func(handler http.Handler) http.Handler {
 		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-			if h.authConfig.AuthTelegramConfig != nil && isAuthorizedByTelegram(request, *h.authConfig.AuthTelegramConfig) {
-				handler.ServeHTTP(writer, request)
+			if h.authConfig == nil {
+				// without auth everything belongs to the default user
+				handler.ServeHTTP(writer, withUser(request, &models.User{}, ""))
 				return
 			}
-			if h.authConfig.AuthTokenConfig != nil && isAuthorizedByToken(request, *h.authConfig.AuthTokenConfig) {
-				handler.ServeHTTP(writer, request)
-				return
+			users := h.authConfig.Users
+			if h.authConfig.AuthTelegramConfig != nil {
+				if user, actor := isAuthorizedByTelegram(request, *h.authConfig.AuthTelegramConfig, users); user != nil {
+					handler.ServeHTTP(writer, withUser(request, user, actor))
+					return
+				}
+			}
+			if h.authConfig.AuthTokenConfig != nil {
+				if user, actor := isAuthorizedByToken(request, users); user != nil {
+					handler.ServeHTTP(writer, withUser(request, user, actor))
+					return
+				}
 			}
 			if h.authConfig.AuthBaseConfig != nil {
-				if isAuthorizedByBaseAuth(request, *h.authConfig.AuthBaseConfig) {
-					handler.ServeHTTP(writer, request)
+				if user, actor := isAuthorizedByBaseAuth(request, users); user != nil {
+					handler.ServeHTTP(writer, withUser(request, user, actor))
 					return
 				}
 				if isRequireForceBaseAuth(request) {
@@ -35,9 +62,11 @@ func (h *httpServer) AuthChainMiddleware() mux.MiddlewareFunc {
 					return
 				}
 			}
-			if h.oidc != nil && h.oidc.isAuthorizedByOidc(request) {
-				handler.ServeHTTP(writer, request)
-				return
+			if h.oidc != nil {
+				if user, actor := h.oidc.isAuthorizedByOidc(request); user != nil {
+					handler.ServeHTTP(writer, withUser(request, user, actor))
+					return
+				}
 			}
 			h.htmxPageLogin(writer, request)
 		})
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/paragor/todo/pkg/models"
 	"github.com/zitadel/oidc/v3/pkg/client/rp"
 	httphelper "github.com/zitadel/oidc/v3/pkg/http"
 	"github.com/zitadel/oidc/v3/pkg/oidc"
@@ -14,22 +15,22 @@ import (
 const oidcCookie = "oidc_cookie"
 
 type AuthOidcConfig struct {
-	ClientId        string
-	ClientSecret    string
-	IssuerUrl       string
-	CookieKey       string
-	Scopes          []string
-	WhitelistEmails []string
+	ClientId     string
+	ClientSecret string
+	IssuerUrl    string
+	CookieKey    string
+	Scopes       []string
 }
 
 type authOidcContext struct {
 	cfg                    AuthOidcConfig
+	users                  models.Users
 	provider               rp.RelyingParty
 	successfulRedirectPath string
 	idTokenCookieName      string
 }
 
-func newOidcContext(cfg AuthOidcConfig, callbackUrl string, successfulRedirectPath string) (*authOidcContext, error) {
+func newOidcContext(cfg AuthOidcConfig, users models.Users, callbackUrl string, successfulRedirectPath string) (*authOidcContext, error) {
 	cookieHandler := httphelper.NewCookieHandler([]byte(cfg.CookieKey), []byte(cfg.CookieKey))
 	options := []rp.Option{
 		rp.WithCookieHandler(cookieHandler),
@@ -43,38 +44,35 @@ func newOidcContext(cfg AuthOidcConfig, callbackUrl string, successfulRedirectPa
 	}
 	return &authOidcContext{
 		cfg:                    cfg,
+		users:                  users,
 		provider:               provider,
 		idTokenCookieName:      "oidc_id_token",
 		successfulRedirectPath: successfulRedirectPath,
 	}, nil
 }
 
-func (oc *authOidcContext) isAuthorizedByOidc(request *http.Request) bool {
+func (oc *authOidcContext) isAuthorizedByOidc(request *http.Request) (*models.User, string) {
 	idToken, err := oc.provider.CookieHandler().CheckCookie(request, oc.idTokenCookieName)
 	if err != nil || idToken == "" {
-		return false
+		return nil, ""
 	}
 	claim, err := rp.VerifyIDToken[*oidc.IDTokenClaims](request.Context(), idToken, oc.provider.IDTokenVerifier())
 	if err != nil {
-		return false
+		return nil, ""
 	}
-	return oc.isValidEmail(claim.UserInfoEmail)
+	return oc.userByEmail(claim.UserInfoEmail), "oidc:" + claim.UserInfoEmail.Email
 }
 
-func (oc *authOidcContext) isValidEmail(info oidc.UserInfoEmail) bool {
-	for _, validEmail := range oc.cfg.WhitelistEmails {
-		if validEmail == info.Email && info.EmailVerified {
-			return true
-		}
+func (oc *authOidcContext) userByEmail(info oidc.UserInfoEmail) *models.User {
+	if !info.EmailVerified {
+		return nil
 	}
-	return false
+	return oc.users.ByEmail(info.Email)
 }
 func (oc *authOidcContext) userInfoCallback(w http.ResponseWriter, r *http.Request, tokens *oidc.Tokens[*oidc.IDTokenClaims], state string, rp rp.RelyingParty, info *oidc.UserInfo) {
-	if len(oc.cfg.WhitelistEmails) > 0 {
-		if !oc.isValidEmail(info.UserInfoEmail) {
-			http.Error(w, "email is blocked", http.StatusUnauthorized)
-			return
-		}
+	if oc.userByEmail(info.UserInfoEmail) == nil {
+		http.Error(w, "email is blocked", http.StatusUnauthorized)
+		return
 	}
 	if err := oc.provider.CookieHandler().SetCookie(w, oc.idTokenCookieName, tokens.IDToken); err != nil {
 		http.Error(w, "cant set cookie: "+err.Error(), http.StatusInternalServerError)
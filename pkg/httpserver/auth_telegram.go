@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"github.com/paragor/todo/pkg/models"
 	"net/http"
 	"net/url"
 	"sort"
@@ -12,25 +13,25 @@ import (
 	"strings"
 )
 
+// AuthTelegramConfig enables auth of the telegram web app for telegram ids of AuthChainConfig.Users.
 type AuthTelegramConfig struct {
-	Token     string
-	TrustedId int64
+	Token string
 }
 
-func isAuthorizedByTelegram(request *http.Request, cfg AuthTelegramConfig) bool {
+func isAuthorizedByTelegram(request *http.Request, cfg AuthTelegramConfig, users models.Users) (*models.User, string) {
 	cookie, err := request.Cookie("telegram_data")
 	if err != nil {
-		return false
+		return nil, ""
 	}
 	telegramData := cookie.Value
 	if len(telegramData) == 0 {
-		return false
+		return nil, ""
 	}
 	requestTelegramUserData, valid := authTelegram(cfg.Token, telegramData)
-	if !valid || cfg.TrustedId != requestTelegramUserData.Id {
-		return false
+	if !valid {
+		return nil, ""
 	}
-	return true
+	return users.ByTelegramId(requestTelegramUserData.Id), "telegram:" + strconv.FormatInt(requestTelegramUserData.Id, 10)
 }
 
 type telegramUserData struct {
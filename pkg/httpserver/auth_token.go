@@ -1,13 +1,13 @@
 package httpserver
 
 import (
+	"github.com/paragor/todo/pkg/models"
 	"net/http"
 )
 
-type AuthTokenConfig struct {
-	Token string
-}
+// AuthTokenConfig enables api auth with client tokens of AuthChainConfig.Users.
+type AuthTokenConfig struct{}
 
-func isAuthorizedByToken(request *http.Request, cfg AuthTokenConfig) bool {
-	return request.Header.Get("Authorization") == cfg.Token
+func isAuthorizedByToken(request *http.Request, users models.Users) (*models.User, string) {
+	return users.ByToken(request.Header.Get("Authorization")), "token"
 }
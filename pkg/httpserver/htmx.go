@@ -2,6 +2,8 @@ package httpserver
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/paragor/todo/pkg/httpserver/htmxtemplates"
@@ -9,7 +11,10 @@ import (
 	"github.com/paragor/todo/pkg/templatesutils"
 	"html/template"
 	"net/http"
+	"net/url"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -17,7 +22,10 @@ import (
 var templates *template.Template
 
 func init() {
-	templates = template.New("").Funcs(templatesutils.GetFunctions())
+	templates = template.New("").Funcs(templatesutils.GetFunctions()).Funcs(template.FuncMap{
+		"task_templates": models.GetTaskTemplates,
+		"task_statuses":  models.GetTaskStatuses,
+	})
 	templates = must(templates.ParseFS(htmxtemplates.Components, "components/*.html"))
 	templates = must(templates.ParseFS(htmxtemplates.Pages, "pages/*.html"))
 }
@@ -49,65 +57,168 @@ func writeHtmx(writer http.ResponseWriter, template string, data any, status int
 }
 
 type filterContext struct {
-	Enabled     bool
+	Enabled bool
+	// Context is the applied saved filter, see htmxApplyContext.
+	Context     *models.SavedFilter
 	Filter      *models.ListFilter
+	Order       models.TaskOrder
 	AllProjects map[string]int
 	AllTags     map[string]int
 }
 
+type paginationContext struct {
+	From    int
+	To      int
+	Total   int
+	PrevUrl string
+	NextUrl string
+}
+
 type listContext struct {
 	Tasks         []*models.Task
 	FilterContext filterContext
+	Pagination    *paginationContext
 }
 type groupedListComponentContext struct {
 	ExpandAll     bool
-	GroupedTasks  []models.TaskGroup
+	GroupedTasks  []taskTreeGroup
 	FilterContext filterContext
 }
 
+// taskTreeGroup shows subtasks nested into their parents, if they are in the same group.
+type taskTreeGroup struct {
+	Group string
+	Tasks []*models.Task
+	Trees []*models.TaskTree
+	// Collaborators are users of the shared project, empty for other groups.
+	Collaborators []models.ProjectMember
+	// Project is the stored metadata of the project group, nil if it is not set.
+	Project *models.Project
+	// SettingsUrl is the project settings page of the project group, empty for other groups.
+	SettingsUrl string
+}
+
+func newTaskTreeGroups(groups []models.TaskGroup) []taskTreeGroup {
+	result := []taskTreeGroup{}
+	for _, group := range groups {
+		result = append(result, taskTreeGroup{
+			Group: group.Group,
+			Tasks: group.Tasks,
+			Trees: models.BuildTaskTrees(group.Tasks),
+		})
+	}
+	return result
+}
+
 func (c *listContext) groupByProjects() *groupedListComponentContext {
 	return &groupedListComponentContext{
 		FilterContext: c.FilterContext,
-		GroupedTasks:  models.GroupTasksByProject(c.Tasks),
+		GroupedTasks:  newTaskTreeGroups(models.GroupTasksByProject(c.Tasks)),
 	}
 }
 func (c *listContext) agenda() *groupedListComponentContext {
 	result := &groupedListComponentContext{
 		FilterContext: c.FilterContext,
-		GroupedTasks:  models.Agenda(c.Tasks),
+		GroupedTasks:  newTaskTreeGroups(models.Agenda(c.Tasks)),
 	}
 	result.ExpandAll = true
 	result.FilterContext.Enabled = false
 	return result
 }
 
-func (h *httpServer) htmxGenerateListContext(request *http.Request) (*listContext, error) {
+const htmxListPageSize = 100
+
+func (h *httpServer) htmxGenerateListContext(request *http.Request, defaultPage models.Page) (*listContext, error) {
 	_ = request.ParseForm()
-	tasks, err := h.repository.All()
+	filter := models.QueryToListFilter(request.Form)
+	page := models.QueryToPage(request.Form, defaultPage)
+	result, err := h.repository.Find(request.Context(), filter, page)
 	if err != nil {
 		return nil, fmt.Errorf("cant list tasks: %w", err)
 	}
-	filter := queryToListFilter(request.Form)
-	tasks = filter.Apply(tasks)
-	uniqProjects := models.UniqProjects(tasks)
-	uniqTags := models.UniqTags(tasks)
-	models.SortTasks(tasks)
-	return &listContext{
+	tasks := result.Tasks
+	if err := models.ResolveBlockers(request.Context(), h.repository, tasks...); err != nil {
+		return nil, fmt.Errorf("cant resolve dependencies: %w", err)
+	}
+	// projects and tags of the filter form are counted over all pages
+	facetTasks := tasks
+	if len(tasks) < result.Total {
+		allTasks, err := h.repository.Find(request.Context(), filter, models.Page{})
+		if err != nil {
+			return nil, fmt.Errorf("cant list tasks: %w", err)
+		}
+		facetTasks = allTasks.Tasks
+	}
+	uniqProjects := models.UniqProjects(facetTasks)
+	uniqTags := models.UniqTags(facetTasks)
+	context := &listContext{
 		Tasks: tasks,
 		FilterContext: filterContext{
 			Enabled:     true,
 			Filter:      filter,
+			Order:       page.Order,
 			AllProjects: uniqProjects,
 			AllTags:     uniqTags,
 		},
-	}, nil
+	}
+	if page.Limit > 0 && result.Total > page.Limit {
+		context.Pagination = &paginationContext{
+			From:  page.Offset + 1,
+			To:    page.Offset + len(tasks),
+			Total: result.Total,
+		}
+		if page.Offset > 0 {
+			prev := models.Page{Offset: max(page.Offset-page.Limit, 0), Limit: page.Limit, Order: page.Order}
+			context.Pagination.PrevUrl = "?" + models.PageToQuery(models.ListFilterToQuery(filter), prev).Encode()
+		}
+		if page.Offset+page.Limit < result.Total {
+			next := models.Page{Offset: page.Offset + page.Limit, Limit: page.Limit, Order: page.Order}
+			context.Pagination.NextUrl = "?" + models.PageToQuery(models.ListFilterToQuery(filter), next).Encode()
+		}
+	}
+	return context, nil
 }
+
+// htmxApplyContext sets filter params of the saved filter from the context param, or of the active saved filter
+// if the request has no filter params. The context param none disables the active saved filter.
+func (h *httpServer) htmxApplyContext(request *http.Request) (*models.SavedFilter, error) {
+	_ = request.ParseForm()
+	options := &models.HumanInputOptions{Context: strings.ToLower(request.Form.Get("context"))}
+	if len(options.Context) == 0 && models.HasListFilterQuery(request.Form) {
+		return nil, nil
+	}
+	filters, err := h.repository.Filters(request.Context())
+	if err != nil {
+		return nil, fmt.Errorf("cant get filters: %w", err)
+	}
+	saved, err := options.ContextSavedFilter(filters)
+	if err != nil || saved == nil {
+		return nil, err
+	}
+	filter, err := options.ContextListFilter(filters)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range models.ListFilterToQuery(filter) {
+		if !request.Form.Has(key) {
+			request.Form[key] = values
+		}
+	}
+	return saved, nil
+}
+
 func (h *httpServer) htmxPageMain(writer http.ResponseWriter, request *http.Request) {
-	context, err := h.htmxGenerateListContext(request)
+	saved, err := h.htmxApplyContext(request)
+	if err != nil {
+		http.Error(writer, "cant apply context: "+err.Error(), 400)
+		return
+	}
+	context, err := h.htmxGenerateListContext(request, models.Page{Limit: htmxListPageSize, Order: models.OrderUrgency})
 	if err != nil {
 		http.Error(writer, "cant generate context: "+err.Error(), 500)
 		return
 	}
+	context.FilterContext.Context = saved
 
 	tasksHtml, deferFn, err := renderHtmx("component/list_tasks", context)
 	defer deferFn()
@@ -129,7 +240,7 @@ func (h *httpServer) htmxPageTask(writer http.ResponseWriter, request *http.Requ
 		http.Error(writer, "cant parse UUID: %s"+err.Error(), 400)
 		return
 	}
-	task, err := h.repository.Get(parsedUUID)
+	task, err := h.repository.Get(request.Context(), parsedUUID)
 	if err != nil {
 		http.Error(writer, "cant fetch task: "+err.Error(), 500)
 		return
@@ -139,6 +250,10 @@ func (h *httpServer) htmxPageTask(writer http.ResponseWriter, request *http.Requ
 		http.Error(writer, "task not found", 400)
 		return
 	}
+	if err := models.ResolveBlockers(request.Context(), h.repository, task); err != nil {
+		http.Error(writer, "cant resolve dependencies: "+err.Error(), 500)
+		return
+	}
 
 	tasksHtml, deferFn, err := renderHtmx("component/task_card", task)
 	defer deferFn()
@@ -150,26 +265,58 @@ func (h *httpServer) htmxPageTask(writer http.ResponseWriter, request *http.Requ
 }
 
 func (h *httpServer) htmxPageProjects(writer http.ResponseWriter, request *http.Request) {
-	context, err := h.htmxGenerateListContext(request)
+	context, err := h.htmxGenerateListContext(request, models.Page{})
 	if err != nil {
 		http.Error(writer, "cant generate context: "+err.Error(), 500)
 		return
 	}
 
-	tasksHtml, deferFn, err := renderHtmx("component/list_tasks_by_groups", context.groupByProjects())
+	projects, err := h.repository.Projects(request.Context())
+	if err != nil {
+		http.Error(writer, "cant get projects: "+err.Error(), 500)
+		return
+	}
+	grouped := context.groupByProjects()
+	user := models.UserFromContext(request.Context())
+	for i := range grouped.GroupedTasks {
+		group := &grouped.GroupedTasks[i]
+		if group.Group == models.ProjectSelectorEmpty {
+			continue
+		}
+		group.SettingsUrl = "/project?" + url.Values{"name": {group.Group}}.Encode()
+		owner := ""
+		if user != nil {
+			group.Collaborators = h.shares.Collaborators(user, group.Group)
+			owner = h.shares.OwnerOf(user, group.Group)
+		}
+		group.Project = models.FindProject(projects, owner, group.Group)
+	}
+	renameHtml, deferRenameFn, err := renderHtmx("component/rename_form", context.FilterContext)
+	defer deferRenameFn()
+	if err != nil {
+		http.Error(writer, "error on render", 500)
+		return
+	}
+	tasksHtml, deferFn, err := renderHtmx("component/list_tasks_by_groups", grouped)
 	defer deferFn()
 	if err != nil {
 		http.Error(writer, "error on render", 500)
 		return
 	}
-	writeHtmx(writer, "page/index", template.HTML(tasksHtml.String()), 200)
+	writeHtmx(writer, "page/index", template.HTML(renameHtml.String()+tasksHtml.String()), 200)
 }
 func (h *httpServer) htmxPageAgenda(writer http.ResponseWriter, request *http.Request) {
-	context, err := h.htmxGenerateListContext(request)
+	saved, err := h.htmxApplyContext(request)
+	if err != nil {
+		http.Error(writer, "cant apply context: "+err.Error(), 400)
+		return
+	}
+	context, err := h.htmxGenerateListContext(request, models.Page{})
 	if err != nil {
 		http.Error(writer, "cant generate context: "+err.Error(), 500)
 		return
 	}
+	context.FilterContext.Context = saved
 
 	tasksHtml, deferFn, err := renderHtmx("component/list_tasks_by_groups", context.agenda())
 	defer deferFn()
@@ -180,6 +327,248 @@ func (h *httpServer) htmxPageAgenda(writer http.ResponseWriter, request *http.Re
 	writeHtmx(writer, "page/index", template.HTML(tasksHtml.String()), 200)
 }
 
+type projectSettingsContext struct {
+	Project *models.Project
+	// Stored is false for projects without metadata.
+	Stored        bool
+	Collaborators []models.ProjectMember
+}
+
+// htmxFindProject returns stored metadata of the project or a new project, the owner is resolved
+// the same way as for new tasks of the project.
+func (h *httpServer) htmxFindProject(request *http.Request) (*models.Project, bool, error) {
+	name := strings.TrimSpace(strings.ToLower(request.Form.Get("name")))
+	if len(name) == 0 || name == models.ProjectSelectorEmpty {
+		return nil, false, fmt.Errorf("project name should not be empty")
+	}
+	owner := ""
+	if user := models.UserFromContext(request.Context()); user != nil {
+		owner = h.shares.OwnerOf(user, name)
+	}
+	projects, err := h.repository.Projects(request.Context())
+	if err != nil {
+		return nil, false, fmt.Errorf("cant get projects: %w", err)
+	}
+	if project := models.FindProject(projects, owner, name); project != nil {
+		return project, true, nil
+	}
+	return &models.Project{Name: name, Owner: owner}, false, nil
+}
+
+func (h *httpServer) htmxPageProject(writer http.ResponseWriter, request *http.Request) {
+	_ = request.ParseForm()
+	project, stored, err := h.htmxFindProject(request)
+	if err != nil {
+		http.Error(writer, err.Error(), 400)
+		return
+	}
+	context := &projectSettingsContext{Project: project, Stored: stored}
+	if user := models.UserFromContext(request.Context()); user != nil {
+		context.Collaborators = h.shares.Collaborators(user, project.Name)
+	}
+	projectHtml, deferFn, err := renderHtmx("component/project_settings", context)
+	defer deferFn()
+	if err != nil {
+		http.Error(writer, "error on render", 500)
+		return
+	}
+	writeHtmx(writer, "page/index", template.HTML(projectHtml.String()), 200)
+}
+
+func (h *httpServer) htmxSaveProject(writer http.ResponseWriter, request *http.Request) {
+	_ = request.ParseForm()
+	project, _, err := h.htmxFindProject(request)
+	if err != nil {
+		http.Error(writer, err.Error(), 400)
+		return
+	}
+	project.DisplayName = request.Form.Get("display_name")
+	project.Description = request.Form.Get("description")
+	project.Color = request.Form.Get("color")
+	project.Archived = request.Form.Has("archived")
+	project.DefaultTags = strings.Split(request.Form.Get("default_tags"), ",")
+	project.Unify()
+	if err := project.Validate(); err != nil {
+		http.Error(writer, "invalid project: "+err.Error(), 400)
+		return
+	}
+	if err := h.repository.SaveProject(request.Context(), project); err != nil {
+		http.Error(writer, "cant save project: "+err.Error(), projectErrorStatus(err))
+		return
+	}
+	writer.Header().Set("HX-Refresh", "true")
+	writer.WriteHeader(200)
+}
+
+func (h *httpServer) htmxDeleteProject(writer http.ResponseWriter, request *http.Request) {
+	_ = request.ParseForm()
+	project, _, err := h.htmxFindProject(request)
+	if err != nil {
+		http.Error(writer, err.Error(), 400)
+		return
+	}
+	if err := h.repository.DeleteProject(request.Context(), project); err != nil {
+		http.Error(writer, "cant delete project: "+err.Error(), projectErrorStatus(err))
+		return
+	}
+	writer.Header().Set("HX-Refresh", "true")
+	writer.WriteHeader(200)
+}
+
+// htmxRename renames the project or the tag, with dry_run it renders the preview of affected tasks.
+func (h *httpServer) htmxRename(writer http.ResponseWriter, request *http.Request) {
+	_ = request.ParseForm()
+	rename, err := models.NewRename(models.RenameKind(request.Form.Get("kind")), request.Form.Get("from"), request.Form.Get("to"))
+	if err != nil {
+		http.Error(writer, "invalid rename: "+err.Error(), 400)
+		return
+	}
+	rename.DryRun = request.Form.Has("dry_run")
+	rename.ModifiedBy = actorFromRequest(request)
+	preview, err := h.repository.Rename(request.Context(), rename)
+	if err != nil {
+		http.Error(writer, "cant rename: "+err.Error(), projectErrorStatus(err))
+		return
+	}
+	if !rename.DryRun {
+		writer.Header().Set("HX-Refresh", "true")
+		writer.WriteHeader(200)
+		return
+	}
+	writeHtmx(writer, "component/rename_preview", preview, 200)
+}
+
+func (h *httpServer) htmxPageFilters(writer http.ResponseWriter, request *http.Request) {
+	filters, err := h.repository.Filters(request.Context())
+	if err != nil {
+		http.Error(writer, "cant get filters: "+err.Error(), 500)
+		return
+	}
+	models.SortSavedFilters(filters)
+	filtersHtml, deferFn, err := renderHtmx("component/saved_filters", filters)
+	defer deferFn()
+	if err != nil {
+		http.Error(writer, "error on render", 500)
+		return
+	}
+	writeHtmx(writer, "page/index", template.HTML(filtersHtml.String()), 200)
+}
+
+// htmxSavedFilters renders quick links of saved filters for the navbar.
+func (h *httpServer) htmxSavedFilters(writer http.ResponseWriter, request *http.Request) {
+	filters, err := h.repository.Filters(request.Context())
+	if err != nil {
+		http.Error(writer, "cant get filters: "+err.Error(), 500)
+		return
+	}
+	models.SortSavedFilters(filters)
+	writeHtmx(writer, "component/saved_filters_menu", filters, 200)
+}
+
+func (h *httpServer) htmxSaveFilter(writer http.ResponseWriter, request *http.Request) {
+	_ = request.ParseForm()
+	filter := &models.SavedFilter{
+		Name:   request.Form.Get("name"),
+		Query:  request.Form.Get("query"),
+		Active: request.Form.Has("active"),
+	}
+	filter.Unify()
+	if err := filter.Validate(); err != nil {
+		http.Error(writer, "invalid filter: "+err.Error(), 400)
+		return
+	}
+	if err := h.repository.SaveFilter(request.Context(), filter); err != nil {
+		http.Error(writer, "cant save filter: "+err.Error(), 500)
+		return
+	}
+	writer.Header().Set("HX-Refresh", "true")
+	writer.WriteHeader(200)
+}
+
+func (h *httpServer) htmxDeleteFilter(writer http.ResponseWriter, request *http.Request) {
+	_ = request.ParseForm()
+	filter := &models.SavedFilter{Name: request.Form.Get("name")}
+	filter.Unify()
+	if err := h.repository.DeleteFilter(request.Context(), filter); err != nil {
+		http.Error(writer, "cant delete filter: "+err.Error(), 500)
+		return
+	}
+	writer.Header().Set("HX-Refresh", "true")
+	writer.WriteHeader(200)
+}
+
+type timeReportContext struct {
+	From           string
+	To             string
+	GroupBy        models.ReportGroupBy
+	GroupByOptions []models.ReportGroupBy
+	Project        string
+	AllProjects    map[string]int
+	Table          template.HTML
+	Formats        []string
+	Query          string
+}
+
+func (h *httpServer) htmxPageReport(writer http.ResponseWriter, request *http.Request) {
+	_ = request.ParseForm()
+	groupBy, err := models.ParseReportGroupBy(request.Form.Get("group_by"))
+	if err != nil {
+		http.Error(writer, err.Error(), 400)
+		return
+	}
+	from, to, err := models.ParseReportRange(request.Form.Get("from"), request.Form.Get("to"), time.Now())
+	if err != nil {
+		http.Error(writer, err.Error(), 400)
+		return
+	}
+	filter := models.NewTimeReportFilter()
+	allTasks, err := h.repository.Find(request.Context(), filter, models.Page{})
+	if err != nil {
+		http.Error(writer, "cant list tasks: "+err.Error(), 500)
+		return
+	}
+	filter.Project = request.Form.Get("project")
+	tasks := filter.Apply(slices.Clone(allTasks.Tasks))
+	report := models.BuildTimeReport(tasks, groupBy, from, to, time.Now())
+
+	if format := request.Form.Get("format"); len(format) > 0 {
+		if !slices.Contains(models.ReportFormatsAllowed, format) {
+			http.Error(writer, "unknown format", 400)
+			return
+		}
+		writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=report-%s.%s", groupBy, format))
+		_, _ = writer.Write([]byte(report.Render(format)))
+		return
+	}
+
+	query := url.Values{}
+	query.Set("from", from.Format(time.DateOnly))
+	query.Set("to", to.AddDate(0, 0, -1).Format(time.DateOnly))
+	query.Set("group_by", string(groupBy))
+	if len(filter.Project) > 0 {
+		query.Set("project", filter.Project)
+	}
+	context := &timeReportContext{
+		From:           query.Get("from"),
+		To:             query.Get("to"),
+		GroupBy:        groupBy,
+		GroupByOptions: models.ReportGroupByAllowed,
+		Project:        filter.Project,
+		AllProjects:    models.UniqProjects(allTasks.Tasks),
+		Table:          template.HTML(report.Render("html")),
+		Formats:        models.ReportFormatsAllowed,
+		Query:          query.Encode(),
+	}
+	reportHtml, deferFn, err := renderHtmx("component/time_report", context)
+	defer deferFn()
+	if err != nil {
+		http.Error(writer, "error on render", 500)
+		return
+	}
+	writeHtmx(writer, "page/index", template.HTML(reportHtml.String()), 200)
+}
+
 func (h *httpServer) htmxGetTask(writer http.ResponseWriter, request *http.Request) {
 	_ = request.ParseForm()
 	UUID := request.Form.Get("uuid")
@@ -192,7 +581,7 @@ func (h *httpServer) htmxGetTask(writer http.ResponseWriter, request *http.Reque
 		http.Error(writer, "cant parse UUID: %s"+err.Error(), 400)
 		return
 	}
-	task, err := h.repository.Get(parsedUUID)
+	task, err := h.repository.Get(request.Context(), parsedUUID)
 	if err != nil {
 		http.Error(writer, "cant fetch task: "+err.Error(), 500)
 		return
@@ -201,24 +590,36 @@ func (h *httpServer) htmxGetTask(writer http.ResponseWriter, request *http.Reque
 		http.Error(writer, "task not found", 400)
 		return
 	}
+	if err := models.ResolveBlockers(request.Context(), h.repository, task); err != nil {
+		http.Error(writer, "cant resolve dependencies: "+err.Error(), 500)
+		return
+	}
 	writer.Header().Set("HX-Reswap", "outerHTML")
 	writeHtmx(writer, "component/task_card", task, 200)
 }
 
 type taskModalContext struct {
-	Task           *models.Task
-	ProjectOptions []string
-	TagsOptions    []string
+	Task             *models.Task
+	ProjectOptions   []string
+	TagsOptions      []string
+	ParentOptions    []*models.Task
+	Subtasks         []*models.TaskTree
+	SubtasksProgress models.SubtasksProgress
+	PendingSubtasks  int
+	History          []*models.TaskHistoryEntry
+	// UDAFields are attributes of the schema with task values, empty if not set.
+	UDAFields []models.UDAValue
 }
 
-func (h *httpServer) htmxGenerateTaskModalContext(task *models.Task) (*taskModalContext, error) {
+func (h *httpServer) htmxGenerateTaskModalContext(ctx context.Context, task *models.Task) (*taskModalContext, error) {
 	filter := models.NewDefaultListFilter()
 	filter.ShowCompleted = true
-	tasks, err := h.repository.All()
+	filter.ShowArchived = true
+	result, err := h.repository.Find(ctx, filter, models.Page{})
 	if err != nil {
 		return nil, fmt.Errorf("cant list tasks: %w", err)
 	}
-	tasks = filter.Apply(tasks)
+	tasks := result.Tasks
 
 	projects := []string{}
 	for project := range models.UniqProjects(tasks) {
@@ -233,10 +634,41 @@ func (h *httpServer) htmxGenerateTaskModalContext(task *models.Task) (*taskModal
 		tags = append(tags, tag)
 	}
 	sort.Strings(tags)
+
+	subtasks := models.Subtasks(tasks, task.UUID)
+	descendants := map[uuid.UUID]struct{}{task.UUID: {}}
+	pendingSubtasks := 0
+	var walk func(trees []*models.TaskTree)
+	walk = func(trees []*models.TaskTree) {
+		for _, tree := range trees {
+			descendants[tree.Task.UUID] = struct{}{}
+			if tree.Task.Status.IsOpen() {
+				pendingSubtasks++
+			}
+			walk(tree.Children)
+		}
+	}
+	walk(subtasks)
+	parents := []*models.Task{}
+	for _, t := range tasks {
+		if _, ok := descendants[t.UUID]; ok || !t.Status.IsOpen() {
+			continue
+		}
+		parents = append(parents, t)
+	}
+	udaFields := []models.UDAValue{}
+	for _, definition := range models.GetUDASchema() {
+		udaFields = append(udaFields, models.UDAValue{Definition: definition, Value: task.UDA[definition.Name]})
+	}
 	return &taskModalContext{
-		Task:           task,
-		ProjectOptions: projects,
-		TagsOptions:    tags,
+		Task:             task,
+		ProjectOptions:   projects,
+		TagsOptions:      tags,
+		ParentOptions:    parents,
+		Subtasks:         subtasks,
+		SubtasksProgress: (&models.TaskTree{Task: task, Children: subtasks}).Progress(),
+		PendingSubtasks:  pendingSubtasks,
+		UDAFields:        udaFields,
 	}, nil
 }
 func (h *httpServer) htmxEditTask(writer http.ResponseWriter, request *http.Request) {
@@ -253,7 +685,7 @@ func (h *httpServer) htmxEditTask(writer http.ResponseWriter, request *http.Requ
 		return
 	}
 
-	task, err := h.repository.Get(parsedUUID)
+	task, err := h.repository.Get(request.Context(), parsedUUID)
 	if err != nil {
 		http.Error(writer, "cant fetch task: "+err.Error(), 500)
 		return
@@ -277,12 +709,32 @@ func (h *httpServer) htmxEditTask(writer http.ResponseWriter, request *http.Requ
 			notify := task.Notify.In(tz)
 			task.Notify = &notify
 		}
+		if task.Wait != nil {
+			wait := task.Wait.In(tz)
+			task.Wait = &wait
+		}
+		if task.Scheduled != nil {
+			scheduled := task.Scheduled.In(tz)
+			task.Scheduled = &scheduled
+		}
+		udaDatesIn(task, tz)
 	}
-	context, err := h.htmxGenerateTaskModalContext(task)
+	context, err := h.htmxGenerateTaskModalContext(request.Context(), task)
 	if err != nil {
 		http.Error(writer, "cant generate context: "+err.Error(), 500)
 		return
 	}
+	context.History, err = h.repository.History(request.Context(), task.UUID)
+	if err != nil {
+		http.Error(writer, "cant fetch task history: "+err.Error(), 500)
+		return
+	}
+	if len(timezone) > 0 {
+		tz, _ := time.LoadLocation(timezone)
+		for _, entry := range context.History {
+			entry.At = entry.At.In(tz)
+		}
+	}
 	writeHtmx(writer, "component/task_modal", context, 200)
 }
 
@@ -300,7 +752,7 @@ func (h *httpServer) htmxCopyTask(writer http.ResponseWriter, request *http.Requ
 		return
 	}
 
-	task, err := h.repository.Get(parsedUUID)
+	task, err := h.repository.Get(request.Context(), parsedUUID)
 	if err != nil {
 		http.Error(writer, "cant fetch task: "+err.Error(), 500)
 		return
@@ -324,10 +776,19 @@ func (h *httpServer) htmxCopyTask(writer http.ResponseWriter, request *http.Requ
 			notify := task.Notify.In(tz)
 			task.Notify = &notify
 		}
+		if task.Wait != nil {
+			wait := task.Wait.In(tz)
+			task.Wait = &wait
+		}
+		if task.Scheduled != nil {
+			scheduled := task.Scheduled.In(tz)
+			task.Scheduled = &scheduled
+		}
+		udaDatesIn(task, tz)
 	}
 	task = task.Clone(true)
 	task.Description = ""
-	context, err := h.htmxGenerateTaskModalContext(task)
+	context, err := h.htmxGenerateTaskModalContext(request.Context(), task)
 	if err != nil {
 		http.Error(writer, "cant generate context: "+err.Error(), 500)
 		return
@@ -338,7 +799,7 @@ func (h *httpServer) htmxCopyTask(writer http.ResponseWriter, request *http.Requ
 
 func (h *httpServer) htmxNewTask(writer http.ResponseWriter, request *http.Request) {
 	task := models.NewTask()
-	context, err := h.htmxGenerateTaskModalContext(task)
+	context, err := h.htmxGenerateTaskModalContext(request.Context(), task)
 	if err != nil {
 		http.Error(writer, "cant generate context: "+err.Error(), 500)
 		return
@@ -347,6 +808,25 @@ func (h *httpServer) htmxNewTask(writer http.ResponseWriter, request *http.Reque
 	writeHtmx(writer, "component/task_modal", context, 200)
 }
 
+func (h *httpServer) htmxNewFromTemplate(writer http.ResponseWriter, request *http.Request) {
+	_ = request.ParseForm()
+	taskTemplate := models.FindTaskTemplate(request.Form.Get("name"))
+	if taskTemplate == nil {
+		http.Error(writer, "template not found", 400)
+		return
+	}
+	tasks := taskTemplate.Instantiate(time.Now())
+	for _, task := range tasks {
+		task.ModifiedBy = actorFromRequest(request)
+	}
+	if err := h.repository.Insert(request.Context(), tasks...); err != nil {
+		http.Error(writer, "cant save tasks: "+err.Error(), insertErrorStatus(err))
+		return
+	}
+	writer.Header().Set("HX-Redirect", "/task?uuid="+tasks[0].UUID.String())
+	writer.WriteHeader(200)
+}
+
 func (h *httpServer) htmxSaveStatus(writer http.ResponseWriter, request *http.Request) {
 	_ = request.ParseForm()
 	UUID := request.Form.Get("uuid")
@@ -370,30 +850,184 @@ func (h *httpServer) htmxSaveStatus(writer http.ResponseWriter, request *http.Re
 		http.Error(writer, "invalid status", 400)
 		return
 	}
-	task, err := h.repository.Get(parsedUUID)
+	task, err := h.repository.Get(request.Context(), parsedUUID)
 	if err != nil {
 		http.Error(writer, "cant fetch task: "+err.Error(), 500)
 		return
 	}
 
 	if task == nil {
+		http.Error(writer, "task not found", 400)
+		return
+	}
+	if err := setFormRevision(task, request.Form.Get("revision")); err != nil {
+		http.Error(writer, err.Error(), 400)
+		return
+	}
+	previousStatus := task.Status
+	task.Status = parsedStatus
+	task.ModifiedBy = actorFromRequest(request)
+	isCompleting := models.IsClosing(previousStatus, task.Status)
+	subtasksAction := request.Form.Get("subtasks")
+	if isCompleting && subtasksAction == "" {
+		pending, err := models.PendingSubtasks(request.Context(), h.repository, task.UUID)
 		if err != nil {
-			http.Error(writer, "task not found", 400)
+			http.Error(writer, "cant fetch subtasks: "+err.Error(), 500)
+			return
+		}
+		if len(pending) > 0 {
+			writeHtmx(writer, "component/subtasks_warning", &subtasksWarningContext{Task: task, Pending: pending}, http.StatusConflict)
 			return
 		}
 	}
-	task.Status = parsedStatus
-	if err := h.repository.Insert(task); err != nil {
-		http.Error(writer, "cant save task: "+err.Error(), 500)
+	related := []*models.Task{}
+	if isCompleting && subtasksAction == "complete" {
+		subtasks, subtasksNext, err := models.CompleteSubtasks(request.Context(), h.repository, task)
+		if err != nil {
+			http.Error(writer, "cant complete subtasks: "+err.Error(), 500)
+			return
+		}
+		related = append(subtasks, subtasksNext...)
+	}
+	next, err := models.InsertTask(request.Context(), h.repository, previousStatus, task, related...)
+	if err != nil {
+		http.Error(writer, "cant save task: "+err.Error(), insertErrorStatus(err))
 		return
 	}
+	if len(related) > 0 {
+		// cards of the completed subtasks are somewhere on the page
+		writer.Header().Set("HX-Refresh", "true")
+	}
 
+	if err := models.ResolveBlockers(request.Context(), h.repository, task); err != nil {
+		http.Error(writer, "cant resolve dependencies: "+err.Error(), 500)
+		return
+	}
 	writer.Header().Set("HX-Reswap", "outerHTML")
+	if next != nil {
+		if err := models.ResolveBlockers(request.Context(), h.repository, next); err != nil {
+			http.Error(writer, "cant resolve dependencies: "+err.Error(), 500)
+			return
+		}
+		writeHtmx(writer, "component/task_cards", []*models.Task{task, next}, 200)
+		return
+	}
 	writeHtmx(writer, "component/task_card", task, 200)
 }
 
-func (h *httpServer) htmxSaveTask(writer http.ResponseWriter, request *http.Request) {
+type subtasksWarningContext struct {
+	Task    *models.Task
+	Pending []*models.Task
+}
+
+func (h *httpServer) htmxSaveChecklist(writer http.ResponseWriter, request *http.Request) {
+	_ = request.ParseForm()
+	parsedUUID, err := uuid.Parse(request.Form.Get("uuid"))
+	if err != nil {
+		http.Error(writer, "cant parse UUID: "+err.Error(), 400)
+		return
+	}
+	item, err := strconv.Atoi(request.Form.Get("item"))
+	if err != nil {
+		http.Error(writer, "cant parse item: "+err.Error(), 400)
+		return
+	}
+	checked, err := strconv.ParseBool(request.Form.Get("checked"))
+	if err != nil {
+		http.Error(writer, "cant parse checked: "+err.Error(), 400)
+		return
+	}
+	task, err := h.repository.Get(request.Context(), parsedUUID)
+	if err != nil {
+		http.Error(writer, "cant fetch task: "+err.Error(), 500)
+		return
+	}
+	if task == nil {
+		http.Error(writer, "task not found", 400)
+		return
+	}
+	if err := setFormRevision(task, request.Form.Get("revision")); err != nil {
+		http.Error(writer, err.Error(), 400)
+		return
+	}
+	if err := task.CheckChecklistItem(item, checked); err != nil {
+		http.Error(writer, err.Error(), 400)
+		return
+	}
+	task.ModifiedBy = actorFromRequest(request)
+	if err := h.repository.Insert(request.Context(), task); err != nil {
+		http.Error(writer, "cant save task: "+err.Error(), insertErrorStatus(err))
+		return
+	}
+	if err := models.ResolveBlockers(request.Context(), h.repository, task); err != nil {
+		http.Error(writer, "cant resolve dependencies: "+err.Error(), 500)
+		return
+	}
+	writer.Header().Set("HX-Reswap", "outerHTML")
+	writeHtmx(writer, "component/task_card", task, 200)
+}
+
+func (h *httpServer) htmxSaveTracking(writer http.ResponseWriter, request *http.Request) {
 	_ = request.ParseForm()
+	parsedUUID, err := uuid.Parse(request.Form.Get("uuid"))
+	if err != nil {
+		http.Error(writer, "cant parse UUID: "+err.Error(), 400)
+		return
+	}
+	action := request.Form.Get("action")
+	if action != "start" && action != "stop" {
+		http.Error(writer, "action should be start or stop", 400)
+		return
+	}
+	task, err := h.repository.Get(request.Context(), parsedUUID)
+	if err != nil {
+		http.Error(writer, "cant fetch task: "+err.Error(), 500)
+		return
+	}
+	if task == nil {
+		http.Error(writer, "task not found", 400)
+		return
+	}
+	if err := setFormRevision(task, request.Form.Get("revision")); err != nil {
+		http.Error(writer, err.Error(), 400)
+		return
+	}
+	task.ModifiedBy = actorFromRequest(request)
+	if action == "start" {
+		stopped, err := models.StartTracking(request.Context(), h.repository, task, time.Now())
+		if err != nil {
+			http.Error(writer, "cant start task: "+err.Error(), insertErrorStatus(err))
+			return
+		}
+		if len(stopped) > 0 {
+			// cards of the stopped tasks are somewhere on the page
+			writer.Header().Set("HX-Refresh", "true")
+		}
+	} else {
+		if err := task.StopTracking(time.Now()); err != nil {
+			http.Error(writer, "cant stop task: "+err.Error(), insertErrorStatus(err))
+			return
+		}
+		if err := h.repository.Insert(request.Context(), task); err != nil {
+			http.Error(writer, "cant save task: "+err.Error(), insertErrorStatus(err))
+			return
+		}
+	}
+	if err := models.ResolveBlockers(request.Context(), h.repository, task); err != nil {
+		http.Error(writer, "cant resolve dependencies: "+err.Error(), 500)
+		return
+	}
+	writer.Header().Set("HX-Reswap", "outerHTML")
+	writeHtmx(writer, "component/task_card", task, 200)
+}
+
+// maxSaveTaskBodySize leaves a room for several attachments in the task form.
+const maxSaveTaskBodySize = 4 * models.MaxAttachmentSize
+
+func (h *httpServer) htmxSaveTask(writer http.ResponseWriter, request *http.Request) {
+	request.Body = http.MaxBytesReader(writer, request.Body, maxSaveTaskBodySize)
+	// the form is multipart when files are attached, ParseMultipartForm parses urlencoded forms too
+	_ = request.ParseMultipartForm(models.MaxAttachmentSize)
 	UUID := request.Form.Get("uuid")
 	status := request.Form.Get("status")
 	description := request.Form.Get("description")
@@ -428,7 +1062,7 @@ func (h *httpServer) htmxSaveTask(writer http.ResponseWriter, request *http.Requ
 		return
 	}
 	isNewTask := false
-	task, err := h.repository.Get(parsedUUID)
+	task, err := h.repository.Get(request.Context(), parsedUUID)
 	if err != nil {
 		http.Error(writer, "cant fetch task: "+err.Error(), 500)
 		return
@@ -438,7 +1072,11 @@ func (h *httpServer) htmxSaveTask(writer http.ResponseWriter, request *http.Requ
 		isNewTask = true
 		task = models.NewTask()
 		task.UUID = parsedUUID
+	} else if err := setFormRevision(task, request.Form.Get("revision")); err != nil {
+		http.Error(writer, err.Error(), 400)
+		return
 	}
+	previousStatus := task.Status
 	task.Status = parsedStatus
 	task.Description = description
 	task.Project = strings.TrimSpace(strings.ToLower(request.Form.Get("project")))
@@ -463,9 +1101,93 @@ func (h *httpServer) htmxSaveTask(writer http.ResponseWriter, request *http.Requ
 		return
 	}
 	task.Notify = notifyTime
+	waitTime, err := parseBrowserTime(request.Form.Get("wait"), timezone)
+	if err != nil {
+		http.Error(writer, "cant parse wait: "+err.Error(), 400)
+		return
+	}
+	task.Wait = waitTime
+	scheduledTime, err := parseBrowserTime(request.Form.Get("scheduled"), timezone)
+	if err != nil {
+		http.Error(writer, "cant parse scheduled: "+err.Error(), 400)
+		return
+	}
+	task.Scheduled = scheduledTime
+	task.Recur = strings.TrimSpace(request.Form.Get("recur"))
+	priority, err := models.ParsePriority(request.Form.Get("priority"))
+	if err != nil {
+		http.Error(writer, "cant parse priority: "+err.Error(), 400)
+		return
+	}
+	task.Priority = priority
+	task.Parent = nil
+	if parent := strings.TrimSpace(request.Form.Get("parent")); len(parent) > 0 {
+		parsedParent, err := uuid.Parse(parent)
+		if err != nil {
+			http.Error(writer, "cant parse parent: "+err.Error(), 400)
+			return
+		}
+		task.Parent = &parsedParent
+	}
+	task.Depends = nil
+	for _, dependency := range strings.Split(request.Form.Get("depends"), ",") {
+		dependency = strings.TrimSpace(dependency)
+		if len(dependency) == 0 {
+			continue
+		}
+		parsedDependency, err := uuid.Parse(dependency)
+		if err != nil {
+			http.Error(writer, "cant parse depends: "+err.Error(), 400)
+			return
+		}
+		task.Depends = append(task.Depends, parsedDependency)
+	}
+	for _, definition := range models.GetUDASchema() {
+		value := strings.TrimSpace(request.Form.Get("uda_" + definition.Name))
+		if definition.Type == models.UDADate {
+			date, err := parseBrowserTime(value, timezone)
+			if err != nil {
+				http.Error(writer, "cant parse "+definition.Name+": "+err.Error(), 400)
+				return
+			}
+			value = ""
+			if date != nil {
+				value = date.Format(time.RFC3339)
+			}
+		} else if len(value) > 0 {
+			value, err = definition.Parse(value)
+			if err != nil {
+				http.Error(writer, "cant parse "+definition.Name+": "+err.Error(), 400)
+				return
+			}
+		}
+		task.SetUDA(definition.Name, value)
+	}
+	task.Checklist = models.ParseChecklistText(request.Form.Get("checklist"))
+	if annotation := strings.TrimSpace(request.Form.Get("annotation")); len(annotation) > 0 {
+		task.Annotate(annotation, time.Now())
+	}
+	if request.MultipartForm != nil {
+		for _, header := range request.MultipartForm.File["attachment"] {
+			if err := h.attachFormFile(request, task, header); err != nil {
+				http.Error(writer, "cant attach file: "+err.Error(), insertErrorStatus(err))
+				return
+			}
+		}
+	}
+	task.ModifiedBy = actorFromRequest(request)
 
-	if err := h.repository.Insert(task); err != nil {
-		http.Error(writer, "cant save task: "+err.Error(), 500)
+	related := []*models.Task{}
+	if models.IsClosing(previousStatus, task.Status) && request.Form.Has("complete_subtasks") {
+		subtasks, subtasksNext, err := models.CompleteSubtasks(request.Context(), h.repository, task)
+		if err != nil {
+			http.Error(writer, "cant complete subtasks: "+err.Error(), 500)
+			return
+		}
+		related = append(subtasks, subtasksNext...)
+	}
+	if _, err := models.InsertTask(request.Context(), h.repository, previousStatus, task, related...); err != nil {
+		http.Error(writer, "cant save task: "+err.Error(), insertErrorStatus(err))
 		return
 	}
 	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
@@ -478,6 +1200,15 @@ func (h *httpServer) htmxSaveTask(writer http.ResponseWriter, request *http.Requ
 	_, _ = writer.Write([]byte("Success!"))
 }
 
+// udaDatesIn converts date attributes to the browser timezone, like other task dates.
+func udaDatesIn(task *models.Task, tz *time.Location) {
+	for _, value := range task.UDAValues() {
+		if date := value.Time(); date != nil {
+			task.SetUDA(value.Definition.Name, date.In(tz).Format(time.RFC3339))
+		}
+	}
+}
+
 func parseBrowserTime(browserDatetime string, timezone string) (*time.Time, error) {
 	if len(browserDatetime) == 0 {
 		return nil, nil
@@ -492,3 +1223,30 @@ func parseBrowserTime(browserDatetime string, timezone string) (*time.Time, erro
 	}
 	return &result, nil
 }
+
+// setFormRevision sets the revision the browser has seen, so the repository can reject the stale write.
+func setFormRevision(task *models.Task, revision string) error {
+	if len(revision) == 0 {
+		return nil
+	}
+	parsedRevision, err := strconv.ParseInt(revision, 10, 64)
+	if err != nil {
+		return fmt.Errorf("cant parse revision: %w", err)
+	}
+	task.Revision = parsedRevision
+	return nil
+}
+
+func insertErrorStatus(err error) int {
+	if errors.Is(err, models.TaskConflictError) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, models.TaskPermissionError) {
+		return http.StatusForbidden
+	}
+	if errors.Is(err, models.TaskParentError) || errors.Is(err, models.TaskTrackingError) ||
+		errors.Is(err, models.TaskAttachmentError) || errors.Is(err, models.TaskChangesError) {
+		return 400
+	}
+	return 500
+}
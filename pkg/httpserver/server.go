@@ -9,6 +9,7 @@ import (
 	"github.com/paragor/todo/pkg/models"
 	"github.com/paragor/todo/public"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net"
 	"net/http"
 	"os"
 	"path"
@@ -18,11 +19,13 @@ import (
 )
 
 type httpServer struct {
-	listen     string
-	mux        *mux.Router
-	repository models.Repository
-	authConfig *AuthChainConfig
-	oidc       *authOidcContext
+	listen      string
+	mux         *mux.Router
+	repository  models.Repository
+	attachments models.AttachmentStorage
+	shares      models.ProjectShares
+	authConfig  *AuthChainConfig
+	oidc        *authOidcContext
 
 	cancel       func()
 	shutdownChan chan struct{}
@@ -31,11 +34,20 @@ type httpServer struct {
 func NewHttpServer(
 	listen string,
 	repository models.Repository,
+	attachments models.AttachmentStorage,
+	shares models.ProjectShares,
 	authConfig *AuthChainConfig,
 	serverPublicUrl string,
 	diagnosticEndpointsEnabled bool,
 ) (*httpServer, error) {
-	server := &httpServer{listen: listen, mux: mux.NewRouter(), repository: repository, authConfig: authConfig}
+	server := &httpServer{
+		listen:      listen,
+		mux:         mux.NewRouter(),
+		repository:  repository,
+		attachments: attachments,
+		shares:      shares,
+		authConfig:  authConfig,
+	}
 	server.mux.Use(
 		handlers.RecoveryHandler(),
 		func(handler http.Handler) http.Handler {
@@ -59,7 +71,7 @@ func NewHttpServer(
 		server.mux.Path("/login").HandlerFunc(server.htmxPageLogin)
 	}
 	if authConfig != nil && authConfig.AuthOidcConfig != nil {
-		oidc, err := newOidcContext(*authConfig.AuthOidcConfig, serverPublicUrl+"/oidc/callback", "/")
+		oidc, err := newOidcContext(*authConfig.AuthOidcConfig, authConfig.Users, serverPublicUrl+"/oidc/callback", "/")
 		if err != nil {
 			return nil, fmt.Errorf("cant init oidc: %w", err)
 		}
@@ -74,28 +86,50 @@ func NewHttpServer(
 	}
 
 	htmx := server.mux.Name("htmx").Subrouter()
-	if authConfig != nil {
-		htmx.Use(server.AuthChainMiddleware())
-	}
+	htmx.Use(server.AuthChainMiddleware())
 	htmx.Path("/").HandlerFunc(server.htmxPageMain)
 	htmx.Path("/projects").HandlerFunc(server.htmxPageProjects)
+	htmx.Path("/project").HandlerFunc(server.htmxPageProject)
 	htmx.Path("/agenda").HandlerFunc(server.htmxPageAgenda)
+	htmx.Path("/report").HandlerFunc(server.htmxPageReport)
+	htmx.Path("/filters").HandlerFunc(server.htmxPageFilters)
 	htmx.Path("/task").HandlerFunc(server.htmxPageTask)
 	htmx.Path("/htmx/get_task").HandlerFunc(server.htmxGetTask)
 	htmx.Path("/htmx/edit_task").HandlerFunc(server.htmxEditTask)
 	htmx.Path("/htmx/copy_task").HandlerFunc(server.htmxCopyTask)
 	htmx.Path("/htmx/new_task").HandlerFunc(server.htmxNewTask)
+	htmx.Path("/htmx/api/new_from_template").Methods("PUT").HandlerFunc(server.htmxNewFromTemplate)
 	htmx.Path("/htmx/api/save_status").Methods("PUT").HandlerFunc(server.htmxSaveStatus)
 	htmx.Path("/htmx/api/save_task").Methods("PUT").HandlerFunc(server.htmxSaveTask)
+	htmx.Path("/htmx/api/save_tracking").Methods("PUT").HandlerFunc(server.htmxSaveTracking)
+	htmx.Path("/htmx/api/save_checklist").Methods("PUT").HandlerFunc(server.htmxSaveChecklist)
+	htmx.Path("/htmx/api/save_project").Methods("PUT").HandlerFunc(server.htmxSaveProject)
+	htmx.Path("/htmx/api/delete_project").Methods("PUT").HandlerFunc(server.htmxDeleteProject)
+	htmx.Path("/htmx/api/rename").Methods("PUT").HandlerFunc(server.htmxRename)
+	htmx.Path("/htmx/saved_filters").HandlerFunc(server.htmxSavedFilters)
+	htmx.Path("/htmx/api/save_filter").Methods("PUT").HandlerFunc(server.htmxSaveFilter)
+	htmx.Path("/htmx/api/delete_filter").Methods("PUT").HandlerFunc(server.htmxDeleteFilter)
+
+	attachmentsRouter := server.mux.Name("attachments").PathPrefix("/attachments/").Subrouter()
+	attachmentsRouter.Use(server.AuthChainMiddleware())
+	attachmentsRouter.Path("/{task}/{attachment}/{name}").Methods("GET").HandlerFunc(server.getAttachment)
 
 	api := server.mux.Name("api").PathPrefix("/api/").Subrouter()
-	if authConfig != nil {
-		api.Use(server.AuthChainMiddleware())
-	}
+	api.Use(server.AuthChainMiddleware())
 	api.Path("/ping").HandlerFunc(server.apiPing)
 	api.Path("/all").HandlerFunc(server.apiAllTask)
+	api.Path("/find").HandlerFunc(server.apiFindTasks)
 	api.Path("/get_task").HandlerFunc(server.apiGetTask)
+	api.Path("/task_history").HandlerFunc(server.apiTaskHistory)
 	api.Path("/insert_task").Methods("PUT").HandlerFunc(server.apiInsertTask)
+	api.Path("/insert_tasks").Methods("PUT").HandlerFunc(server.apiInsertTasks)
+	api.Path("/projects").HandlerFunc(server.apiProjects)
+	api.Path("/save_project").Methods("PUT").HandlerFunc(server.apiSaveProject)
+	api.Path("/delete_project").Methods("PUT").HandlerFunc(server.apiDeleteProject)
+	api.Path("/rename").Methods("PUT").HandlerFunc(server.apiRename)
+	api.Path("/filters").HandlerFunc(server.apiFilters)
+	api.Path("/save_filter").Methods("PUT").HandlerFunc(server.apiSaveFilter)
+	api.Path("/delete_filter").Methods("PUT").HandlerFunc(server.apiDeleteFilter)
 
 	return server, nil
 }
@@ -110,7 +144,13 @@ func (h *httpServer) Stop() {
 }
 func (h *httpServer) Start(ctx context.Context, stopper chan<- error) error {
 	h.shutdownChan = make(chan struct{}, 1)
-	server := &http.Server{Addr: h.listen, Handler: h.mux}
+	// in-flight requests are aborted only if graceful shutdown is timed out
+	requestsCtx, abortRequests := context.WithCancel(context.Background())
+	server := &http.Server{
+		Addr:        h.listen,
+		Handler:     h.mux,
+		BaseContext: func(net.Listener) context.Context { return requestsCtx },
+	}
 	go func() {
 		err := server.ListenAndServe()
 		stopper <- fmt.Errorf("stop httpserver: %w", err)
@@ -121,6 +161,7 @@ func (h *httpServer) Start(ctx context.Context, stopper chan<- error) error {
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		defer cancel()
 		_ = server.Shutdown(ctx)
+		abortRequests()
 	}()
 	return nil
 }
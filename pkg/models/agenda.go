@@ -9,49 +9,54 @@ func truncateToDay(date time.Time) time.Time {
 	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 }
 
+// Agenda expects tasks with resolved blockers, blocked tasks are not shown in Today until blockers are completed.
+// Waiting tasks are hidden, scheduled tasks are shown in Today on their scheduled day even without due,
+// scheduled tasks without due stay in Today after their scheduled day until they are closed.
+// Tasks in every group are ordered by urgency.
 func Agenda(tasks []*Task) []TaskGroup {
-	todayStart := truncateToDay(time.Now())
-	todayTasks := []*Task{}
-	for _, task := range tasks {
-		if task.Due == nil {
-			continue
-		}
-		due := truncateToDay(*task.Due)
-		if !due.Equal(todayStart) {
-			continue
+	now := time.Now()
+	todayStart := truncateToDay(now)
+	weekEnd := todayStart.Add(7 * 24 * time.Hour)
+	isDay := func(date *time.Time, day time.Time) bool {
+		return date != nil && truncateToDay(*date).Equal(day)
+	}
+	isScheduledToday := func(task *Task) bool {
+		if task.Due == nil && task.Scheduled != nil {
+			return !truncateToDay(*task.Scheduled).After(todayStart)
 		}
-		todayTasks = append(todayTasks, task)
+		return isDay(task.Scheduled, todayStart)
 	}
-	SortTasks(todayTasks)
 
+	todayTasks := []*Task{}
 	overdueTasks := []*Task{}
+	thisWeekTasks := []*Task{}
 	for _, task := range tasks {
-		if task.Due == nil {
+		if task.IsWaiting(now) {
 			continue
 		}
-		due := truncateToDay(*task.Due)
-		if due.After(todayStart) || due.Equal(todayStart) {
+		if (isDay(task.Due, todayStart) || isScheduledToday(task)) && !task.IsBlocked() {
+			todayTasks = append(todayTasks, task)
 			continue
 		}
-		overdueTasks = append(overdueTasks, task)
-	}
-	SortTasks(overdueTasks)
-
-	thisWeekTasks := []*Task{}
-	for _, task := range tasks {
-		if task.Due == nil {
-			continue
+		date := task.Due
+		if date == nil {
+			date = task.Scheduled
 		}
-		due := truncateToDay(*task.Due)
-		if due.Before(todayStart) || due.Equal(todayStart) {
+		if date == nil {
 			continue
 		}
-		if due.After(todayStart.Add(7 * 24 * time.Hour)) {
+		day := truncateToDay(*date)
+		if task.Due != nil && day.Before(todayStart) {
+			overdueTasks = append(overdueTasks, task)
 			continue
 		}
-		thisWeekTasks = append(thisWeekTasks, task)
+		if day.After(todayStart) && !day.After(weekEnd) {
+			thisWeekTasks = append(thisWeekTasks, task)
+		}
 	}
-	SortTasks(thisWeekTasks)
+	SortTasksByUrgency(todayTasks, now)
+	SortTasksByUrgency(overdueTasks, now)
+	SortTasksByUrgency(thisWeekTasks, now)
 
 	return []TaskGroup{
 		{
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAgenda_waitAndScheduled(t *testing.T) {
+	now := time.Now()
+	tomorrow := now.Add(24 * time.Hour)
+	yesterday := now.Add(-24 * time.Hour)
+
+	planned := NewTask()
+	planned.Description = "planned"
+	planned.Scheduled = &now
+	waiting := NewTask()
+	waiting.Description = "waiting"
+	waiting.Due = &now
+	waiting.Wait = &tomorrow
+	waited := NewTask()
+	waited.Description = "waited"
+	waited.Due = &now
+	waited.Wait = &yesterday
+	later := NewTask()
+	later.Description = "later"
+	later.Scheduled = &tomorrow
+	started := NewTask()
+	started.Description = "started"
+	started.Scheduled = &yesterday
+
+	agenda := Agenda([]*Task{planned, waiting, waited, later, started})
+	today := agenda[0].Tasks
+	if len(today) != 3 || !slices.Contains(today, planned) || !slices.Contains(today, waited) || !slices.Contains(today, started) {
+		t.Errorf("today should contain planned, waited and started tasks, have %+v", today)
+	}
+	if week := agenda[1].Tasks; len(week) != 1 || week[0] != later {
+		t.Errorf("next 7 days should contain later task, have %+v", week)
+	}
+
+	filter := NewDefaultListFilter()
+	if tasks := filter.Apply([]*Task{planned, waiting, waited}); len(tasks) != 2 {
+		t.Errorf("waiting task should be hidden, have %+v", tasks)
+	}
+	filter.ShowWaiting = true
+	if tasks := filter.Apply([]*Task{planned, waiting, waited}); len(tasks) != 3 {
+		t.Errorf("waiting task should be shown, have %+v", tasks)
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TaskAnnotation is a timestamped note, annotations keep progress of the task without rewriting its description.
+type TaskAnnotation struct {
+	At   time.Time `json:"at"`
+	Text string    `json:"text"`
+}
+
+func (a TaskAnnotation) validate() error {
+	if a.At.IsZero() {
+		return fmt.Errorf("annotation time should not be zero")
+	}
+	if len(strings.TrimSpace(a.Text)) == 0 {
+		return fmt.Errorf("annotation text should not be empty")
+	}
+	return nil
+}
+
+func (t *Task) Annotate(text string, at time.Time) {
+	t.Annotations = append(t.Annotations, TaskAnnotation{At: at, Text: strings.TrimSpace(text)})
+}
+
+func unifyAnnotations(annotations []TaskAnnotation) []TaskAnnotation {
+	if len(annotations) == 0 {
+		return nil
+	}
+	annotations = slices.Clone(annotations)
+	slices.SortStableFunc(annotations, func(a, b TaskAnnotation) int {
+		return a.At.Compare(b.At)
+	})
+	return annotations
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// MaxAttachmentSize is the same as the download limit of telegram bots.
+const MaxAttachmentSize = 20 << 20
+
+// TaskAttachment is the metadata of a file attached to the task, the content is kept in AttachmentStorage.
+type TaskAttachment struct {
+	UUID        uuid.UUID `json:"uuid"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type AttachmentStorage interface {
+	SaveAttachment(ctx context.Context, UUID uuid.UUID, content []byte) error
+	// LoadAttachment returns nil if the attachment is not found.
+	LoadAttachment(ctx context.Context, UUID uuid.UUID) ([]byte, error)
+}
+
+func (a TaskAttachment) validate() error {
+	if a.UUID == uuid.Nil {
+		return fmt.Errorf("attachment uuid should not be nil")
+	}
+	if len(a.Name) == 0 {
+		return fmt.Errorf("attachment name should not be empty")
+	}
+	return nil
+}
+
+// HumanSize returns the size in B, KB or MB.
+func (a TaskAttachment) HumanSize() string {
+	switch {
+	case a.Size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(a.Size)/(1<<20))
+	case a.Size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(a.Size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", a.Size)
+}
+
+// AttachmentPath is the path of the http route which serves the attachment.
+func (t *Task) AttachmentPath(attachment TaskAttachment) string {
+	return "/attachments/" + t.UUID.String() + "/" + attachment.UUID.String() + "/" + url.PathEscape(attachment.Name)
+}
+
+func (t *Task) FindAttachment(UUID uuid.UUID) *TaskAttachment {
+	for i := range t.Attachments {
+		if t.Attachments[i].UUID == UUID {
+			return &t.Attachments[i]
+		}
+	}
+	return nil
+}
+
+// Attach saves the content to the storage and adds the attachment to the task, the task should be inserted after.
+func Attach(ctx context.Context, storage AttachmentStorage, task *Task, name string, contentType string, content []byte, now time.Time) (*TaskAttachment, error) {
+	if len(content) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", TaskAttachmentError)
+	}
+	if len(content) > MaxAttachmentSize {
+		return nil, fmt.Errorf("%w: file is larger than %d MB", TaskAttachmentError, MaxAttachmentSize>>20)
+	}
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "." || name == "/" || len(name) == 0 {
+		name = "attachment"
+	}
+	if len(contentType) == 0 {
+		contentType = http.DetectContentType(content)
+	}
+	attachment := TaskAttachment{
+		UUID:        uuid.New(),
+		Name:        name,
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		CreatedAt:   now,
+	}
+	if err := storage.SaveAttachment(ctx, attachment.UUID, content); err != nil {
+		return nil, fmt.Errorf("cant save attachment: %w", err)
+	}
+	task.Attachments = append(task.Attachments, attachment)
+	return &attachment, nil
+}
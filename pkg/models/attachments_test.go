@@ -0,0 +1,52 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testAttachmentStorage map[uuid.UUID][]byte
+
+func (s testAttachmentStorage) SaveAttachment(ctx context.Context, UUID uuid.UUID, content []byte) error {
+	s[UUID] = content
+	return nil
+}
+
+func (s testAttachmentStorage) LoadAttachment(ctx context.Context, UUID uuid.UUID) ([]byte, error) {
+	return s[UUID], nil
+}
+
+func TestAttach(t *testing.T) {
+	storage := testAttachmentStorage{}
+	task := NewTask()
+	task.Description = "pay invoice"
+
+	attachment, err := Attach(context.Background(), storage, task, "C:\\scans\\invoice.pdf", "", []byte("%PDF-1.4"), time.Now())
+	if err != nil {
+		t.Fatalf("cant attach: %s", err)
+	}
+	if attachment.Name != "invoice.pdf" || attachment.Size != 8 || len(attachment.ContentType) == 0 {
+		t.Errorf("unexpected attachment: %+v", attachment)
+	}
+	if string(storage[attachment.UUID]) != "%PDF-1.4" {
+		t.Errorf("content should be saved to the storage")
+	}
+	if task.FindAttachment(attachment.UUID) == nil || task.Validate() != nil {
+		t.Errorf("attachment should be added to the task: %+v", task.Attachments)
+	}
+	if path := task.AttachmentPath(*attachment); !strings.HasSuffix(path, "/invoice.pdf") {
+		t.Errorf("unexpected attachment path: %s", path)
+	}
+
+	large := make([]byte, MaxAttachmentSize+1)
+	if _, err := Attach(context.Background(), storage, task, "large.bin", "", large, time.Now()); !errors.Is(err, TaskAttachmentError) {
+		t.Errorf("large file should be rejected, have %v", err)
+	}
+	if len(task.Attachments) != 1 {
+		t.Errorf("rejected file should not be added")
+	}
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChecklistItem is a step of a small task which does not deserve a subtask.
+type ChecklistItem struct {
+	Text    string `json:"text"`
+	Checked bool   `json:"checked,omitempty"`
+}
+
+const (
+	checklistCheckedPrefix   = "[x]"
+	checklistUncheckedPrefix = "[ ]"
+)
+
+func (i ChecklistItem) validate() error {
+	if len(strings.TrimSpace(i.Text)) == 0 {
+		return fmt.Errorf("checklist item text should not be empty")
+	}
+	return nil
+}
+
+func (t *Task) AddChecklistItem(text string) {
+	t.Checklist = append(t.Checklist, ChecklistItem{Text: strings.TrimSpace(text)})
+}
+
+func (t *Task) CheckChecklistItem(index int, checked bool) error {
+	if index < 0 || index >= len(t.Checklist) {
+		return fmt.Errorf("checklist item %d not found", index)
+	}
+	t.Checklist[index].Checked = checked
+	return nil
+}
+
+// ChecklistProgress returns checked and total items like 3/5, empty without checklist.
+func (t *Task) ChecklistProgress() string {
+	if len(t.Checklist) == 0 {
+		return ""
+	}
+	checked := 0
+	for _, item := range t.Checklist {
+		if item.Checked {
+			checked++
+		}
+	}
+	return fmt.Sprintf("%d/%d", checked, len(t.Checklist))
+}
+
+// ChecklistText is the checklist for the text editor, one item per line, checked items start with [x].
+func (t *Task) ChecklistText() string {
+	lines := []string{}
+	for _, item := range t.Checklist {
+		prefix := checklistUncheckedPrefix
+		if item.Checked {
+			prefix = checklistCheckedPrefix
+		}
+		lines = append(lines, prefix+" "+item.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseChecklistText is the reverse of ChecklistText, lines without prefix are unchecked items.
+func ParseChecklistText(text string) []ChecklistItem {
+	var result []ChecklistItem
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		item := ChecklistItem{Text: line}
+		if strings.HasPrefix(strings.ToLower(line), checklistCheckedPrefix) {
+			item = ChecklistItem{Text: strings.TrimSpace(line[len(checklistCheckedPrefix):]), Checked: true}
+		} else if strings.HasPrefix(line, checklistUncheckedPrefix) {
+			item.Text = strings.TrimSpace(strings.TrimPrefix(line, checklistUncheckedPrefix))
+		}
+		if len(item.Text) == 0 {
+			continue
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+func unifyChecklist(checklist []ChecklistItem) []ChecklistItem {
+	var result []ChecklistItem
+	for _, item := range checklist {
+		item.Text = strings.TrimSpace(item.Text)
+		result = append(result, item)
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChecklist(t *testing.T) {
+	parsed, err := ParseHumanInput("add checklist:buy_milk,call_bob,, groceries")
+	if err != nil {
+		t.Fatalf("cant parse input: %s", err)
+	}
+	task := NewTask()
+	parsed.Options.ModifyTask(task)
+	if task.Description != "groceries" || len(task.Checklist) != 2 || task.Checklist[0].Text != "buy milk" {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+	if err := task.CheckChecklistItem(1, true); err != nil {
+		t.Fatalf("cant check item: %s", err)
+	}
+	if err := task.CheckChecklistItem(2, true); err == nil {
+		t.Errorf("unknown item should be rejected")
+	}
+	if progress := task.ChecklistProgress(); progress != "1/2" {
+		t.Errorf("unexpected progress: %s", progress)
+	}
+
+	text := task.ChecklistText()
+	if text != "[ ] buy milk\n[x] call bob" {
+		t.Errorf("unexpected checklist text: %q", text)
+	}
+	if items := ParseChecklistText(text + "\n\n[X] pay\nwash car"); !reflect.DeepEqual(items, []ChecklistItem{
+		{Text: "buy milk"},
+		{Text: "call bob", Checked: true},
+		{Text: "pay", Checked: true},
+		{Text: "wash car"},
+	}) {
+		t.Errorf("unexpected parsed checklist: %+v", items)
+	}
+
+	next := task.Clone(true)
+	if next.ChecklistProgress() != "0/2" || task.ChecklistProgress() != "1/2" {
+		t.Errorf("copy should start with unchecked items")
+	}
+}
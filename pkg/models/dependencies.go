@@ -0,0 +1,38 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+func (t *Task) IsBlocked() bool {
+	return len(t.BlockedBy) > 0
+}
+
+// ResolveBlockers fills BlockedBy with pending dependencies of tasks.
+// Dependencies out of the tasks list are fetched from the repository, not found dependencies do not block.
+func ResolveBlockers(ctx context.Context, repo Repository, tasks ...*Task) error {
+	known := map[uuid.UUID]*Task{}
+	for _, task := range tasks {
+		known[task.UUID] = task
+	}
+	for _, task := range tasks {
+		task.BlockedBy = nil
+		for _, dependency := range task.Depends {
+			blocker, ok := known[dependency]
+			if !ok {
+				var err error
+				blocker, err = repo.Get(ctx, dependency)
+				if err != nil {
+					return fmt.Errorf("cant get dependency %s: %w", dependency, err)
+				}
+				known[dependency] = blocker
+			}
+			if blocker != nil && blocker.Status.IsOpen() {
+				task.BlockedBy = append(task.BlockedBy, blocker)
+			}
+		}
+	}
+	return nil
+}
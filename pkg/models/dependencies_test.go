@@ -0,0 +1,42 @@
+package models
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+func TestResolveBlockers(t *testing.T) {
+	due := time.Now()
+	release := NewTask()
+	release.Description = "release"
+	release.Due = &due
+	build := NewTask()
+	build.Description = "build"
+	test := NewTask()
+	test.Description = "test"
+	test.Status = Completed
+	release.Depends = []uuid.UUID{build.UUID, test.UUID}
+
+	if err := ResolveBlockers(context.Background(), nil, release, build, test); err != nil {
+		t.Fatalf("ResolveBlockers() error = %v", err)
+	}
+	if len(release.BlockedBy) != 1 || release.BlockedBy[0] != build {
+		t.Errorf("release should be blocked by build only, have %+v", release.BlockedBy)
+	}
+	if today := Agenda([]*Task{release, build}); len(today[0].Tasks) != 0 {
+		t.Errorf("blocked task should not be in today agenda, have %+v", today[0].Tasks)
+	}
+
+	build.Status = Completed
+	if err := ResolveBlockers(context.Background(), nil, release, build, test); err != nil {
+		t.Fatalf("ResolveBlockers() error = %v", err)
+	}
+	if release.IsBlocked() {
+		t.Errorf("release should not be blocked, have %+v", release.BlockedBy)
+	}
+	if today := Agenda([]*Task{release}); len(today[0].Tasks) != 1 {
+		t.Errorf("unblocked task should be in today agenda, have %+v", today[0].Tasks)
+	}
+}
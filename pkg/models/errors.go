@@ -0,0 +1,33 @@
+package models
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+)
+
+var (
+	TaskConflictError   = fmt.Errorf("task conflict")
+	TaskParentError     = fmt.Errorf("invalid task parent")
+	TaskChangesError    = fmt.Errorf("invalid task changes")
+	TaskTrackingError   = fmt.Errorf("invalid time tracking")
+	TaskAttachmentError = fmt.Errorf("invalid attachment")
+	TaskPermissionError = fmt.Errorf("permission denied")
+	NoUserError         = fmt.Errorf("no user in context")
+)
+
+type TaskRevisionConflictError struct {
+	UUID     uuid.UUID `json:"uuid"`
+	Expected int64     `json:"expected"`
+	Actual   int64     `json:"actual"`
+}
+
+func (e *TaskRevisionConflictError) Error() string {
+	return fmt.Sprintf(
+		"%s: task %s was changed concurrently: expected revision %d, actual %d",
+		TaskConflictError, e.UUID, e.Expected, e.Actual,
+	)
+}
+
+func (e *TaskRevisionConflictError) Unwrap() error {
+	return TaskConflictError
+}
@@ -1,15 +1,119 @@
 package models
 
 import (
+	"fmt"
+	"github.com/google/uuid"
 	"slices"
 	"strings"
+	"time"
 )
 
+type TaskOrder string
+
+const (
+	OrderDefault TaskOrder = ""
+	OrderUrgency TaskOrder = "urgency"
+	// OrderCompleted and OrderModified put the most recent tasks first.
+	OrderCompleted TaskOrder = "completed"
+	OrderModified  TaskOrder = "modified"
+)
+
+func ParseTaskOrder(value string) (TaskOrder, error) {
+	switch TaskOrder(value) {
+	case OrderDefault, OrderUrgency, OrderCompleted, OrderModified:
+		return TaskOrder(value), nil
+	}
+	return OrderDefault, fmt.Errorf("unknown order: %s", value)
+}
+
+// Page selects a window of sorted tasks, zero Limit means no limit.
+// Order switches sorting from SortTasks, see SortTasksByOrder.
+type Page struct {
+	Offset int
+	Limit  int
+	Order  TaskOrder
+}
+
+type FindResult struct {
+	Tasks []*Task `json:"tasks"`
+	Total int     `json:"total"`
+}
+
+// FindInTasks is the in-process implementation of Repository.Find for backends which keep all tasks in memory.
+func FindInTasks(tasks []*Task, filter *ListFilter, page Page) *FindResult {
+	tasks = filter.Apply(tasks)
+	SortTasksByOrder(tasks, page.Order, time.Now())
+	total := len(tasks)
+	tasks = tasks[min(max(page.Offset, 0), total):]
+	if page.Limit > 0 {
+		tasks = tasks[:min(page.Limit, len(tasks))]
+	}
+	return &FindResult{Tasks: tasks, Total: total}
+}
+
+func SortTasksByOrder(tasks []*Task, order TaskOrder, now time.Time) {
+	switch order {
+	case OrderUrgency:
+		SortTasksByUrgency(tasks, now)
+	case OrderCompleted:
+		sortTasksByTime(tasks, func(task *Task) *time.Time { return task.CompletedAt })
+	case OrderModified:
+		sortTasksByTime(tasks, func(task *Task) *time.Time { return task.ModifiedAt })
+	default:
+		SortTasks(tasks)
+	}
+}
+
+// sortTasksByTime puts the most recent tasks first and tasks without time last, ties are ordered as in SortTasks.
+func sortTasksByTime(tasks []*Task, getTime func(task *Task) *time.Time) {
+	slices.SortFunc(tasks, func(a, b *Task) int {
+		aTime, bTime := getTime(a), getTime(b)
+		switch {
+		case aTime != nil && bTime != nil && !aTime.Equal(*bTime):
+			return bTime.Compare(*aTime)
+		case aTime != nil && bTime == nil:
+			return -1
+		case aTime == nil && bTime != nil:
+			return 1
+		}
+		return compareTasks(a, b)
+	})
+}
+
+// TimeRange selects times within [After, Before), nil bound means unbounded.
+type TimeRange struct {
+	After  *time.Time
+	Before *time.Time
+}
+
+func (r TimeRange) IsEmpty() bool {
+	return r.After == nil && r.Before == nil
+}
+
+// Contains reports whether the time is in the range, nil time is only in the empty range.
+func (r TimeRange) Contains(date *time.Time) bool {
+	if r.IsEmpty() {
+		return true
+	}
+	if date == nil {
+		return false
+	}
+	if r.After != nil && date.Before(*r.After) {
+		return false
+	}
+	if r.Before != nil && !date.Before(*r.Before) {
+		return false
+	}
+	return true
+}
+
 func NewDefaultListFilter() *ListFilter {
 	return &ListFilter{
 		ShowPending:   true,
 		ShowDeleted:   false,
 		ShowCompleted: false,
+		ShowWaiting:   false,
+		ShowArchived:  false,
 		Tags:          nil,
 		SearchWords:   nil,
 		Project:       "",
@@ -17,23 +121,71 @@ func NewDefaultListFilter() *ListFilter {
 }
 
 type ListFilter struct {
+	// ShowPending, ShowCompleted and ShowDeleted show statuses of open, closed and removed categories.
 	ShowPending   bool
 	ShowDeleted   bool
 	ShowCompleted bool
-	Tags          []string
-	SearchWords   []string
-	Project       string
+	// Status narrows tasks to the single status, empty for any.
+	Status taskStatus
+	// Scopes narrow tasks to the ones visible to the user, nil for tasks of all users.
+	Scopes []TaskScope
+	// ShowWaiting shows tasks whose wait date has not passed yet.
+	ShowWaiting bool
+	// ShowArchived shows tasks of archived projects, repositories hide them by Archived otherwise.
+	ShowArchived bool
+	// Archived are scopes of archived projects, repositories fill them from stored projects, see ArchivedScopes.
+	Archived    []TaskScope
+	Tags        []string
+	SearchWords []string
+	Project     string
+	Parent      *uuid.UUID
+	Completed   TimeRange
+	Modified    TimeRange
+}
+
+// Statuses returns known statuses shown by the filter, tasks with statuses removed from the config are not shown.
+func (filter *ListFilter) Statuses() []taskStatus {
+	if len(filter.Status) > 0 {
+		return []taskStatus{filter.Status}
+	}
+	categories := []StatusCategory{}
+	if filter.ShowPending {
+		categories = append(categories, StatusCategoryOpen)
+	}
+	if filter.ShowCompleted {
+		categories = append(categories, StatusCategoryClosed)
+	}
+	if filter.ShowDeleted {
+		categories = append(categories, StatusCategoryRemoved)
+	}
+	return StatusesOf(categories...)
 }
 
 func (filter *ListFilter) Apply(tasks []*Task) []*Task {
+	now := time.Now()
+	statuses := filter.Statuses()
 	return slices.DeleteFunc(tasks, func(task *Task) bool {
-		if !filter.ShowDeleted && task.Status == Deleted {
+		if !slices.Contains(statuses, task.Status) {
+			return true
+		}
+
+		if !filter.ShowWaiting && task.IsWaiting(now) {
+			return true
+		}
+
+		if !filter.Completed.Contains(task.CompletedAt) || !filter.Modified.Contains(task.ModifiedAt) {
+			return true
+		}
+
+		if filter.Scopes != nil && !slices.ContainsFunc(filter.Scopes, func(scope TaskScope) bool { return scope.Contains(task) }) {
 			return true
 		}
-		if !filter.ShowCompleted && task.Status == Completed {
+
+		if !filter.ShowArchived && slices.ContainsFunc(filter.Archived, func(scope TaskScope) bool { return scope.Contains(task) }) {
 			return true
 		}
-		if !filter.ShowPending && task.Status == Pending {
+
+		if filter.Parent != nil && (task.Parent == nil || *task.Parent != *filter.Parent) {
 			return true
 		}
 
@@ -0,0 +1,184 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListFilterToQuery encodes the filter to url params of the web list and the api, see QueryToListFilter.
+func ListFilterToQuery(filter *ListFilter) url.Values {
+	query := url.Values{}
+	if !filter.ShowPending {
+		query.Add("hide_pending", "true")
+	}
+	if filter.ShowDeleted {
+		query.Add("show_deleted", "true")
+	}
+	if filter.ShowCompleted {
+		query.Add("show_completed", "true")
+	}
+	if filter.ShowWaiting {
+		query.Add("show_waiting", "true")
+	}
+	if filter.ShowArchived {
+		query.Add("show_archived", "true")
+	}
+	if len(filter.Status) > 0 {
+		query.Add("status", filter.Status.String())
+	}
+	if filter.Project != "" {
+		query.Add("project", filter.Project)
+	}
+	for _, tag := range filter.Tags {
+		query.Add("tags", tag)
+	}
+	for _, word := range filter.SearchWords {
+		query.Add("search_words", word)
+	}
+	if filter.Parent != nil {
+		query.Add("parent", filter.Parent.String())
+	}
+	timeRangeToQuery(query, "completed", filter.Completed)
+	timeRangeToQuery(query, "modified", filter.Modified)
+	return query
+}
+
+// listFilterQueryKeys are params of QueryToListFilter.
+var listFilterQueryKeys = []string{
+	"all", "show_deleted", "show_completed", "show_waiting", "show_archived", "hide_pending", "status",
+	"project", "tags", "search_words", "parent", "completed_after", "completed_before", "modified_after", "modified_before",
+}
+
+// HasListFilterQuery reports whether the query sets the filter, the context is applied only without it.
+func HasListFilterQuery(query url.Values) bool {
+	for _, key := range listFilterQueryKeys {
+		if query.Has(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func QueryToListFilter(query url.Values) *ListFilter {
+	if query.Has("all") {
+		return &ListFilter{
+			ShowPending:   true,
+			ShowDeleted:   true,
+			ShowCompleted: true,
+			ShowWaiting:   true,
+			ShowArchived:  true,
+			Tags:          nil,
+			SearchWords:   nil,
+			Project:       "",
+		}
+	}
+	filter := &ListFilter{
+		ShowDeleted:   query.Has("show_deleted"),
+		ShowCompleted: query.Has("show_completed"),
+		ShowWaiting:   query.Has("show_waiting"),
+		ShowArchived:  query.Has("show_archived"),
+		ShowPending:   !query.Has("hide_pending"),
+		Tags:          nil,
+		SearchWords:   nil,
+		Project:       query.Get("project"),
+	}
+	if query.Has("tags") {
+		for _, tag := range query["tags"] {
+			tag = strings.TrimSpace(tag)
+			if len(tag) == 0 {
+				continue
+			}
+			for _, sub := range strings.Split(tag, ",") {
+				sub = strings.TrimSpace(sub)
+				if len(sub) == 0 {
+					continue
+				}
+				filter.Tags = append(filter.Tags, sub)
+			}
+		}
+	}
+	if parent, err := uuid.Parse(query.Get("parent")); err == nil {
+		filter.Parent = &parent
+	}
+	if status, err := NewTaskStatus(query.Get("status")); err == nil {
+		filter.Status = status
+	}
+	filter.Completed = queryToTimeRange(query, "completed")
+	if !filter.Completed.IsEmpty() {
+		// only completed tasks have completion time
+		filter.ShowCompleted = true
+	}
+	filter.Modified = queryToTimeRange(query, "modified")
+	if query.Has("search_words") {
+		for _, word := range query["search_words"] {
+			word = strings.TrimSpace(word)
+			if len(word) == 0 {
+				continue
+			}
+			for _, sub := range strings.Split(word, " ") {
+				sub = strings.TrimSpace(sub)
+				if len(sub) == 0 {
+					continue
+				}
+				filter.SearchWords = append(filter.SearchWords, sub)
+			}
+		}
+	}
+
+	return filter
+}
+
+func QueryToPage(query url.Values, page Page) Page {
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset > 0 {
+		page.Offset = offset
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit >= 0 {
+		page.Limit = limit
+	}
+	if order, err := ParseTaskOrder(query.Get("order")); err == nil && query.Has("order") {
+		page.Order = order
+	}
+	return page
+}
+
+func PageToQuery(query url.Values, page Page) url.Values {
+	if page.Offset > 0 {
+		query.Set("offset", strconv.Itoa(page.Offset))
+	}
+	if page.Limit > 0 {
+		query.Set("limit", strconv.Itoa(page.Limit))
+	}
+	if page.Order != OrderDefault {
+		query.Set("order", string(page.Order))
+	}
+	return query
+}
+
+func timeRangeToQuery(query url.Values, name string, timeRange TimeRange) {
+	if timeRange.After != nil {
+		query.Add(name+"_after", timeRange.After.Format(time.RFC3339))
+	}
+	if timeRange.Before != nil {
+		query.Add(name+"_before", timeRange.Before.Format(time.RFC3339))
+	}
+}
+
+// queryToTimeRange accepts RFC3339 or a local date, invalid bounds are ignored like other filter values.
+func queryToTimeRange(query url.Values, name string) TimeRange {
+	parse := func(value string) *time.Time {
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			return &parsed
+		}
+		if parsed, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+			return &parsed
+		}
+		return nil
+	}
+	return TimeRange{
+		After:  parse(query.Get(name + "_after")),
+		Before: parse(query.Get(name + "_before")),
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestListFilterQuery(t *testing.T) {
+	parent := uuid.New()
+	after := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+	filter := &ListFilter{
+		ShowPending:   true,
+		ShowCompleted: true,
+		ShowArchived:  true,
+		Status:        Completed,
+		Tags:          []string{"urgent", "office"},
+		SearchWords:   []string{"write", "report"},
+		Project:       "work",
+		Parent:        &parent,
+		Completed:     TimeRange{After: &after},
+	}
+	query := ListFilterToQuery(filter)
+	if !HasListFilterQuery(query) {
+		t.Errorf("query should set the filter: %s", query.Encode())
+	}
+	if decoded := QueryToListFilter(query); !reflect.DeepEqual(decoded, filter) {
+		t.Errorf("filter should be decoded from the query:\n%+v\n%+v", decoded, filter)
+	}
+
+	page := Page{Offset: 50, Limit: 25, Order: OrderUrgency}
+	query = PageToQuery(query, page)
+	if decoded := QueryToPage(query, Page{}); decoded != page {
+		t.Errorf("page should be decoded from the query: %+v", decoded)
+	}
+	if HasListFilterQuery(PageToQuery(ListFilterToQuery(NewDefaultListFilter()), page)) {
+		t.Errorf("default filter and page should not set the filter")
+	}
+}
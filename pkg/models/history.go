@@ -0,0 +1,119 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+	"time"
+)
+
+type TaskFieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+type TaskHistoryEntry struct {
+	TaskUUID uuid.UUID         `json:"task_uuid"`
+	At       time.Time         `json:"at"`
+	Actor    string            `json:"actor,omitempty"`
+	Changes  []TaskFieldChange `json:"changes"`
+}
+
+var historyIgnoredFields = []string{"uuid", "modified_by", "revision", "modified_at", "completed_at"}
+
+// NewTaskHistoryEntry describes the revision from previous to current, previous is nil for new tasks.
+// It returns nil if nothing has been changed.
+func NewTaskHistoryEntry(previous *Task, current *Task, at time.Time) (*TaskHistoryEntry, error) {
+	changes, err := DiffTasks(previous, current)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return &TaskHistoryEntry{
+		TaskUUID: current.UUID,
+		At:       at,
+		Actor:    current.ModifiedBy,
+		Changes:  changes,
+	}, nil
+}
+
+func DiffTasks(previous *Task, current *Task) ([]TaskFieldChange, error) {
+	previousFields, err := taskFields(previous)
+	if err != nil {
+		return nil, err
+	}
+	currentFields, err := taskFields(current)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for name := range previousFields {
+		names = append(names, name)
+	}
+	for name := range currentFields {
+		if _, ok := previousFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	changes := []TaskFieldChange{}
+	for _, name := range names {
+		if slices.Contains(historyIgnoredFields, name) {
+			continue
+		}
+		if previousFields[name] == currentFields[name] {
+			continue
+		}
+		changes = append(changes, TaskFieldChange{
+			Field: name,
+			Old:   previousFields[name],
+			New:   currentFields[name],
+		})
+	}
+	return changes, nil
+}
+
+func taskFields(task *Task) (map[string]string, error) {
+	result := map[string]string{}
+	if task == nil {
+		return result, nil
+	}
+	data, err := json.Marshal(task)
+	if err != nil {
+		return nil, fmt.Errorf("cant marshal task: %w", err)
+	}
+	fields := map[string]any{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("cant unmarshal task: %w", err)
+	}
+	for name, value := range fields {
+		result[name] = formatHistoryValue(value)
+	}
+	return result, nil
+}
+
+func formatHistoryValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		items := []string{}
+		for _, item := range v {
+			items = append(items, formatHistoryValue(item))
+		}
+		return strings.Join(items, ", ")
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffTasks(t *testing.T) {
+	createdAt := time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+	annotatedAt := time.Date(2024, 8, 2, 10, 0, 0, 0, time.UTC)
+	base := func() *Task {
+		return &Task{
+			UUID:        uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+			Description: "write report",
+			Status:      Pending,
+			CreatedAt:   createdAt,
+			Tags:        []string{"office"},
+			Revision:    1,
+		}
+	}
+	tests := []struct {
+		name     string
+		previous *Task
+		modify   func(task *Task)
+		want     []TaskFieldChange
+	}{
+		{
+			name:     "new task",
+			previous: nil,
+			modify:   func(task *Task) {},
+			want: []TaskFieldChange{
+				{Field: "created_at", New: "2024-08-01T10:00:00Z"},
+				{Field: "description", New: "write report"},
+				{Field: "status", New: "pending"},
+				{Field: "tags", New: "office"},
+			},
+		},
+		{
+			name:     "changed fields",
+			previous: base(),
+			modify: func(task *Task) {
+				task.Description = "write the report"
+				task.Status = Completed
+				task.Project = "work"
+			},
+			want: []TaskFieldChange{
+				{Field: "description", Old: "write report", New: "write the report"},
+				{Field: "project", New: "work"},
+				{Field: "status", Old: "pending", New: "completed"},
+			},
+		},
+		{
+			name:     "ignored fields",
+			previous: base(),
+			modify: func(task *Task) {
+				now := time.Now()
+				task.UUID = uuid.New()
+				task.ModifiedBy = "alice"
+				task.Revision = 2
+				task.ModifiedAt = &now
+				task.CompletedAt = &now
+			},
+			want: []TaskFieldChange{},
+		},
+		{
+			name:     "slice fields",
+			previous: base(),
+			modify: func(task *Task) {
+				task.Tags = []string{"office", "urgent"}
+				task.Checklist = []ChecklistItem{{Text: "draft", Checked: true}}
+			},
+			want: []TaskFieldChange{
+				{Field: "checklist", New: `{"checked":true,"text":"draft"}`},
+				{Field: "tags", Old: "office", New: "office, urgent"},
+			},
+		},
+		{
+			name:     "nested fields",
+			previous: base(),
+			modify: func(task *Task) {
+				task.Annotations = []TaskAnnotation{{At: annotatedAt, Text: "asked bob"}}
+				task.UDA = map[string]string{"size": "M"}
+			},
+			want: []TaskFieldChange{
+				{Field: "annotations", New: `{"at":"2024-08-02T10:00:00Z","text":"asked bob"}`},
+				{Field: "uda", New: `{"size":"M"}`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := base()
+			tt.modify(current)
+			got, err := DiffTasks(tt.previous, current)
+			if err != nil {
+				t.Fatalf("DiffTasks() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffTasks() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTaskHistoryEntry(t *testing.T) {
+	at := time.Now()
+	previous := NewTask()
+	previous.Description = "write report"
+	current := previous.Clone(false)
+	current.ModifiedBy = "alice"
+	current.Revision++
+	if entry, err := NewTaskHistoryEntry(previous, current, at); err != nil || entry != nil {
+		t.Errorf("revision without changes should not have history entry, have %+v, %v", entry, err)
+	}
+	current.Priority = PriorityHigh
+	entry, err := NewTaskHistoryEntry(previous, current, at)
+	if err != nil {
+		t.Fatalf("NewTaskHistoryEntry() error = %v", err)
+	}
+	if entry.TaskUUID != current.UUID || entry.Actor != "alice" || !entry.At.Equal(at) ||
+		len(entry.Changes) != 1 || entry.Changes[0].Field != "priority" {
+		t.Errorf("unexpected history entry: %+v", entry)
+	}
+}
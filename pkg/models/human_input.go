@@ -35,20 +35,67 @@ ACTIONS
     copy UUID
         Create new task with copy fields from UUID.
 
-    done UUID
-        Set status completed for task by the given UUID.
+    done UUID [subtasks:complete]
+        Set status completed for task by the given UUID. For recurring task the next occurrence is created.
+        Pending subtasks are reported, with subtasks:complete they are completed too.
 
     agenda
         Show tasks that have due today, next 7 day and overdue
 
+    annotate UUID TEXT...
+        Adds a timestamped note to the task by the given UUID. The whole TEXT is kept as is, options are not parsed.
+        Example: annotate 123e4567-e89b-12d3-a456-426614174000 waiting for review from the team
+
+    start UUID
+        Starts time tracking of the task by the given UUID. Only one task is tracked at a time,
+        the previously started task is stopped.
+
+    stop UUID
+        Stops time tracking of the task by the given UUID.
+
+    history UUID
+        Show changes of the task by the given UUID: when, who and which fields were changed.
+
+    from-template NAME [options...]
+        Creates the task with subtasks from the template of the config. Options and words override
+        fields of the created task, the same as add with template:NAME.
+        Example: from-template onboarding project:hiring onboard Alice
+
+    rename-project OLD NEW [preview]
+        Renames the project OLD with its subprojects to NEW in all tasks and project settings at once.
+        If the project NEW exists, tasks are merged into it and its settings are kept.
+        With preview only the number of affected tasks is shown.
+        Example: rename-project work job preview
+
+    rename-tag OLD NEW [preview]
+        Renames the tag OLD to NEW in all tasks and default tags of projects, merging it with the existing tag NEW.
+        Example: rename-tag urgent important
+
+    filters
+        Shows saved filters, the active one is the context.
+
+    save-filter NAME [options...]
+        Saves options of list action as the named filter, the filter with the same name is replaced.
+        Example: save-filter work-urgent project:work +urgent
+
+    delete-filter NAME
+        Deletes the saved filter.
+
+    context NAME
+        Sets the saved filter as the context, it is applied by default to list and agenda, the main page
+        and the daily agenda. Use none to disable the context.
+        Example: context work-urgent
+
 OPTIONS
     project:PROJECT_NAME
         Specifies the project name associated with the task. 
         Example: project:MyProject
 
     status:STATUS
-        Sets the task's status. Valid values include pending, completed, deleted.
-        Example: status:pending
+        Sets the task's status. Valid values include pending, completed, deleted and statuses of the config,
+        each status is open, closed or removed like pending, completed and deleted. For list action
+        shows tasks with the status only.
+        Example: status:in-progress
 
     +TAG
         Adds a tag to the task. Multiple tags can be added by repeating this option with different tags.
@@ -82,9 +129,78 @@ OPTIONS
 			+1h, -30m
         Example: notify:2024-08-15T12:00:00
 
+    wait:TIME
+        Hides the task from lists and agenda until TIME, formats are the same as for due.
+        Use empty value to show the task again. For list action shows waiting tasks too.
+        Example: wait:2024-08-10
+
+    scheduled:TIME
+        Sets the day when you plan to work on the task, it is shown in agenda for today on that day
+        even without due. Formats are the same as for due. Use empty value to remove it.
+        Example: scheduled:2024-08-12
+
+    recur:PERIOD
+        Makes the task recurring. When it is completed, a new pending task is created with due and notify
+        shifted by the period. The task should have due or notify. PERIOD can be in formats:
+		Named period:
+			daily, weekly, biweekly, monthly, quarterly, yearly
+		Counted period (d - days, w - weeks, m - months, y - years):
+			2d, 3w, 6m, 1y
+		RFC 5545 RRULE subset:
+			FREQ=WEEKLY;INTERVAL=2
+        Use empty value to stop recurrence.
+        Example: recur:weekly
+
+    priority:PRIORITY
+        Sets the task priority: H (high), M (medium) or L (low). Priority raises the task urgency,
+        which orders agenda, the main list and the telegram shortlist. Use empty value to remove priority.
+        Example: priority:H
+
+    completed.after:TIME, completed.before:TIME, modified.after:TIME, modified.before:TIME
+        For list action shows tasks completed or modified within the range, formats are the same as for due.
+        With completed range and without status only closed tasks are shown.
+        Example: list completed.after:-168h
+
+    sort:ORDER
+        For list action sets the order of tasks: urgency, completed or modified (the most recent first).
+        Example: list sort:completed
+
+    parent:UUID
+        Makes the task a subtask of the task by the given UUID. Use empty value to detach the task.
+        For list action shows only subtasks of the given UUID.
+        Example: parent:123e4567-e89b-12d3-a456-426614174000
+
+    depends:UUID[,UUID]
+        Sets tasks which should be completed before this one. Blocked task is not shown in agenda for today
+        and its notification is deferred until dependencies are completed. Use empty value to remove dependencies.
+        Example: depends:123e4567-e89b-12d3-a456-426614174000
+
+    checklist:ITEM[,ITEM]
+        Adds items to the checklist of the task, underscores in items are replaced with spaces.
+        Example: checklist:buy_milk,call_bob
+
+    template:NAME
+        For add action creates the task with subtasks from the template NAME of the config.
+        Example: add template:release release 1.2
+
+    subtasks:complete
+        Completes pending subtasks together with the task, when it becomes completed.
+
+    context:NAME
+        For list and agenda actions applies the saved filter NAME instead of the context, use none to list
+        without the context. Other options are combined with the saved filter.
+        Example: list context:none +urgent
+
+    NAME:VALUE
+        Sets the user defined attribute NAME from the uda section of the config. Value is checked by the
+        attribute type: string, number, date (formats are the same as for due) or one of enum values.
+        Use empty value to remove the attribute.
+        Example: estimate:2.5
+
     ExtraWords...
         Any additional words or phrases will be added to the task's description.
-		For list action words used as search words.
+		For list action words used as search words. Line breaks are kept, so the description
+		can be multi-line markdown: **bold**, inline code in backticks, [links](https://example.com) and "- " lists.
         Example: prepare quarterly report
 
 EXAMPLES
@@ -107,15 +223,32 @@ EXAMPLES
 type HumanAction string
 
 const (
-	HumanActionList   HumanAction = "list"
-	HumanActionAdd    HumanAction = "add"
-	HumanActionModify HumanAction = "modify"
-	HumanActionInfo   HumanAction = "info"
-	HumanActionCopy   HumanAction = "copy"
-	HumanActionDone   HumanAction = "done"
-	HumanActionAgenda HumanAction = "agenda"
+	HumanActionList     HumanAction = "list"
+	HumanActionAdd      HumanAction = "add"
+	HumanActionModify   HumanAction = "modify"
+	HumanActionInfo     HumanAction = "info"
+	HumanActionCopy     HumanAction = "copy"
+	HumanActionDone     HumanAction = "done"
+	HumanActionAgenda   HumanAction = "agenda"
+	HumanActionHistory  HumanAction = "history"
+	HumanActionAnnotate HumanAction = "annotate"
+	HumanActionStart    HumanAction = "start"
+	HumanActionStop     HumanAction = "stop"
+
+	HumanActionFromTemplate  HumanAction = "from-template"
+	HumanActionRenameProject HumanAction = "rename-project"
+	HumanActionRenameTag     HumanAction = "rename-tag"
+	HumanActionFilters       HumanAction = "filters"
+	HumanActionSaveFilter    HumanAction = "save-filter"
+	HumanActionDeleteFilter  HumanAction = "delete-filter"
+	HumanActionContext       HumanAction = "context"
 )
 
+var humanActionsWithUUID = []HumanAction{
+	HumanActionModify, HumanActionInfo, HumanActionCopy, HumanActionDone, HumanActionHistory,
+	HumanActionAnnotate, HumanActionStart, HumanActionStop,
+}
+
 type HumanInputParserResult struct {
 	Action     HumanAction
 	ActionUUID *uuid.UUID
@@ -129,11 +262,34 @@ type AddOrDeleteValue[T any] struct {
 }
 
 type HumanInputOptions struct {
-	Project AddOrDeleteValue[string]
-	Tags    []AddOrDeleteValue[string]
-	Notify  AddOrDeleteValue[time.Time]
-	Due     AddOrDeleteValue[time.Time]
-	Status  *taskStatus
+	Project   AddOrDeleteValue[string]
+	Tags      []AddOrDeleteValue[string]
+	Notify    AddOrDeleteValue[time.Time]
+	Due       AddOrDeleteValue[time.Time]
+	Wait      AddOrDeleteValue[time.Time]
+	Scheduled AddOrDeleteValue[time.Time]
+	Status    *taskStatus
+	Recur     AddOrDeleteValue[string]
+	Priority  AddOrDeleteValue[TaskPriority]
+	Parent    AddOrDeleteValue[uuid.UUID]
+	Depends   AddOrDeleteValue[[]uuid.UUID]
+	UDA       map[string]AddOrDeleteValue[string]
+	Checklist []string
+
+	CompleteSubtasks bool
+	// Template is the name of the template for new tasks.
+	Template  string
+	Completed TimeRange
+	Modified  TimeRange
+	Order     *TaskOrder
+	// Annotation is the text of annotate action.
+	Annotation string
+	// Rename is the rename of rename-project and rename-tag actions.
+	Rename *Rename
+	// Context is the name of the saved filter for list, agenda and context actions, see ContextSavedFilter.
+	Context string
+	// SavedFilter is the saved filter of save-filter and delete-filter actions.
+	SavedFilter *SavedFilter
 
 	ExtraWords []string
 }
@@ -162,8 +318,66 @@ func (o *HumanInputOptions) ModifyTask(task *Task) {
 			task.Due = nil
 		}
 	}
+	if o.Wait.IsExists {
+		if o.Wait.IsAdd {
+			wait := o.Wait.Value
+			task.Wait = &wait
+		} else {
+			task.Wait = nil
+		}
+	}
+	if o.Scheduled.IsExists {
+		if o.Scheduled.IsAdd {
+			scheduled := o.Scheduled.Value
+			task.Scheduled = &scheduled
+		} else {
+			task.Scheduled = nil
+		}
+	}
+	if o.Recur.IsExists {
+		if o.Recur.IsAdd {
+			task.Recur = o.Recur.Value
+		} else {
+			task.Recur = ""
+		}
+	}
+	if o.Priority.IsExists {
+		if o.Priority.IsAdd {
+			task.Priority = o.Priority.Value
+		} else {
+			task.Priority = ""
+		}
+	}
+	if o.Parent.IsExists {
+		if o.Parent.IsAdd {
+			parent := o.Parent.Value
+			task.Parent = &parent
+		} else {
+			task.Parent = nil
+		}
+	}
+	if o.Depends.IsExists {
+		if o.Depends.IsAdd {
+			task.Depends = slices.Clone(o.Depends.Value)
+		} else {
+			task.Depends = nil
+		}
+	}
+	for name, value := range o.UDA {
+		if value.IsAdd {
+			task.SetUDA(name, value.Value)
+		} else {
+			task.SetUDA(name, "")
+		}
+	}
+	for _, item := range o.Checklist {
+		task.AddChecklistItem(item)
+	}
+	if len(o.Annotation) > 0 {
+		task.Annotate(o.Annotation, time.Now())
+	}
 	if len(o.ExtraWords) > 0 {
-		task.Description = strings.Join(o.ExtraWords, " ")
+		task.Description = joinHumanInputWords(o.ExtraWords)
 	}
 	if len(o.Tags) > 0 {
 		taskTags := map[string]struct{}{}
@@ -192,12 +406,42 @@ func (o *HumanInputOptions) ModifyTask(task *Task) {
 	}
 }
 
+// NewTasks creates the task for add action, with template it also creates subtasks, the root task goes first.
+func (o *HumanInputOptions) NewTasks(now time.Time) ([]*Task, error) {
+	if len(o.Template) == 0 {
+		task := NewTask()
+		o.ModifyTask(task)
+		return []*Task{task}, nil
+	}
+	template := FindTaskTemplate(o.Template)
+	if template == nil {
+		return nil, fmt.Errorf("unknown template: %s", o.Template)
+	}
+	tasks := template.Instantiate(now)
+	o.ModifyTask(tasks[0])
+	return tasks, nil
+}
+
 func (o *HumanInputOptions) ToListFilter() *ListFilter {
 	filter := NewDefaultListFilter()
 	if o.Project.IsExists && o.Project.IsAdd {
+		// explicitly listed project is shown even if archived
 		filter.Project = o.Project.Value
+		filter.ShowArchived = true
+	}
+	if o.Wait.IsExists {
+		filter.ShowWaiting = true
+	}
+	filter.Completed = o.Completed
+	filter.Modified = o.Modified
+	if o.Parent.IsExists && o.Parent.IsAdd {
+		parent := o.Parent.Value
+		filter.Parent = &parent
 	}
 	for _, word := range o.ExtraWords {
+		if word == humanInputLineBreak {
+			continue
+		}
 		filter.SearchWords = append(filter.SearchWords, strings.ToLower(word))
 	}
 	for _, tag := range o.Tags {
@@ -208,13 +452,26 @@ func (o *HumanInputOptions) ToListFilter() *ListFilter {
 	}
 
 	if o.Status != nil {
-		filter.ShowPending = *o.Status == Pending
-		filter.ShowCompleted = *o.Status == Completed
-		filter.ShowDeleted = *o.Status == Deleted
+		filter.Status = *o.Status
+		filter.ShowPending = o.Status.IsOpen()
+		filter.ShowCompleted = o.Status.IsClosed()
+		filter.ShowDeleted = o.Status.IsRemoved()
+	} else if !o.Completed.IsEmpty() {
+		filter.ShowPending = false
+		filter.ShowCompleted = true
 	}
 	return filter
 }
 
+// ToPage returns the page for list action, defaultOrder is used without sort option.
+func (o *HumanInputOptions) ToPage(defaultOrder TaskOrder, limit int) Page {
+	page := Page{Order: defaultOrder, Limit: limit}
+	if o.Order != nil {
+		page.Order = *o.Order
+	}
+	return page
+}
+
 func ParseHumanInput(input string) (*HumanInputParserResult, error) {
 	input = strings.TrimSpace(input)
 	if len(input) == 0 {
@@ -229,14 +486,17 @@ func ParseHumanInput(input string) (*HumanInputParserResult, error) {
 	allActions := []HumanAction{
 		HumanActionAdd, HumanActionModify, HumanActionList,
 		HumanActionInfo, HumanActionCopy, HumanActionDone,
-		HumanActionAgenda,
+		HumanActionAgenda, HumanActionHistory, HumanActionAnnotate,
+		HumanActionStart, HumanActionStop, HumanActionFromTemplate,
+		HumanActionRenameProject, HumanActionRenameTag, HumanActionFilters,
+		HumanActionSaveFilter, HumanActionDeleteFilter, HumanActionContext,
 	}
 	if !slices.Contains(allActions, action) {
 		return nil, fmt.Errorf("invalid action: %s", input[:firstSpace])
 	}
 	result.Action = action
 	if firstSpace+1 >= len(input) {
-		if action == HumanActionList || action == HumanActionAgenda {
+		if action == HumanActionList || action == HumanActionAgenda || action == HumanActionFilters {
 			result.Options = HumanInputOptions{}
 			return result, nil
 		}
@@ -244,7 +504,7 @@ func ParseHumanInput(input string) (*HumanInputParserResult, error) {
 	}
 	input = input[firstSpace+1:]
 	input = strings.TrimSpace(input)
-	if action == HumanActionModify || action == HumanActionInfo || action == HumanActionCopy || action == HumanActionDone {
+	if slices.Contains(humanActionsWithUUID, action) {
 		secondSpace := strings.IndexFunc(input, unicode.IsSpace)
 		if secondSpace < 0 {
 			secondSpace = len(input)
@@ -257,27 +517,108 @@ func ParseHumanInput(input string) (*HumanInputParserResult, error) {
 		input = input[secondSpace:]
 	}
 	if action == HumanActionDone {
+		options, err := parseHumanOptions(input)
+		if err != nil {
+			return nil, fmt.Errorf("cant parse options: %w", err)
+		}
 		completedStatus := Completed
 		result.Options = HumanInputOptions{
-			Status: &completedStatus,
+			Status:           &completedStatus,
+			CompleteSubtasks: options.CompleteSubtasks,
+		}
+		return result, nil
+	}
+	if action == HumanActionAnnotate {
+		text := strings.TrimSpace(input)
+		if len(text) == 0 {
+			return nil, fmt.Errorf("annotation text required")
+		}
+		result.Options = HumanInputOptions{Annotation: text}
+		return result, nil
+	}
+	if action == HumanActionRenameProject || action == HumanActionRenameTag {
+		kind := RenameKindProject
+		if action == HumanActionRenameTag {
+			kind = RenameKindTag
+		}
+		words := strings.Fields(input)
+		if len(words) < 2 || len(words) > 3 || (len(words) == 3 && words[2] != "preview") {
+			return nil, fmt.Errorf("usage: %s OLD NEW [preview]", action)
 		}
+		rename, err := NewRename(kind, words[0], words[1])
+		if err != nil {
+			return nil, err
+		}
+		rename.DryRun = len(words) == 3
+		result.Options = HumanInputOptions{Rename: rename}
+		return result, nil
+	}
+	if action == HumanActionSaveFilter || action == HumanActionDeleteFilter || action == HumanActionContext {
+		name, query, _ := strings.Cut(input, " ")
+		if action == HumanActionContext {
+			if len(strings.TrimSpace(query)) > 0 {
+				return nil, fmt.Errorf("usage: context NAME")
+			}
+			result.Options = HumanInputOptions{Context: strings.ToLower(name)}
+			return result, nil
+		}
+		filter := &SavedFilter{Name: name, Query: query}
+		filter.Unify()
+		if action == HumanActionDeleteFilter && len(filter.Query) > 0 {
+			return nil, fmt.Errorf("usage: delete-filter NAME")
+		}
+		if err := filter.Validate(); err != nil {
+			return nil, err
+		}
+		result.Options = HumanInputOptions{SavedFilter: filter}
 		return result, nil
 	}
 	if action == HumanActionAgenda {
+		options, err := parseHumanOptions(input)
+		if err != nil {
+			return nil, fmt.Errorf("cant parse options: %w", err)
+		}
+		result.Options = HumanInputOptions{Context: options.Context}
+		return result, nil
+	}
+	if action == HumanActionFilters {
 		result.Options = HumanInputOptions{}
 		return result, nil
 	}
+	if action == HumanActionHistory || action == HumanActionStart || action == HumanActionStop {
+		result.Options = HumanInputOptions{}
+		return result, nil
+	}
+	templateName := ""
+	if action == HumanActionFromTemplate {
+		secondSpace := strings.IndexFunc(input, unicode.IsSpace)
+		if secondSpace < 0 {
+			secondSpace = len(input)
+		}
+		templateName = input[:secondSpace]
+		input = input[secondSpace:]
+	}
 	options, err := parseHumanOptions(input)
 	if err != nil {
 		return nil, fmt.Errorf("cant parse options: %w", err)
 	}
+	if len(templateName) > 0 {
+		if FindTaskTemplate(templateName) == nil {
+			return nil, fmt.Errorf("unknown template: %s", templateName)
+		}
+		options.Template = templateName
+	}
 	result.Options = *options
 	return result, nil
 }
 
 func parseHumanOptions(input string) (*HumanInputOptions, error) {
 	result := &HumanInputOptions{}
-	for _, word := range strings.Split(strings.TrimSpace(input), " ") {
+	for _, word := range splitHumanInputWords(input) {
+		if word == humanInputLineBreak {
+			result.ExtraWords = append(result.ExtraWords, word)
+			continue
+		}
 		word = strings.TrimSpace(word)
 		if len(word) == 0 {
 			continue
@@ -346,12 +687,214 @@ func parseHumanOptions(input string) (*HumanInputOptions, error) {
 			continue
 		}
 
+		if strings.HasPrefix(word, "wait:") {
+			wait := strings.TrimPrefix(word, "wait:")
+			waitValue := AddOrDeleteValue[time.Time]{IsExists: true, IsAdd: len(wait) > 0}
+			if waitValue.IsAdd {
+				timeValue, err := parseHumanInputTime(wait)
+				if err != nil {
+					return nil, fmt.Errorf("invalid wait: %w", err)
+				}
+				waitValue.Value = timeValue
+			}
+			result.Wait = waitValue
+			continue
+		}
+
+		if strings.HasPrefix(word, "scheduled:") {
+			scheduled := strings.TrimPrefix(word, "scheduled:")
+			scheduledValue := AddOrDeleteValue[time.Time]{IsExists: true, IsAdd: len(scheduled) > 0}
+			if scheduledValue.IsAdd {
+				timeValue, err := parseHumanInputTime(scheduled)
+				if err != nil {
+					return nil, fmt.Errorf("invalid scheduled: %w", err)
+				}
+				scheduledValue.Value = timeValue
+			}
+			result.Scheduled = scheduledValue
+			continue
+		}
+
+		timeRangeBounds := map[string]**time.Time{
+			"completed.after:":  &result.Completed.After,
+			"completed.before:": &result.Completed.Before,
+			"modified.after:":   &result.Modified.After,
+			"modified.before:":  &result.Modified.Before,
+		}
+		if prefix, bound := findTimeRangeBound(word, timeRangeBounds); bound != nil {
+			timeValue, err := parseHumanInputTime(strings.TrimPrefix(word, prefix))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %w", prefix, err)
+			}
+			*bound = &timeValue
+			continue
+		}
+
+		if strings.HasPrefix(word, "sort:") {
+			order, err := ParseTaskOrder(strings.TrimPrefix(word, "sort:"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid sort: %w", err)
+			}
+			result.Order = &order
+			continue
+		}
+
+		if strings.HasPrefix(word, "recur:") {
+			recur := strings.TrimPrefix(word, "recur:")
+			recurValue := AddOrDeleteValue[string]{IsExists: true, IsAdd: len(recur) > 0}
+			if recurValue.IsAdd {
+				recurrence, err := ParseRecurrence(recur)
+				if err != nil {
+					return nil, fmt.Errorf("invalid recur: %w", err)
+				}
+				recurValue.Value = recurrence.String()
+			}
+			result.Recur = recurValue
+			continue
+		}
+
+		if strings.HasPrefix(word, "priority:") {
+			priority, err := ParsePriority(strings.TrimPrefix(word, "priority:"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid priority: %w", err)
+			}
+			result.Priority = AddOrDeleteValue[TaskPriority]{IsExists: true, IsAdd: len(priority) > 0, Value: priority}
+			continue
+		}
+
+		if strings.HasPrefix(word, "parent:") {
+			parent := strings.TrimPrefix(word, "parent:")
+			parentValue := AddOrDeleteValue[uuid.UUID]{IsExists: true, IsAdd: len(parent) > 0}
+			if parentValue.IsAdd {
+				parentUUID, err := uuid.Parse(parent)
+				if err != nil {
+					return nil, fmt.Errorf("invalid parent: %w", err)
+				}
+				parentValue.Value = parentUUID
+			}
+			result.Parent = parentValue
+			continue
+		}
+
+		if strings.HasPrefix(word, "depends:") {
+			depends := strings.TrimPrefix(word, "depends:")
+			dependsValue := AddOrDeleteValue[[]uuid.UUID]{IsExists: true, IsAdd: len(depends) > 0}
+			for _, dependency := range strings.Split(depends, ",") {
+				if len(dependency) == 0 {
+					continue
+				}
+				dependencyUUID, err := uuid.Parse(dependency)
+				if err != nil {
+					return nil, fmt.Errorf("invalid depends: %w", err)
+				}
+				dependsValue.Value = append(dependsValue.Value, dependencyUUID)
+			}
+			result.Depends = dependsValue
+			continue
+		}
+
+		if strings.HasPrefix(word, "template:") {
+			name := strings.TrimPrefix(word, "template:")
+			if FindTaskTemplate(name) == nil {
+				return nil, fmt.Errorf("unknown template: %s", name)
+			}
+			result.Template = name
+			continue
+		}
+
+		if strings.HasPrefix(word, "checklist:") {
+			for _, item := range strings.Split(strings.TrimPrefix(word, "checklist:"), ",") {
+				item = strings.TrimSpace(strings.ReplaceAll(item, "_", " "))
+				if len(item) > 0 {
+					result.Checklist = append(result.Checklist, item)
+				}
+			}
+			continue
+		}
+
+		if strings.HasPrefix(word, "context:") {
+			result.Context = strings.ToLower(strings.TrimPrefix(word, "context:"))
+			continue
+		}
+
+		if word == "subtasks:complete" {
+			result.CompleteSubtasks = true
+			continue
+		}
+
+		if name, value, ok := strings.Cut(word, ":"); ok {
+			if definition := GetUDASchema().Lookup(name); definition != nil {
+				udaValue := AddOrDeleteValue[string]{IsExists: true, IsAdd: len(value) > 0}
+				if udaValue.IsAdd {
+					parsed, err := definition.Parse(value)
+					if err != nil {
+						return nil, fmt.Errorf("invalid %s: %w", name, err)
+					}
+					udaValue.Value = parsed
+				}
+				if result.UDA == nil {
+					result.UDA = map[string]AddOrDeleteValue[string]{}
+				}
+				result.UDA[name] = udaValue
+				continue
+			}
+		}
+
 		result.ExtraWords = append(result.ExtraWords, word)
 	}
+	result.ExtraWords = trimHumanInputLineBreaks(result.ExtraWords)
 
 	return result, nil
 }
 
+// humanInputLineBreak is kept in ExtraWords between lines of multi-line input.
+const humanInputLineBreak = "\n"
+
+func splitHumanInputWords(input string) []string {
+	result := []string{}
+	for i, line := range strings.Split(strings.TrimSpace(input), "\n") {
+		if i > 0 {
+			result = append(result, humanInputLineBreak)
+		}
+		result = append(result, strings.Split(line, " ")...)
+	}
+	return result
+}
+
+func trimHumanInputLineBreaks(words []string) []string {
+	for len(words) > 0 && words[0] == humanInputLineBreak {
+		words = words[1:]
+	}
+	for len(words) > 0 && words[len(words)-1] == humanInputLineBreak {
+		words = words[:len(words)-1]
+	}
+	if len(words) == 0 {
+		return nil
+	}
+	return words
+}
+
+// joinHumanInputWords keeps line breaks, but not more than one empty line in a row.
+func joinHumanInputWords(words []string) string {
+	result := strings.Builder{}
+	lineBreaks := 0
+	for i, word := range words {
+		if word == humanInputLineBreak {
+			if lineBreaks < 2 {
+				result.WriteString(word)
+			}
+			lineBreaks++
+			continue
+		}
+		if i > 0 && lineBreaks == 0 {
+			result.WriteString(" ")
+		}
+		lineBreaks = 0
+		result.WriteString(word)
+	}
+	return result.String()
+}
+
 func parseHumanInputTime(word string) (time.Time, error) {
 	now := time.Now()
 	if t, err := time.Parse(time.RFC3339, word); err == nil {
@@ -402,3 +945,12 @@ func parseHumanInputTime(word string) (time.Time, error) {
 		"-30m",
 	}, ", "))
 }
+
+func findTimeRangeBound(word string, bounds map[string]**time.Time) (string, **time.Time) {
+	for prefix, bound := range bounds {
+		if strings.HasPrefix(word, prefix) {
+			return prefix, bound
+		}
+	}
+	return "", nil
+}
@@ -58,6 +58,65 @@ func TestParseHumanInput(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			args: args{
+				input: "annotate 358bb57b-7d84-47a0-a3d5-29fcd77f87b9  waiting for +review project:x ",
+			},
+			want: &HumanInputParserResult{
+				Action:     HumanActionAnnotate,
+				ActionUUID: (func(UUID string) *uuid.UUID { r := uuid.MustParse(UUID); return &r })("358bb57b-7d84-47a0-a3d5-29fcd77f87b9"),
+				Options: HumanInputOptions{
+					Annotation: "waiting for +review project:x",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			args: args{
+				input: "annotate 358bb57b-7d84-47a0-a3d5-29fcd77f87b9",
+			},
+			wantErr: true,
+		},
+		{
+			args: args{
+				input: "rename-project Work  job preview",
+			},
+			want: &HumanInputParserResult{
+				Action: HumanActionRenameProject,
+				Options: HumanInputOptions{
+					Rename: &Rename{Kind: RenameKindProject, From: "work", To: "job", DryRun: true},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			args: args{
+				input: "rename-tag urgent urgent",
+			},
+			wantErr: true,
+		},
+		{
+			args: args{
+				input: "save-filter Work-Urgent project:work  +urgent",
+			},
+			want: &HumanInputParserResult{
+				Action: HumanActionSaveFilter,
+				Options: HumanInputOptions{
+					SavedFilter: &SavedFilter{Name: "work-urgent", Query: "project:work +urgent"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			args: args{
+				input: "list context:none",
+			},
+			want: &HumanInputParserResult{
+				Action:  HumanActionList,
+				Options: HumanInputOptions{Context: ContextNone},
+			},
+			wantErr: false,
+		},
 	}
 	for i, tt := range tests {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Descriptions support a markdown subset: **bold**, `code`, ```code blocks```, [links](https://...),
+// bare links, "- " and "1. " lists and line breaks. Everything else is escaped, so the result is safe html.
+
+var (
+	linkRegexp         = regexp.MustCompile("^(https?://([^/\\s]+)\\S*)")
+	markdownLinkRegexp = regexp.MustCompile("^\\[([^\\]]+)\\]\\((https?://[^\\s)]+)\\)")
+	orderedItemRegexp  = regexp.MustCompile("^(\\d+)[.)]\\s+(.*)$")
+)
+
+type markdownFormat int
+
+const (
+	markdownWeb markdownFormat = iota
+	// markdownTelegram uses tags of telegram HTML parse mode, it has no lists and line breaks are kept as is.
+	markdownTelegram
+)
+
+// markdownPart is an inline line or a block element, line breaks are added only between lines.
+type markdownPart struct {
+	html    string
+	isBlock bool
+}
+
+func renderMarkdown(source string, format markdownFormat) string {
+	lines := strings.Split(strings.ReplaceAll(strings.TrimSpace(source), "\r\n", "\n"), "\n")
+	parts := []markdownPart{}
+	listTag := ""
+	listItems := []string{}
+	closeList := func() {
+		if len(listItems) == 0 {
+			return
+		}
+		if format == markdownTelegram {
+			parts = append(parts, markdownPart{html: strings.Join(listItems, "\n")})
+		} else {
+			parts = append(parts, markdownPart{html: "<" + listTag + ">" + strings.Join(listItems, "") + "</" + listTag + ">", isBlock: true})
+		}
+		listItems = nil
+	}
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "```") {
+			closeList()
+			code := []string{}
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			escaped := html.EscapeString(strings.Join(code, "\n"))
+			if format == markdownTelegram {
+				parts = append(parts, markdownPart{html: "<pre>" + escaped + "</pre>"})
+			} else {
+				parts = append(parts, markdownPart{html: "<pre><code>" + escaped + "</code></pre>", isBlock: true})
+			}
+			continue
+		}
+		tag, marker, text := markdownListItem(line)
+		if len(tag) > 0 {
+			if tag != listTag {
+				closeList()
+				listTag = tag
+			}
+			if format == markdownTelegram {
+				listItems = append(listItems, marker+" "+renderMarkdownInline(text, format))
+			} else {
+				listItems = append(listItems, "<li>"+renderMarkdownInline(text, format)+"</li>")
+			}
+			continue
+		}
+		closeList()
+		parts = append(parts, markdownPart{html: renderMarkdownInline(line, format)})
+	}
+	closeList()
+
+	lineBreak := "<br>"
+	if format == markdownTelegram {
+		lineBreak = "\n"
+	}
+	result := strings.Builder{}
+	for i, part := range parts {
+		if i > 0 && !part.isBlock && !parts[i-1].isBlock {
+			result.WriteString(lineBreak)
+		}
+		result.WriteString(part.html)
+	}
+	return result.String()
+}
+
+// markdownListItem returns the list tag, the marker for telegram and the item text, the tag is empty for other lines.
+func markdownListItem(line string) (string, string, string) {
+	if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") {
+		return "ul", "•", strings.TrimSpace(line[2:])
+	}
+	if match := orderedItemRegexp.FindStringSubmatch(line); match != nil {
+		return "ol", match[1] + ".", strings.TrimSpace(match[2])
+	}
+	return "", "", ""
+}
+
+func renderMarkdownInline(text string, format markdownFormat) string {
+	result := strings.Builder{}
+	for len(text) > 0 {
+		switch {
+		case text[0] == '`':
+			if end := strings.IndexByte(text[1:], '`'); end > 0 {
+				result.WriteString("<code>" + html.EscapeString(text[1:end+1]) + "</code>")
+				text = text[end+2:]
+				continue
+			}
+		case strings.HasPrefix(text, "**"):
+			if end := strings.Index(text[2:], "**"); end > 0 {
+				tag := "strong"
+				if format == markdownTelegram {
+					tag = "b"
+				}
+				result.WriteString("<" + tag + ">" + renderMarkdownInline(text[2:end+2], format) + "</" + tag + ">")
+				text = text[end+4:]
+				continue
+			}
+		case text[0] == '[':
+			if match := markdownLinkRegexp.FindStringSubmatch(text); match != nil {
+				result.WriteString(markdownLink(match[2], renderMarkdownInline(match[1], format), format))
+				text = text[len(match[0]):]
+				continue
+			}
+		case strings.HasPrefix(text, "http://") || strings.HasPrefix(text, "https://"):
+			if match := linkRegexp.FindStringSubmatch(text); match != nil {
+				result.WriteString(markdownLink(match[1], html.EscapeString(match[2]), format))
+				text = text[len(match[0]):]
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(text)
+		result.WriteString(html.EscapeString(string(r)))
+		text = text[size:]
+	}
+	return result.String()
+}
+
+func markdownLink(url string, text string, format markdownFormat) string {
+	if format == markdownTelegram {
+		return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), text)
+	}
+	return fmt.Sprintf(`<a href="%s" target="_blank" rel="noopener noreferrer">%s</a>`, html.EscapeString(url), text)
+}
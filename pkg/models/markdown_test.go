@@ -0,0 +1,53 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	source := "Pay **invoice** `#42` at https://bank.example.com/pay?a=1&b=2\n" +
+		"<script>alert(1)</script>\n\n" +
+		"- first [docs](https://example.com/\"x)\n" +
+		"- second\n" +
+		"1. one\n" +
+		"```\nif a < b {}\n```\n" +
+		"[bad](javascript:alert(1)) **unclosed"
+
+	web := renderMarkdown(source, markdownWeb)
+	wantWeb := `Pay <strong>invoice</strong> <code>#42</code> at <a href="https://bank.example.com/pay?a=1&amp;b=2" target="_blank" rel="noopener noreferrer">bank.example.com</a><br>` +
+		`&lt;script&gt;alert(1)&lt;/script&gt;<br>` +
+		`<ul><li>first <a href="https://example.com/&#34;x" target="_blank" rel="noopener noreferrer">docs</a></li><li>second</li></ul>` +
+		`<ol><li>one</li></ol>` +
+		`<pre><code>if a &lt; b {}</code></pre>` +
+		`[bad](javascript:alert(1)) **unclosed`
+	if web != wantWeb {
+		t.Errorf("unexpected web markdown:\n%s\nwant:\n%s", web, wantWeb)
+	}
+
+	telegram := renderMarkdown(source, markdownTelegram)
+	wantTelegram := `Pay <b>invoice</b> <code>#42</code> at <a href="https://bank.example.com/pay?a=1&amp;b=2">bank.example.com</a>` + "\n" +
+		`&lt;script&gt;alert(1)&lt;/script&gt;` + "\n\n" +
+		`• first <a href="https://example.com/&#34;x">docs</a>` + "\n" +
+		`• second` + "\n" +
+		`1. one` + "\n" +
+		`<pre>if a &lt; b {}</pre>` + "\n" +
+		`[bad](javascript:alert(1)) **unclosed`
+	if telegram != wantTelegram {
+		t.Errorf("unexpected telegram markdown:\n%s\nwant:\n%s", telegram, wantTelegram)
+	}
+}
+
+func TestMultilineHumanInput(t *testing.T) {
+	parsed, err := ParseHumanInput("add project:home buy:\n\n\n\n- milk +shop\n- bread\n")
+	if err != nil {
+		t.Fatalf("cant parse input: %s", err)
+	}
+	task := NewTask()
+	parsed.Options.ModifyTask(task)
+	if task.Description != "buy:\n\n- milk\n- bread" || task.Project != "home" || len(task.Tags) != 1 {
+		t.Errorf("unexpected task: %q %+v", task.Description, task)
+	}
+	if task.Summary() != "buy:" {
+		t.Errorf("unexpected summary: %s", task.Summary())
+	}
+}
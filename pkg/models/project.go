@@ -0,0 +1,110 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Project is the stored metadata of the project of tasks. Tasks refer to projects by name,
+// so projects without metadata exist while they have tasks, see UniqProjects.
+type Project struct {
+	// Name is the project of tasks, lowercase as Task.Project.
+	Name string `json:"name"`
+	// Owner is the user of tasks of the project, see Task.Owner.
+	Owner       string   `json:"owner,omitempty"`
+	DisplayName string   `json:"display_name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Color       string   `json:"color,omitempty"`
+	Archived    bool     `json:"archived,omitempty"`
+	DefaultTags []string `json:"default_tags,omitempty"`
+}
+
+var projectColorRegexp = regexp.MustCompile("^#[0-9a-f]{6}$")
+
+func (p *Project) Unify() {
+	p.Name = strings.TrimSpace(strings.ToLower(p.Name))
+	p.DisplayName = strings.TrimSpace(p.DisplayName)
+	p.Description = strings.TrimSpace(p.Description)
+	p.Color = strings.TrimSpace(strings.ToLower(p.Color))
+	tags := []string{}
+	for _, tag := range p.DefaultTags {
+		if tag = strings.TrimSpace(strings.ToLower(tag)); len(tag) > 0 {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	tags = slices.Compact(tags)
+	if len(tags) == 0 {
+		tags = nil
+	}
+	p.DefaultTags = tags
+}
+
+func (p *Project) Validate() error {
+	if len(p.Name) == 0 {
+		return fmt.Errorf("project name should not be empty")
+	}
+	if p.Name == ProjectSelectorEmpty {
+		return fmt.Errorf("project name %s is reserved", ProjectSelectorEmpty)
+	}
+	if len(p.Color) > 0 && !projectColorRegexp.MatchString(p.Color) {
+		return fmt.Errorf("invalid project color %q, example: #1e90ff", p.Color)
+	}
+	return nil
+}
+
+// Title is the display name or the name if it is not set.
+func (p *Project) Title() string {
+	if len(p.DisplayName) > 0 {
+		return p.DisplayName
+	}
+	return p.Name
+}
+
+// Scope selects tasks of the project with subprojects.
+func (p *Project) Scope() TaskScope {
+	return TaskScope{Owner: p.Owner, Project: p.Name}
+}
+
+// ApplyDefaults adds default tags of the project to the new task.
+func (p *Project) ApplyDefaults(task *Task) {
+	for _, tag := range p.DefaultTags {
+		if !slices.Contains(task.Tags, tag) {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+}
+
+// FindProject returns the project of tasks of the owner, nil if it has no metadata.
+func FindProject(projects []*Project, owner string, name string) *Project {
+	name = strings.ToLower(name)
+	for _, project := range projects {
+		if project.Owner == owner && project.Name == name {
+			return project
+		}
+	}
+	return nil
+}
+
+// ArchivedScopes selects tasks of archived projects, see ListFilter.Archived.
+func ArchivedScopes(projects []*Project) []TaskScope {
+	result := []TaskScope{}
+	for _, project := range projects {
+		if project.Archived {
+			result = append(result, project.Scope())
+		}
+	}
+	return result
+}
+
+// SortProjects orders projects by name and owner.
+func SortProjects(projects []*Project) {
+	slices.SortFunc(projects, func(a, b *Project) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Owner, b.Owner)
+	})
+}
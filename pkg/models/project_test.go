@@ -0,0 +1,66 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	project := &Project{Name: " Work ", Color: "#1E90FF", DefaultTags: []string{"Office", "", "office", "focus"}}
+	project.Unify()
+	if project.Name != "work" || project.Color != "#1e90ff" || !slices.Equal(project.DefaultTags, []string{"focus", "office"}) {
+		t.Errorf("unexpected unified project: %+v", project)
+	}
+	if err := project.Validate(); err != nil {
+		t.Errorf("valid project is rejected: %s", err)
+	}
+	if project.Title() != "work" {
+		t.Errorf("name should be the title without display name: %s", project.Title())
+	}
+	for _, invalid := range []*Project{{}, {Name: ProjectSelectorEmpty}, {Name: "work", Color: "blue"}} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("invalid project should be rejected: %+v", invalid)
+		}
+	}
+
+	task := NewTask()
+	task.Tags = []string{"office"}
+	project.ApplyDefaults(task)
+	if !slices.Equal(task.Tags, []string{"office", "focus"}) {
+		t.Errorf("default tags should be added once: %v", task.Tags)
+	}
+
+	archived := &Project{Name: "old", Archived: true}
+	projects := []*Project{project, archived, {Name: "old", Owner: "alice"}}
+	if found := FindProject(projects, "alice", "OLD"); found != projects[2] {
+		t.Errorf("project of the owner should be found: %v", found)
+	}
+	if found := FindProject(projects, "bob", "old"); found != nil {
+		t.Errorf("projects of other owners should not be found: %v", found)
+	}
+
+	tasks := []*Task{}
+	for _, name := range []string{"old", "old.sub", "older", "work"} {
+		task := NewTask()
+		task.Project = name
+		tasks = append(tasks, task)
+	}
+	aliceTask := NewTask()
+	aliceTask.Project = "old"
+	aliceTask.Owner = "alice"
+	tasks = append(tasks, aliceTask)
+
+	filter := NewDefaultListFilter()
+	filter.Archived = ArchivedScopes(projects)
+	shown := []string{}
+	for _, task := range filter.Apply(slices.Clone(tasks)) {
+		shown = append(shown, task.Owner+":"+task.Project)
+	}
+	if !slices.Equal(shown, []string{":older", ":work", "alice:old"}) {
+		t.Errorf("archived projects with subprojects should be hidden: %v", shown)
+	}
+	filter.ShowArchived = true
+	if shown := filter.Apply(slices.Clone(tasks)); len(shown) != len(tasks) {
+		t.Errorf("archived projects should be shown: %d", len(shown))
+	}
+}
@@ -0,0 +1,233 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RecurrenceFrequency string
+
+const (
+	Daily   RecurrenceFrequency = "daily"
+	Weekly  RecurrenceFrequency = "weekly"
+	Monthly RecurrenceFrequency = "monthly"
+	Yearly  RecurrenceFrequency = "yearly"
+)
+
+type Recurrence struct {
+	Frequency RecurrenceFrequency
+	Interval  int
+}
+
+var recurrenceAliases = map[string]Recurrence{
+	"daily":      {Daily, 1},
+	"day":        {Daily, 1},
+	"weekly":     {Weekly, 1},
+	"week":       {Weekly, 1},
+	"biweekly":   {Weekly, 2},
+	"fortnight":  {Weekly, 2},
+	"monthly":    {Monthly, 1},
+	"month":      {Monthly, 1},
+	"bimonthly":  {Monthly, 2},
+	"quarterly":  {Monthly, 3},
+	"semiannual": {Monthly, 6},
+	"yearly":     {Yearly, 1},
+	"year":       {Yearly, 1},
+	"annual":     {Yearly, 1},
+	"biannual":   {Yearly, 2},
+}
+
+var recurrenceUnits = []struct {
+	suffix    string
+	frequency RecurrenceFrequency
+}{
+	{"days", Daily}, {"day", Daily}, {"d", Daily},
+	{"weeks", Weekly}, {"week", Weekly}, {"wks", Weekly}, {"w", Weekly},
+	{"months", Monthly}, {"month", Monthly}, {"mo", Monthly}, {"m", Monthly},
+	{"years", Yearly}, {"year", Yearly}, {"yrs", Yearly}, {"y", Yearly},
+}
+
+// ParseRecurrence accepts named periods (daily, weekly, quarterly...), counted periods (2d, 3w, 6m, 1y)
+// and the FREQ/INTERVAL subset of RFC 5545 RRULE (FREQ=WEEKLY;INTERVAL=2).
+func ParseRecurrence(value string) (*Recurrence, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if len(value) == 0 {
+		return nil, fmt.Errorf("empty recurrence")
+	}
+	if r, ok := recurrenceAliases[value]; ok {
+		return &r, nil
+	}
+	if strings.Contains(value, "freq=") {
+		return parseRRule(value)
+	}
+	for _, unit := range recurrenceUnits {
+		if !strings.HasSuffix(value, unit.suffix) {
+			continue
+		}
+		interval, err := strconv.Atoi(strings.TrimSuffix(value, unit.suffix))
+		if err != nil {
+			continue
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("recurrence interval should be positive")
+		}
+		return &Recurrence{Frequency: unit.frequency, Interval: interval}, nil
+	}
+	return nil, fmt.Errorf("cant parse recurrence: %s", value)
+}
+
+func parseRRule(value string) (*Recurrence, error) {
+	value = strings.TrimPrefix(value, "rrule:")
+	result := &Recurrence{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		if len(part) == 0 {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rrule part: %s", part)
+		}
+		switch key {
+		case "freq":
+			switch val {
+			case "daily":
+				result.Frequency = Daily
+			case "weekly":
+				result.Frequency = Weekly
+			case "monthly":
+				result.Frequency = Monthly
+			case "yearly":
+				result.Frequency = Yearly
+			default:
+				return nil, fmt.Errorf("unsupported rrule freq: %s", val)
+			}
+		case "interval":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval <= 0 {
+				return nil, fmt.Errorf("invalid rrule interval: %s", val)
+			}
+			result.Interval = interval
+		default:
+			return nil, fmt.Errorf("unsupported rrule part: %s", key)
+		}
+	}
+	if result.Frequency == "" {
+		return nil, fmt.Errorf("rrule freq is required")
+	}
+	return result, nil
+}
+
+func (r *Recurrence) String() string {
+	if r.Interval == 1 {
+		return string(r.Frequency)
+	}
+	switch r.Frequency {
+	case Daily:
+		return strconv.Itoa(r.Interval) + "d"
+	case Weekly:
+		return strconv.Itoa(r.Interval) + "w"
+	case Monthly:
+		return strconv.Itoa(r.Interval) + "m"
+	case Yearly:
+		return strconv.Itoa(r.Interval) + "y"
+	}
+	return ""
+}
+
+// Next returns the date after the recurrence period, see After.
+func (r *Recurrence) Next(date time.Time) time.Time {
+	return r.After(date, 1)
+}
+
+// After returns the date after the number of recurrence periods. Monthly and yearly dates are clamped
+// to the last day of the month, so Jan 31 is followed by Feb 29 and Mar 31, not by Mar 2.
+func (r *Recurrence) After(date time.Time, periods int) time.Time {
+	switch r.Frequency {
+	case Daily:
+		return date.AddDate(0, 0, r.Interval*periods)
+	case Weekly:
+		return date.AddDate(0, 0, 7*r.Interval*periods)
+	case Monthly:
+		return addMonths(date, r.Interval*periods)
+	case Yearly:
+		return addMonths(date, 12*r.Interval*periods)
+	}
+	return date
+}
+
+func addMonths(date time.Time, months int) time.Time {
+	year, month, day := date.Date()
+	first := time.Date(year, month+time.Month(months), 1, date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, lastDay)-1)
+}
+
+// NextOccurrence returns a new pending task with Due, Notify, Wait and Scheduled shifted by the recurrence period
+// until the anchor date is in the future. It returns nil for not recurring tasks.
+func (t *Task) NextOccurrence(now time.Time) (*Task, error) {
+	if len(t.Recur) == 0 {
+		return nil, nil
+	}
+	recurrence, err := ParseRecurrence(t.Recur)
+	if err != nil {
+		return nil, err
+	}
+	anchor := t.Due
+	if anchor == nil {
+		anchor = t.Notify
+	}
+	if anchor == nil {
+		return nil, fmt.Errorf("recurring task should have due or notify")
+	}
+	steps := 1
+	for !recurrence.After(*anchor, steps).After(now) {
+		steps++
+	}
+	// dates are shifted from the closed task at once, so clamping to the month end does not accumulate
+	shift := func(date time.Time) *time.Time {
+		date = recurrence.After(date, steps)
+		return &date
+	}
+
+	next := t.Clone(true)
+	next.Status = Pending
+	next.CreatedAt = now
+	next.ModifiedAt = nil
+	next.CompletedAt = nil
+	if next.Due != nil {
+		next.Due = shift(*next.Due)
+	}
+	if next.Notify != nil {
+		next.Notify = shift(*next.Notify)
+	}
+	if next.Wait != nil {
+		next.Wait = shift(*next.Wait)
+	}
+	if next.Scheduled != nil {
+		next.Scheduled = shift(*next.Scheduled)
+	}
+	return next, nil
+}
+
+// InsertTask saves the task, the related tasks and, if the task has just been closed, its next occurrence at once,
+// so the closed task is never left without the next one.
+func InsertTask(ctx context.Context, repo Repository, previousStatus taskStatus, task *Task, related ...*Task) (*Task, error) {
+	tasks := append([]*Task{task}, related...)
+	if !IsClosing(previousStatus, task.Status) {
+		return nil, repo.Insert(ctx, tasks...)
+	}
+	next, err := task.NextOccurrence(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("cant create next occurrence: %w", err)
+	}
+	if next == nil {
+		return nil, repo.Insert(ctx, tasks...)
+	}
+	if err := repo.Insert(ctx, append(tasks, next)...); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    *Recurrence
+		wantErr bool
+	}{
+		{input: "daily", want: &Recurrence{Daily, 1}},
+		{input: "Weekly", want: &Recurrence{Weekly, 1}},
+		{input: "quarterly", want: &Recurrence{Monthly, 3}},
+		{input: "2d", want: &Recurrence{Daily, 2}},
+		{input: "3wks", want: &Recurrence{Weekly, 3}},
+		{input: "6mo", want: &Recurrence{Monthly, 6}},
+		{input: "1y", want: &Recurrence{Yearly, 1}},
+		{input: "FREQ=WEEKLY;INTERVAL=2", want: &Recurrence{Weekly, 2}},
+		{input: "RRULE:FREQ=MONTHLY", want: &Recurrence{Monthly, 1}},
+		{input: "FREQ=WEEKLY;BYDAY=MO", wantErr: true},
+		{input: "0d", wantErr: true},
+		{input: "sometimes", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRecurrence(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseRecurrence() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRecurrence() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_NextOccurrence(t *testing.T) {
+	now := time.Date(2024, 10, 15, 12, 0, 0, 0, time.Local)
+	due := time.Date(2024, 10, 1, 10, 0, 0, 0, time.Local)
+	notify := time.Date(2024, 10, 1, 9, 0, 0, 0, time.Local)
+	wait := time.Date(2024, 9, 28, 0, 0, 0, 0, time.Local)
+	scheduled := time.Date(2024, 9, 30, 8, 0, 0, 0, time.Local)
+	task := NewTask()
+	task.Description = "pay rent"
+	task.Status = Completed
+	task.Due = &due
+	task.Notify = &notify
+	task.Wait = &wait
+	task.Scheduled = &scheduled
+	task.Recur = "weekly"
+
+	next, err := task.NextOccurrence(now)
+	if err != nil {
+		t.Fatalf("NextOccurrence() error = %v", err)
+	}
+	if next.UUID == task.UUID {
+		t.Errorf("next occurrence should have new uuid")
+	}
+	if next.Status != Pending {
+		t.Errorf("next occurrence should be pending, have %s", next.Status)
+	}
+	if wantDue := time.Date(2024, 10, 22, 10, 0, 0, 0, time.Local); !next.Due.Equal(wantDue) {
+		t.Errorf("due should be %s, have %s", wantDue, next.Due)
+	}
+	if wantNotify := time.Date(2024, 10, 22, 9, 0, 0, 0, time.Local); !next.Notify.Equal(wantNotify) {
+		t.Errorf("notify should be %s, have %s", wantNotify, next.Notify)
+	}
+	if wantWait := time.Date(2024, 10, 19, 0, 0, 0, 0, time.Local); !next.Wait.Equal(wantWait) {
+		t.Errorf("wait should be %s, have %s", wantWait, next.Wait)
+	}
+	if wantScheduled := time.Date(2024, 10, 21, 8, 0, 0, 0, time.Local); !next.Scheduled.Equal(wantScheduled) {
+		t.Errorf("scheduled should be %s, have %s", wantScheduled, next.Scheduled)
+	}
+
+	task.Recur = ""
+	next, err = task.NextOccurrence(now)
+	if err != nil || next != nil {
+		t.Errorf("not recurring task should not have next occurrence, have %+v, %v", next, err)
+	}
+}
+
+func TestRecurrence_MonthEnd(t *testing.T) {
+	date := time.Date(2024, 1, 31, 10, 0, 0, 0, time.Local)
+	monthly := &Recurrence{Monthly, 1}
+	for periods, want := range []time.Time{
+		date,
+		time.Date(2024, 2, 29, 10, 0, 0, 0, time.Local),
+		time.Date(2024, 3, 31, 10, 0, 0, 0, time.Local),
+		time.Date(2024, 4, 30, 10, 0, 0, 0, time.Local),
+		time.Date(2024, 5, 31, 10, 0, 0, 0, time.Local),
+	} {
+		if got := monthly.After(date, periods); !got.Equal(want) {
+			t.Errorf("monthly after %d periods should be %s, have %s", periods, want, got)
+		}
+	}
+	leap := time.Date(2024, 2, 29, 10, 0, 0, 0, time.Local)
+	if got, want := (&Recurrence{Yearly, 1}).Next(leap), time.Date(2025, 2, 28, 10, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("yearly after leap day should be %s, have %s", want, got)
+	}
+
+	task := NewTask()
+	task.Description = "pay rent"
+	task.Status = Completed
+	task.Due = &date
+	task.Recur = "monthly"
+	next, err := task.NextOccurrence(time.Date(2024, 3, 15, 12, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("NextOccurrence() error = %v", err)
+	}
+	if want := time.Date(2024, 3, 31, 10, 0, 0, 0, time.Local); !next.Due.Equal(want) {
+		t.Errorf("skipped periods should not drift the month end, want %s, have %s", want, next.Due)
+	}
+}
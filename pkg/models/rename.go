@@ -0,0 +1,206 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type RenameKind string
+
+const (
+	RenameKindProject RenameKind = "project"
+	RenameKindTag     RenameKind = "tag"
+)
+
+// Rename renames the project with its subprojects or the tag of tasks. If tasks with the new name exist,
+// renamed tasks are merged with them. Repositories rename all tasks at once, see Repository.Rename.
+type Rename struct {
+	Kind RenameKind `json:"kind"`
+	From string     `json:"from"`
+	To   string     `json:"to"`
+	// DryRun only counts affected tasks.
+	DryRun bool `json:"dry_run,omitempty"`
+	// Scopes select renamed tasks, nil for tasks of all users, see ListFilter.Scopes.
+	Scopes []TaskScope `json:"-"`
+	// ModifiedBy is the actor of renamed tasks.
+	ModifiedBy string `json:"-"`
+}
+
+func NewRename(kind RenameKind, from string, to string) (*Rename, error) {
+	rename := &Rename{Kind: kind, From: from, To: to}
+	rename.Unify()
+	if err := rename.Validate(); err != nil {
+		return nil, err
+	}
+	return rename, nil
+}
+
+func (r *Rename) Unify() {
+	r.From = strings.TrimSpace(strings.ToLower(r.From))
+	r.To = strings.TrimSpace(strings.ToLower(r.To))
+}
+
+func (r *Rename) Validate() error {
+	if r.Kind != RenameKindProject && r.Kind != RenameKindTag {
+		return fmt.Errorf("unknown rename kind %q, valid are project, tag", r.Kind)
+	}
+	if len(r.From) == 0 || len(r.To) == 0 {
+		return fmt.Errorf("old and new %s should not be empty", r.Kind)
+	}
+	if r.From == ProjectSelectorEmpty || r.To == ProjectSelectorEmpty {
+		return fmt.Errorf("%s name %s is reserved", r.Kind, ProjectSelectorEmpty)
+	}
+	if strings.ContainsFunc(r.To, unicode.IsSpace) {
+		return fmt.Errorf("new %s should not contain spaces", r.Kind)
+	}
+	if r.From == r.To {
+		return fmt.Errorf("old and new %s are the same", r.Kind)
+	}
+	return nil
+}
+
+// rename returns the new name of the project or the tag, subprojects keep their suffix.
+func (r *Rename) rename(name string) (string, bool) {
+	if name == r.From {
+		return r.To, true
+	}
+	if r.Kind == RenameKindProject && strings.HasPrefix(name, r.From+".") {
+		return r.To + strings.TrimPrefix(name, r.From), true
+	}
+	return "", false
+}
+
+func (r *Rename) contains(task *Task) bool {
+	return r.Scopes == nil || slices.ContainsFunc(r.Scopes, func(scope TaskScope) bool { return scope.Contains(task) })
+}
+
+// apply renames the project or the tag of the task, it returns false if the task is not affected.
+func (r *Rename) apply(task *Task) bool {
+	if r.Kind == RenameKindProject {
+		project, ok := r.rename(task.Project)
+		task.Project = project
+		return ok
+	}
+	renamed := false
+	for i := range task.Tags {
+		if tag, ok := r.rename(task.Tags[i]); ok {
+			task.Tags[i] = tag
+			renamed = true
+		}
+	}
+	return renamed
+}
+
+// RenameTasks returns renamed revisions of affected tasks with their history entries.
+func (r *Rename) RenameTasks(tasks []*Task, now time.Time) ([]*Task, []*TaskHistoryEntry, error) {
+	renamed := []*Task{}
+	history := []*TaskHistoryEntry{}
+	for _, previous := range tasks {
+		if !r.contains(previous) {
+			continue
+		}
+		task := previous.Clone(false)
+		if !r.apply(task) {
+			continue
+		}
+		task.ModifiedBy = r.ModifiedBy
+		task.Unify()
+		if err := task.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid task %s: %w", task.UUID, err)
+		}
+		if err := task.NextRevision(previous); err != nil {
+			return nil, nil, err
+		}
+		if err := task.UpdateTimestamps(previous, now); err != nil {
+			return nil, nil, fmt.Errorf("cant update timestamps: %w", err)
+		}
+		entry, err := NewTaskHistoryEntry(previous, task, now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cant create history entry: %w", err)
+		}
+		renamed = append(renamed, task)
+		history = append(history, entry)
+	}
+	return renamed, history, nil
+}
+
+// RenameProjects returns project metadata changed by the rename and projects to delete.
+// Metadata of the existing new project wins on merge, tag renames also change default tags.
+func (r *Rename) RenameProjects(projects []*Project) ([]*Project, []*Project) {
+	saved := []*Project{}
+	deleted := []*Project{}
+	for _, project := range projects {
+		if !r.contains(&Task{Owner: project.Owner, Project: project.Name}) {
+			continue
+		}
+		if r.Kind == RenameKindTag {
+			if !slices.Contains(project.DefaultTags, r.From) {
+				continue
+			}
+			changed := *project
+			changed.DefaultTags = slices.Clone(project.DefaultTags)
+			for i := range changed.DefaultTags {
+				if changed.DefaultTags[i] == r.From {
+					changed.DefaultTags[i] = r.To
+				}
+			}
+			changed.Unify()
+			saved = append(saved, &changed)
+			continue
+		}
+		name, ok := r.rename(project.Name)
+		if !ok {
+			continue
+		}
+		deleted = append(deleted, project)
+		if FindProject(projects, project.Owner, name) == nil {
+			moved := *project
+			moved.Name = name
+			saved = append(saved, &moved)
+		}
+	}
+	return saved, deleted
+}
+
+type RenamePreviewRow struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Tasks int    `json:"tasks"`
+}
+
+// RenamePreview is the number of tasks per renamed project or tag.
+type RenamePreview struct {
+	Rename *Rename            `json:"rename"`
+	Rows   []RenamePreviewRow `json:"rows"`
+	// Merged is the number of tasks which already have the new name.
+	Merged int `json:"merged"`
+}
+
+// Preview counts affected tasks, the same task with several renamed tags is counted once per tag.
+func (r *Rename) Preview(tasks []*Task) *RenamePreview {
+	tasks = slices.DeleteFunc(slices.Clone(tasks), func(task *Task) bool { return !r.contains(task) })
+	counts := UniqTags(tasks)
+	if r.Kind == RenameKindProject {
+		counts = UniqProjects(tasks)
+	}
+	result := &RenamePreview{Rename: r, Rows: []RenamePreviewRow{}, Merged: counts[r.To]}
+	for name, count := range counts {
+		if to, ok := r.rename(name); ok {
+			result.Rows = append(result.Rows, RenamePreviewRow{From: name, To: to, Tasks: count})
+		}
+	}
+	slices.SortFunc(result.Rows, func(a, b RenamePreviewRow) int { return strings.Compare(a.From, b.From) })
+	return result
+}
+
+// Tasks is the number of renamed tasks.
+func (p *RenamePreview) Tasks() int {
+	result := 0
+	for _, row := range p.Rows {
+		result += row.Tasks
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRename(t *testing.T) {
+	if _, err := NewRename(RenameKindProject, "work", " WORK "); err == nil {
+		t.Errorf("rename to the same project should be rejected")
+	}
+	if _, err := NewRename("status", "a", "b"); err == nil {
+		t.Errorf("unknown rename kind should be rejected")
+	}
+
+	tasks := []*Task{}
+	for _, project := range []string{"work", "work.api", "workshop", "job", ""} {
+		task := NewTask()
+		task.Project = project
+		task.Description = "task of " + project
+		task.Tags = []string{"urgent", "office"}
+		tasks = append(tasks, task)
+	}
+	tasks[3].Tags = []string{"important"}
+	aliceTask := NewTask()
+	aliceTask.Owner = "alice"
+	aliceTask.Project = "work"
+	aliceTask.Description = "task of alice"
+	tasks = append(tasks, aliceTask)
+
+	rename, err := NewRename(RenameKindProject, "Work", "job")
+	if err != nil {
+		t.Fatalf("valid rename is rejected: %s", err)
+	}
+	rename.Scopes = []TaskScope{{Owner: ""}}
+	rename.ModifiedBy = "bob"
+	preview := rename.Preview(tasks)
+	if preview.Tasks() != 2 || preview.Merged != 1 || len(preview.Rows) != 2 || preview.Rows[1].To != "job.api" {
+		t.Errorf("unexpected preview: %+v", preview)
+	}
+	renamed, history, err := rename.RenameTasks(tasks, time.Now())
+	if err != nil {
+		t.Fatalf("cant rename tasks: %s", err)
+	}
+	projects := []string{}
+	for _, task := range renamed {
+		projects = append(projects, task.Project)
+		if task.ModifiedBy != "bob" || task.Revision != tasks[0].Revision+1 {
+			t.Errorf("renamed task should be the next revision by the actor: %+v", task)
+		}
+	}
+	if !slices.Equal(projects, []string{"job", "job.api"}) || len(history) != 2 || tasks[0].Project != "work" {
+		t.Errorf("project with subprojects of the owner should be renamed in copies: %v", projects)
+	}
+
+	stored := []*Project{{Name: "work", Color: "#ff0000"}, {Name: "work.api"}, {Name: "job.api", Color: "#00ff00"}}
+	saved, deleted := rename.RenameProjects(stored)
+	if len(saved) != 1 || saved[0].Name != "job" || saved[0].Color != "#ff0000" || len(deleted) != 2 {
+		t.Errorf("settings of the existing project should win on merge: saved %+v, deleted %+v", saved, deleted)
+	}
+
+	tagRename, _ := NewRename(RenameKindTag, "urgent", "office")
+	renamed, _, err = tagRename.RenameTasks(tasks, time.Now())
+	if err != nil || len(renamed) != 4 || !slices.Equal(renamed[0].Tags, []string{"office"}) {
+		t.Errorf("tag should be merged with the existing one: %v", renamed)
+	}
+	saved, _ = tagRename.RenameProjects([]*Project{{Name: "work", DefaultTags: []string{"office", "urgent"}}})
+	if len(saved) != 1 || !slices.Equal(saved[0].DefaultTags, []string{"office"}) {
+		t.Errorf("default tags should be renamed: %+v", saved)
+	}
+}
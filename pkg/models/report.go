@@ -0,0 +1,180 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"slices"
+	"time"
+)
+
+type ReportGroupBy string
+
+const (
+	ReportByProject ReportGroupBy = "project"
+	ReportByTag     ReportGroupBy = "tag"
+	ReportByDay     ReportGroupBy = "day"
+)
+
+var ReportGroupByAllowed = []ReportGroupBy{ReportByProject, ReportByTag, ReportByDay}
+
+func ParseReportGroupBy(value string) (ReportGroupBy, error) {
+	if len(value) == 0 {
+		return ReportByProject, nil
+	}
+	if !slices.Contains(ReportGroupByAllowed, ReportGroupBy(value)) {
+		return "", fmt.Errorf("unknown report group: %s", value)
+	}
+	return ReportGroupBy(value), nil
+}
+
+const reportDateLayout = "2006-01-02"
+
+// ParseReportRange parses inclusive days in 2006-01-02 format into [from, to) range.
+// Empty from means the first day of the current month, empty to means today.
+func ParseReportRange(from, to string, now time.Time) (time.Time, time.Time, error) {
+	today := truncateToDay(now)
+	fromTime := today.AddDate(0, 0, 1-today.Day())
+	toTime := today
+	if len(from) > 0 {
+		parsed, err := time.ParseInLocation(reportDateLayout, from, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("cant parse from: %w", err)
+		}
+		fromTime = parsed
+	}
+	if len(to) > 0 {
+		parsed, err := time.ParseInLocation(reportDateLayout, to, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("cant parse to: %w", err)
+		}
+		toTime = parsed
+	}
+	toTime = toTime.AddDate(0, 0, 1)
+	if !toTime.After(fromTime) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from should be before to")
+	}
+	return fromTime, toTime, nil
+}
+
+// NewTimeReportFilter selects tasks which can have tracked time, deleted tasks are not billed.
+func NewTimeReportFilter() *ListFilter {
+	filter := NewDefaultListFilter()
+	filter.ShowCompleted = true
+	filter.ShowWaiting = true
+	filter.ShowArchived = true
+	return filter
+}
+
+type TimeReportRow struct {
+	Group    string        `json:"group"`
+	Duration time.Duration `json:"duration"`
+}
+
+type TimeReport struct {
+	GroupBy ReportGroupBy   `json:"group_by"`
+	From    time.Time       `json:"from"`
+	To      time.Time       `json:"to"`
+	Rows    []TimeReportRow `json:"rows"`
+	Total   time.Duration   `json:"total"`
+}
+
+// FindTimeReport builds the report over tasks found by the filter.
+func FindTimeReport(ctx context.Context, repo Repository, filter *ListFilter, groupBy ReportGroupBy, from, to time.Time) (*TimeReport, error) {
+	result, err := repo.Find(ctx, filter, Page{})
+	if err != nil {
+		return nil, fmt.Errorf("cant find tasks: %w", err)
+	}
+	return BuildTimeReport(result.Tasks, groupBy, from, to, time.Now()), nil
+}
+
+// BuildTimeReport sums tracked time within [from, to). A task with several tags is counted in every tag,
+// so Total is the real tracked time and can be less than the sum of rows.
+func BuildTimeReport(tasks []*Task, groupBy ReportGroupBy, from, to, now time.Time) *TimeReport {
+	report := &TimeReport{GroupBy: groupBy, From: from, To: to, Rows: []TimeReportRow{}}
+	groups := map[string]time.Duration{}
+	for _, task := range tasks {
+		duration := task.TrackedDuration(from, to, now)
+		if duration == 0 {
+			continue
+		}
+		report.Total += duration
+		switch groupBy {
+		case ReportByTag:
+			if len(task.Tags) == 0 {
+				groups["(no tag)"] += duration
+			}
+			for _, tag := range task.Tags {
+				groups[tag] += duration
+			}
+		case ReportByDay:
+			for day := truncateToDay(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+				dayStart, dayEnd := day, day.AddDate(0, 0, 1)
+				if dayStart.Before(from) {
+					dayStart = from
+				}
+				if dayEnd.After(to) {
+					dayEnd = to
+				}
+				if dayDuration := task.TrackedDuration(dayStart, dayEnd, now); dayDuration > 0 {
+					groups[day.Format(reportDateLayout)] += dayDuration
+				}
+			}
+		default:
+			project := task.Project
+			if len(project) == 0 {
+				project = "(no project)"
+			}
+			groups[project] += duration
+		}
+	}
+	for group, duration := range groups {
+		report.Rows = append(report.Rows, TimeReportRow{Group: group, Duration: duration})
+	}
+	slices.SortFunc(report.Rows, func(a, b TimeReportRow) int {
+		if a.Group < b.Group {
+			return -1
+		}
+		if a.Group > b.Group {
+			return 1
+		}
+		return 0
+	})
+	return report
+}
+
+func FormatTrackedDuration(duration time.Duration) string {
+	duration = duration.Round(time.Minute)
+	return fmt.Sprintf("%dh%02dm", int(duration.Hours()), int(duration.Minutes())%60)
+}
+
+// Table renders the report with go-pretty, so it supports the same output formats as the client.
+func (r *TimeReport) Table() table.Writer {
+	tableWriter := table.NewWriter()
+	tableWriter.Style().Format.Footer = text.FormatDefault
+	tableWriter.AppendHeader(table.Row{string(r.GroupBy), "duration", "hours"})
+	for _, row := range r.Rows {
+		tableWriter.AppendRow(table.Row{row.Group, FormatTrackedDuration(row.Duration), fmt.Sprintf("%.2f", row.Duration.Hours())})
+	}
+	tableWriter.AppendFooter(table.Row{"total", FormatTrackedDuration(r.Total), fmt.Sprintf("%.2f", r.Total.Hours())})
+	return tableWriter
+}
+
+var ReportFormatsAllowed = []string{"table", "csv", "markdown", "html", "tsv"}
+
+func (r *TimeReport) Render(format string) string {
+	tableWriter := r.Table()
+	switch format {
+	case "csv":
+		return tableWriter.RenderCSV()
+	case "markdown":
+		return tableWriter.RenderMarkdown()
+	case "html":
+		return tableWriter.RenderHTML()
+	case "tsv":
+		return tableWriter.RenderTSV()
+	default:
+		return tableWriter.Render()
+	}
+}
@@ -1,11 +1,31 @@
 package models
 
 import (
+	"context"
 	"github.com/google/uuid"
 )
 
 type Repository interface {
-	Get(UUID uuid.UUID) (*Task, error)
-	Insert(t *Task) error
-	All() ([]*Task, error)
+	Get(ctx context.Context, UUID uuid.UUID) (*Task, error)
+	// Insert saves tasks at once, either all of them or none, e.g. the closed task with its next occurrence.
+	Insert(ctx context.Context, tasks ...*Task) error
+	All(ctx context.Context) ([]*Task, error)
+	Find(ctx context.Context, filter *ListFilter, page Page) (*FindResult, error)
+	History(ctx context.Context, UUID uuid.UUID) ([]*TaskHistoryEntry, error)
+
+	// Projects returns projects with stored metadata.
+	Projects(ctx context.Context) ([]*Project, error)
+	// SaveProject creates or updates metadata of the project of the owner.
+	SaveProject(ctx context.Context, project *Project) error
+	// DeleteProject deletes metadata of the project, tasks of the project are kept.
+	DeleteProject(ctx context.Context, project *Project) error
+	// Rename renames the project or the tag of tasks and project metadata at once and returns affected task counts.
+	// With Rename.DryRun nothing is changed.
+	Rename(ctx context.Context, rename *Rename) (*RenamePreview, error)
+
+	// Filters returns saved filters.
+	Filters(ctx context.Context) ([]*SavedFilter, error)
+	// SaveFilter creates or updates the saved filter of the owner, the active filter deactivates other ones of the owner.
+	SaveFilter(ctx context.Context, filter *SavedFilter) error
+	DeleteFilter(ctx context.Context, filter *SavedFilter) error
 }
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ContextNone disables the active saved filter, like context:none.
+const ContextNone = "none"
+
+// SavedFilter is the named list filter of the owner, Query is options of the list action, like "project:work +urgent".
+// The active saved filter is the context, it is applied by default to lists and agenda.
+type SavedFilter struct {
+	Name string `json:"name"`
+	// Owner is the user of the saved filter, saved filters are not shared.
+	Owner  string `json:"owner,omitempty"`
+	Query  string `json:"query"`
+	Active bool   `json:"active,omitempty"`
+}
+
+// savedFilterNameRegexp keeps names usable as telegram commands, see SavedFilter.Command.
+var savedFilterNameRegexp = regexp.MustCompile("^[a-z0-9_-]{1,32}$")
+
+func (f *SavedFilter) Unify() {
+	f.Name = strings.TrimSpace(strings.ToLower(f.Name))
+	f.Query = strings.Join(strings.Fields(f.Query), " ")
+}
+
+func (f *SavedFilter) Validate() error {
+	if !savedFilterNameRegexp.MatchString(f.Name) {
+		return fmt.Errorf("invalid filter name %q, use up to 32 letters, digits, - and _", f.Name)
+	}
+	if f.Name == ContextNone {
+		return fmt.Errorf("filter name %s is reserved", ContextNone)
+	}
+	if _, err := f.Options(); err != nil {
+		return fmt.Errorf("invalid filter query: %w", err)
+	}
+	return nil
+}
+
+func (f *SavedFilter) Options() (*HumanInputOptions, error) {
+	return parseHumanOptions(f.Query)
+}
+
+// Command is the telegram command of the saved filter.
+func (f *SavedFilter) Command() string {
+	return strings.ReplaceAll(f.Name, "-", "_")
+}
+
+// FindSavedFilter returns the saved filter by the name or the telegram command, nil if it is not found.
+func FindSavedFilter(filters []*SavedFilter, name string) *SavedFilter {
+	name = strings.TrimPrefix(strings.ToLower(name), "/")
+	for _, filter := range filters {
+		if filter.Name == name || filter.Command() == name {
+			return filter
+		}
+	}
+	return nil
+}
+
+// ActiveSavedFilter returns the context, nil if there is no active saved filter.
+func ActiveSavedFilter(filters []*SavedFilter) *SavedFilter {
+	for _, filter := range filters {
+		if filter.Active {
+			return filter
+		}
+	}
+	return nil
+}
+
+// SortSavedFilters orders saved filters by name and owner.
+func SortSavedFilters(filters []*SavedFilter) {
+	slices.SortFunc(filters, func(a, b *SavedFilter) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Owner, b.Owner)
+	})
+}
+
+// ContextSavedFilter returns the saved filter of context option, the active one without the option
+// and nil with context:none. Filters are saved filters of the user.
+func (o *HumanInputOptions) ContextSavedFilter(filters []*SavedFilter) (*SavedFilter, error) {
+	switch o.Context {
+	case ContextNone:
+		return nil, nil
+	case "":
+		return ActiveSavedFilter(filters), nil
+	}
+	if filter := FindSavedFilter(filters, o.Context); filter != nil {
+		return filter, nil
+	}
+	return nil, fmt.Errorf("saved filter %s not found", o.Context)
+}
+
+// ContextListFilter returns the list filter of options within the context, see ContextSavedFilter.
+// Options override the project, status and ranges of the saved filter, tags and search words are combined.
+func (o *HumanInputOptions) ContextListFilter(filters []*SavedFilter) (*ListFilter, error) {
+	saved, err := o.ContextSavedFilter(filters)
+	if err != nil || saved == nil {
+		return o.ToListFilter(), err
+	}
+	result, err := saved.Options()
+	if err != nil {
+		return nil, fmt.Errorf("invalid saved filter %s: %w", saved.Name, err)
+	}
+	if o.Project.IsExists {
+		result.Project = o.Project
+	}
+	if o.Wait.IsExists {
+		result.Wait = o.Wait
+	}
+	if o.Parent.IsExists {
+		result.Parent = o.Parent
+	}
+	if o.Status != nil {
+		result.Status = o.Status
+	}
+	if !o.Completed.IsEmpty() {
+		result.Completed = o.Completed
+	}
+	if !o.Modified.IsEmpty() {
+		result.Modified = o.Modified
+	}
+	result.Tags = append(result.Tags, o.Tags...)
+	result.ExtraWords = append(result.ExtraWords, o.ExtraWords...)
+	return result.ToListFilter(), nil
+}
+
+// SwitchContext returns the saved filter to save for context action: the named filter becomes active,
+// with none the active filter is deactivated. It returns nil if there is nothing to change.
+func SwitchContext(filters []*SavedFilter, name string) (*SavedFilter, error) {
+	if name == ContextNone {
+		active := ActiveSavedFilter(filters)
+		if active == nil {
+			return nil, nil
+		}
+		result := *active
+		result.Active = false
+		return &result, nil
+	}
+	filter := FindSavedFilter(filters, name)
+	if filter == nil {
+		return nil, fmt.Errorf("saved filter %s not found", name)
+	}
+	if filter.Active {
+		return nil, nil
+	}
+	result := *filter
+	result.Active = true
+	return &result, nil
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSavedFilter(t *testing.T) {
+	for _, filter := range []SavedFilter{{Name: "none"}, {Name: "work urgent"}, {Name: "work", Query: "due:never-ever"}} {
+		filter.Unify()
+		if err := filter.Validate(); err == nil {
+			t.Errorf("invalid filter should be rejected: %+v", filter)
+		}
+	}
+
+	filters := []*SavedFilter{
+		{Name: "work-urgent", Query: "project:work +urgent", Active: true},
+		{Name: "home", Query: "project:home"},
+	}
+	if FindSavedFilter(filters, "/work_urgent") != filters[0] || FindSavedFilter(filters, "work") != nil {
+		t.Errorf("saved filter should be found by the name or the telegram command")
+	}
+
+	filter, err := (&HumanInputOptions{}).ContextListFilter(filters)
+	if err != nil {
+		t.Fatalf("cant apply context: %s", err)
+	}
+	if filter.Project != "work" || !slices.Equal(filter.Tags, []string{"urgent"}) {
+		t.Errorf("active saved filter should be applied by default: %+v", filter)
+	}
+	options, err := parseHumanOptions("project:office +call")
+	if err != nil {
+		t.Fatalf("cant parse options: %s", err)
+	}
+	filter, err = options.ContextListFilter(filters)
+	if err != nil {
+		t.Fatalf("cant apply context: %s", err)
+	}
+	if filter.Project != "office" || !slices.Equal(filter.Tags, []string{"urgent", "call"}) {
+		t.Errorf("options should override the project and add tags of the context: %+v", filter)
+	}
+	filter, err = (&HumanInputOptions{Context: ContextNone}).ContextListFilter(filters)
+	if err != nil || filter.Project != "" || len(filter.Tags) != 0 {
+		t.Errorf("context:none should not apply the saved filter: %+v, %v", filter, err)
+	}
+	if _, err := (&HumanInputOptions{Context: "missing"}).ContextListFilter(filters); err == nil {
+		t.Errorf("unknown context should be rejected")
+	}
+
+	switched, err := SwitchContext(filters, "home")
+	if err != nil || switched.Name != "home" || !switched.Active || filters[1].Active {
+		t.Errorf("context should activate a copy of the saved filter: %+v, %v", switched, err)
+	}
+	switched, err = SwitchContext(filters, ContextNone)
+	if err != nil || switched.Name != "work-urgent" || switched.Active {
+		t.Errorf("context none should deactivate the active saved filter: %+v, %v", switched, err)
+	}
+	if switched, err := SwitchContext(filters, "work-urgent"); err != nil || switched != nil {
+		t.Errorf("active saved filter should not be changed: %+v, %v", switched, err)
+	}
+}
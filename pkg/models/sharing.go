@@ -0,0 +1,165 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ProjectRole is the access of a collaborator to the shared project.
+// Viewers only read tasks, editors also create and change them, owners can also delete tasks.
+type ProjectRole string
+
+const (
+	ProjectViewer ProjectRole = "viewer"
+	ProjectEditor ProjectRole = "editor"
+	ProjectOwner  ProjectRole = "owner"
+)
+
+var projectRoleLevel = map[ProjectRole]int{
+	ProjectViewer: 1,
+	ProjectEditor: 2,
+	ProjectOwner:  3,
+}
+
+// Allows reports whether the role grants the required one, empty role is no access.
+func (r ProjectRole) Allows(required ProjectRole) bool {
+	return len(r) > 0 && projectRoleLevel[r] >= projectRoleLevel[required]
+}
+
+type ProjectMember struct {
+	User string      `yaml:"user"`
+	Role ProjectRole `yaml:"role"`
+}
+
+// ProjectShare shares the project of the owner with subprojects like project.sub with other users.
+type ProjectShare struct {
+	Project string `yaml:"project"`
+	// Owner is the name of the user whose tasks are shared, empty for the default user.
+	Owner   string          `yaml:"owner,omitempty"`
+	Members []ProjectMember `yaml:"members"`
+}
+
+func (s *ProjectShare) contains(owner string, project string) bool {
+	if s.Owner != owner {
+		return false
+	}
+	project = strings.ToLower(project)
+	shared := strings.ToLower(s.Project)
+	return project == shared || strings.HasPrefix(project, shared+".")
+}
+
+func (s *ProjectShare) role(user *User) ProjectRole {
+	if user.Name == s.Owner {
+		return ProjectOwner
+	}
+	for _, member := range s.Members {
+		if member.User == user.Name {
+			return member.Role
+		}
+	}
+	return ""
+}
+
+type ProjectShares []ProjectShare
+
+func (shares ProjectShares) Validate(users Users) error {
+	for _, share := range shares {
+		if len(strings.TrimSpace(share.Project)) == 0 {
+			return fmt.Errorf("shared project should not be empty")
+		}
+		if users.ByName(share.Owner) == nil {
+			return fmt.Errorf("shared project %s: owner %q not found", share.Project, share.Owner)
+		}
+		for _, member := range share.Members {
+			if users.ByName(member.User) == nil {
+				return fmt.Errorf("shared project %s: user %q not found", share.Project, member.User)
+			}
+			if _, ok := projectRoleLevel[member.Role]; !ok {
+				return fmt.Errorf("shared project %s: invalid role %q of %s, valid are viewer, editor, owner", share.Project, member.Role, member.User)
+			}
+		}
+	}
+	return nil
+}
+
+// Role returns the access of the user to the task, empty if the task is not visible to the user.
+// The user owns own tasks, the role of shared tasks is the highest one of projects which contain the task.
+func (shares ProjectShares) Role(user *User, task *Task) ProjectRole {
+	if task.Owner == user.Name {
+		return ProjectOwner
+	}
+	result := ProjectRole("")
+	for i := range shares {
+		if !shares[i].contains(task.Owner, task.Project) {
+			continue
+		}
+		if role := shares[i].role(user); role.Allows(ProjectViewer) && !result.Allows(role) {
+			result = role
+		}
+	}
+	return result
+}
+
+// OwnerOf returns the owner of a new task of the user: the sharing user if the user can edit
+// the shared project of the task, otherwise the user.
+func (shares ProjectShares) OwnerOf(user *User, project string) string {
+	for i := range shares {
+		if shares[i].Owner != user.Name && shares[i].contains(shares[i].Owner, project) && shares[i].role(user).Allows(ProjectEditor) {
+			return shares[i].Owner
+		}
+	}
+	return user.Name
+}
+
+// Scopes returns tasks visible to the user: own tasks and projects shared with the user.
+func (shares ProjectShares) Scopes(user *User) []TaskScope {
+	result := []TaskScope{{Owner: user.Name}}
+	for _, share := range shares {
+		if share.Owner != user.Name && share.role(user).Allows(ProjectViewer) {
+			result = append(result, TaskScope{Owner: share.Owner, Project: strings.ToLower(share.Project)})
+		}
+	}
+	return result
+}
+
+// Collaborators returns users sharing the project with the user including the owner, nil if the project is not shared.
+// The user with several roles is listed once with the highest one.
+func (shares ProjectShares) Collaborators(user *User, project string) []ProjectMember {
+	var result []ProjectMember
+	add := func(member ProjectMember) {
+		i := slices.IndexFunc(result, func(added ProjectMember) bool { return added.User == member.User })
+		if i < 0 {
+			result = append(result, member)
+		} else if !result[i].Role.Allows(member.Role) {
+			result[i].Role = member.Role
+		}
+	}
+	for _, share := range shares {
+		if !share.contains(share.Owner, project) || !share.role(user).Allows(ProjectViewer) {
+			continue
+		}
+		add(ProjectMember{User: (&User{Name: share.Owner}).String(), Role: ProjectOwner})
+		for _, member := range share.Members {
+			add(member)
+		}
+	}
+	return result
+}
+
+// TaskScope is a part of tasks of the owner: all of them or the project with subprojects.
+type TaskScope struct {
+	Owner   string
+	Project string
+}
+
+func (s TaskScope) Contains(task *Task) bool {
+	if task.Owner != s.Owner {
+		return false
+	}
+	if len(s.Project) == 0 {
+		return true
+	}
+	project := strings.ToLower(task.Project)
+	return project == s.Project || strings.HasPrefix(project, s.Project+".")
+}
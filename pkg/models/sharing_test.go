@@ -0,0 +1,80 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProjectShares(t *testing.T) {
+	users := Users{{}, {Name: "alice"}, {Name: "bob"}, {Name: "carol"}}
+	shares := ProjectShares{
+		{Project: "Work", Members: []ProjectMember{{User: "alice", Role: ProjectEditor}, {User: "bob", Role: ProjectViewer}}},
+		{Project: "work.infra", Members: []ProjectMember{{User: "bob", Role: ProjectOwner}}},
+	}
+	if err := shares.Validate(users); err != nil {
+		t.Fatalf("valid shares are rejected: %s", err)
+	}
+	for _, invalid := range []ProjectShares{
+		{{Project: " "}},
+		{{Project: "work", Owner: "dave"}},
+		{{Project: "work", Members: []ProjectMember{{User: "dave", Role: ProjectViewer}}}},
+		{{Project: "work", Members: []ProjectMember{{User: "alice", Role: "admin"}}}},
+	} {
+		if err := invalid.Validate(users); err == nil {
+			t.Errorf("invalid shares should be rejected: %+v", invalid)
+		}
+	}
+
+	alice, bob, carol := users[1], users[2], users[3]
+	task := NewTask()
+	task.Project = "work.infra.db"
+	if role := shares.Role(alice, task); role != ProjectEditor {
+		t.Errorf("unexpected role of alice: %q", role)
+	}
+	if role := shares.Role(bob, task); role != ProjectOwner {
+		t.Errorf("highest role should be used: %q", role)
+	}
+	if role := shares.Role(carol, task); role.Allows(ProjectViewer) {
+		t.Errorf("carol should not see the task: %q", role)
+	}
+	task.Project = "workshop"
+	if role := shares.Role(alice, task); len(role) > 0 {
+		t.Errorf("projects with the same prefix should not be shared: %q", role)
+	}
+	task.Owner = "carol"
+	if role := shares.Role(carol, task); role != ProjectOwner {
+		t.Errorf("user should own own tasks: %q", role)
+	}
+
+	if owner := shares.OwnerOf(alice, "work.backend"); owner != "" {
+		t.Errorf("new tasks of the shared project should belong to the sharing user: %q", owner)
+	}
+	if owner := shares.OwnerOf(bob, "work"); owner != "bob" {
+		t.Errorf("viewers should not create tasks in the shared project: %q", owner)
+	}
+
+	scopes := shares.Scopes(bob)
+	if !slices.Equal(scopes, []TaskScope{{Owner: "bob"}, {Project: "work"}, {Project: "work.infra"}}) {
+		t.Errorf("unexpected scopes: %v", scopes)
+	}
+	task = NewTask()
+	task.Project = "WORK.backend"
+	filter := NewDefaultListFilter()
+	filter.Scopes = scopes
+	if tasks := filter.Apply([]*Task{task}); len(tasks) != 1 {
+		t.Errorf("shared tasks should be found: %v", tasks)
+	}
+
+	collaborators := shares.Collaborators(bob, "work.infra")
+	expected := []ProjectMember{
+		{User: "default", Role: ProjectOwner},
+		{User: "alice", Role: ProjectEditor},
+		{User: "bob", Role: ProjectOwner},
+	}
+	if !slices.Equal(collaborators, expected) {
+		t.Errorf("unexpected collaborators: %v", collaborators)
+	}
+	if collaborators := shares.Collaborators(carol, "work"); collaborators != nil {
+		t.Errorf("collaborators should be hidden from other users: %v", collaborators)
+	}
+}
@@ -0,0 +1,185 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// StatusCategory tells how the status is treated: open tasks are in work, closed are done, removed are deleted.
+type StatusCategory string
+
+const (
+	StatusCategoryOpen    StatusCategory = "open"
+	StatusCategoryClosed  StatusCategory = "closed"
+	StatusCategoryRemoved StatusCategory = "removed"
+)
+
+// statusCategoryRank orders categories in lists, it is the historical order of deleted, pending and completed tasks.
+var statusCategoryRank = map[StatusCategory]int{
+	StatusCategoryRemoved: 0,
+	StatusCategoryOpen:    1,
+	StatusCategoryClosed:  2,
+}
+
+// StatusDefinition is a status of the config like in-progress or blocked.
+type StatusDefinition struct {
+	Name     string         `yaml:"name" json:"name"`
+	Category StatusCategory `yaml:"category" json:"category"`
+	Emoji    string         `yaml:"emoji,omitempty" json:"emoji,omitempty"`
+}
+
+type taskStatus string
+
+const (
+	Pending   taskStatus = "pending"
+	Completed taskStatus = "completed"
+	Deleted   taskStatus = "deleted"
+
+	invalid taskStatus = "invalid"
+)
+
+var builtinStatuses = []StatusDefinition{
+	{Name: string(Pending), Category: StatusCategoryOpen, Emoji: "⏳"},
+	{Name: string(Completed), Category: StatusCategoryClosed, Emoji: "✅"},
+	{Name: string(Deleted), Category: StatusCategoryRemoved, Emoji: "🗑️"},
+}
+
+var statusNameRegexp = regexp.MustCompile("^[a-z][a-z0-9_-]*$")
+
+var taskStatuses = slices.Clone(builtinStatuses)
+
+// SetTaskStatuses sets statuses in addition to the builtin ones, it is called once on start.
+// Builtin statuses may be listed to change their emoji or position, but not their category.
+// Statuses are ordered by category and then by the config order, builtin ones go first if not listed.
+func SetTaskStatuses(statuses []StatusDefinition) error {
+	result := []StatusDefinition{}
+	names := map[string]struct{}{}
+	for _, status := range statuses {
+		if !statusNameRegexp.MatchString(status.Name) {
+			return fmt.Errorf("invalid status name %q, it should match %s", status.Name, statusNameRegexp.String())
+		}
+		if _, ok := statusCategoryRank[status.Category]; !ok {
+			return fmt.Errorf("invalid category of status %s: %q, valid are open, closed, removed", status.Name, status.Category)
+		}
+		if _, ok := names[status.Name]; ok {
+			return fmt.Errorf("duplicated status: %s", status.Name)
+		}
+		names[status.Name] = struct{}{}
+		for _, builtin := range builtinStatuses {
+			if builtin.Name == status.Name && builtin.Category != status.Category {
+				return fmt.Errorf("category of builtin status %s should be %s", status.Name, builtin.Category)
+			}
+		}
+		result = append(result, status)
+	}
+	for i := len(builtinStatuses) - 1; i >= 0; i-- {
+		if _, ok := names[builtinStatuses[i].Name]; !ok {
+			result = append([]StatusDefinition{builtinStatuses[i]}, result...)
+		}
+	}
+	slices.SortStableFunc(result, func(a, b StatusDefinition) int {
+		return categoryOrder(a.Category) - categoryOrder(b.Category)
+	})
+	taskStatuses = result
+	return nil
+}
+
+// categoryOrder is the order of categories in the status dropdown and help.
+func categoryOrder(category StatusCategory) int {
+	switch category {
+	case StatusCategoryOpen:
+		return 0
+	case StatusCategoryClosed:
+		return 1
+	}
+	return 2
+}
+
+// GetTaskStatuses returns all known statuses: open ones first, then closed and removed.
+func GetTaskStatuses() []StatusDefinition {
+	return taskStatuses
+}
+
+func lookupStatus(name string) (int, *StatusDefinition) {
+	for i := range taskStatuses {
+		if taskStatuses[i].Name == name {
+			return i, &taskStatuses[i]
+		}
+	}
+	return len(taskStatuses), nil
+}
+
+func NewTaskStatus(status string) (taskStatus, error) {
+	if _, definition := lookupStatus(status); definition != nil {
+		return taskStatus(definition.Name), nil
+	}
+	return invalid, fmt.Errorf("invalid status")
+}
+
+// StatusesOf returns known statuses of the categories.
+func StatusesOf(categories ...StatusCategory) []taskStatus {
+	result := []taskStatus{}
+	for _, definition := range taskStatuses {
+		if slices.Contains(categories, definition.Category) {
+			result = append(result, taskStatus(definition.Name))
+		}
+	}
+	return result
+}
+
+func (ts taskStatus) String() string {
+	return string(ts)
+}
+
+// Emoji returns the emoji of the status, the name for statuses without emoji.
+func (ts taskStatus) Emoji() string {
+	if _, definition := lookupStatus(string(ts)); definition != nil && len(definition.Emoji) > 0 {
+		return definition.Emoji
+	}
+	return string(ts)
+}
+
+// Category returns the category of the status, statuses removed from the config are open.
+func (ts taskStatus) Category() StatusCategory {
+	if _, definition := lookupStatus(string(ts)); definition != nil {
+		return definition.Category
+	}
+	return StatusCategoryOpen
+}
+
+func (ts taskStatus) IsOpen() bool {
+	return ts.Category() == StatusCategoryOpen
+}
+
+func (ts taskStatus) IsClosed() bool {
+	return ts.Category() == StatusCategoryClosed
+}
+
+func (ts taskStatus) IsRemoved() bool {
+	return ts.Category() == StatusCategoryRemoved
+}
+
+// IsClosing reports whether the task is done by the status change, e.g. pending to completed.
+func IsClosing(previous taskStatus, next taskStatus) bool {
+	return !previous.IsClosed() && next.IsClosed()
+}
+
+// StatusRank orders tasks by status: removed, open and closed ones, within a category in the config order.
+func StatusRank(status taskStatus) int {
+	index, _ := lookupStatus(string(status))
+	return statusCategoryRank[status.Category()]*(len(taskStatuses)+1) + index
+}
+
+// UnmarshalJSON accepts statuses removed from the config, so the config change does not break stored tasks.
+func (ts *taskStatus) UnmarshalJSON(data []byte) error {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("expected string")
+	}
+	data = data[1 : len(data)-1]
+	if !statusNameRegexp.Match(data) {
+		return fmt.Errorf("unknown status: %s", string(data))
+	}
+	*ts = taskStatus(data)
+	return nil
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTaskStatuses(t *testing.T) {
+	t.Cleanup(func() { _ = SetTaskStatuses(nil) })
+	if err := SetTaskStatuses([]StatusDefinition{{Name: "completed", Category: StatusCategoryOpen}}); err == nil {
+		t.Errorf("category of builtin status should not be changed")
+	}
+	if err := SetTaskStatuses([]StatusDefinition{{Name: "blocked", Category: "later"}}); err == nil {
+		t.Errorf("unknown category should be rejected")
+	}
+	err := SetTaskStatuses([]StatusDefinition{
+		{Name: "cancelled", Category: StatusCategoryClosed},
+		{Name: "in-progress", Category: StatusCategoryOpen, Emoji: "🚧"},
+		{Name: "pending", Category: StatusCategoryOpen, Emoji: "📥"},
+		{Name: "blocked", Category: StatusCategoryOpen},
+	})
+	if err != nil {
+		t.Fatalf("cant set statuses: %s", err)
+	}
+	names := []string{}
+	for _, status := range GetTaskStatuses() {
+		names = append(names, status.Name)
+	}
+	if !slices.Equal(names, []string{"in-progress", "pending", "blocked", "completed", "cancelled", "deleted"}) {
+		t.Errorf("unexpected statuses order: %v", names)
+	}
+
+	inProgress, err := NewTaskStatus("in-progress")
+	if err != nil {
+		t.Fatalf("cant parse status: %s", err)
+	}
+	if inProgress.Emoji() != "🚧" || !inProgress.IsOpen() || Pending.Emoji() != "📥" {
+		t.Errorf("unexpected status: %s %s", inProgress.Emoji(), inProgress.Category())
+	}
+	if !IsClosing(inProgress, "cancelled") || IsClosing(Completed, "cancelled") {
+		t.Errorf("unexpected closing of the status")
+	}
+
+	var orphan taskStatus
+	if err := json.Unmarshal([]byte(`"review"`), &orphan); err != nil {
+		t.Fatalf("removed status should be loaded: %s", err)
+	}
+	if _, err := NewTaskStatus("review"); err == nil {
+		t.Errorf("unknown status should be rejected")
+	}
+	stored := NewTask()
+	stored.Status = orphan
+	stored.Description = "review the report"
+	if err := stored.Validate(); err != nil {
+		t.Errorf("task with removed status should be loaded: %s", err)
+	}
+	modified := stored.Clone(false)
+	modified.Description = "modified"
+	if err := modified.ValidateChanges(stored); err != nil {
+		t.Errorf("task with removed status should be saved: %s", err)
+	}
+	modified.Status = "in-progres"
+	if err := modified.ValidateChanges(stored); !errors.Is(err, TaskChangesError) {
+		t.Errorf("misspelled status should be rejected on write: %v", err)
+	}
+	if err := stored.ValidateChanges(nil); err == nil {
+		t.Errorf("new task with unknown status should be rejected")
+	}
+
+	tasks := []*Task{}
+	for _, status := range []taskStatus{orphan, Completed, "cancelled", "blocked", Pending, Deleted, inProgress} {
+		task := NewTask()
+		task.Status = status
+		tasks = append(tasks, task)
+	}
+	SortTasks(tasks)
+	sorted := []taskStatus{}
+	for _, task := range tasks {
+		sorted = append(sorted, task.Status)
+	}
+	if !slices.Equal(sorted, []taskStatus{Deleted, inProgress, Pending, "blocked", orphan, Completed, "cancelled"}) {
+		t.Errorf("unexpected tasks order: %v", sorted)
+	}
+
+	filter := NewDefaultListFilter()
+	if shown := filter.Apply(slices.Clone(tasks)); len(shown) != 3 {
+		t.Errorf("open tasks of known statuses should be shown, have %d", len(shown))
+	}
+	parsed, err := ParseHumanInput("list status:cancelled")
+	if err != nil {
+		t.Fatalf("cant parse input: %s", err)
+	}
+	if shown := parsed.Options.ToListFilter().Apply(slices.Clone(tasks)); len(shown) != 1 || shown[0].Status != "cancelled" {
+		t.Errorf("only cancelled tasks should be shown: %v", shown)
+	}
+}
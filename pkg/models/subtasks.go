@@ -0,0 +1,178 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+// CheckTaskParent rejects parents that do not exist or make a cycle. getTask returns nil for not found tasks.
+// Only the task whose parent is changed can close a cycle, so the check is skipped if the parent is the same.
+func CheckTaskParent(previous *Task, task *Task, getTask func(UUID uuid.UUID) (*Task, error)) error {
+	if task.Parent == nil {
+		return nil
+	}
+	if previous != nil && previous.Parent != nil && *previous.Parent == *task.Parent {
+		return nil
+	}
+	visited := map[uuid.UUID]struct{}{}
+	for parentUUID := task.Parent; parentUUID != nil; {
+		if *parentUUID == task.UUID {
+			return fmt.Errorf("%w: task %s is ancestor of its parent %s", TaskParentError, task.UUID, *task.Parent)
+		}
+		if _, ok := visited[*parentUUID]; ok {
+			return fmt.Errorf("%w: ancestors of %s already have a cycle", TaskParentError, *task.Parent)
+		}
+		visited[*parentUUID] = struct{}{}
+		parent, err := getTask(*parentUUID)
+		if err != nil {
+			return fmt.Errorf("cant get parent task: %w", err)
+		}
+		if parent == nil {
+			return fmt.Errorf("%w: task %s not found", TaskParentError, *parentUUID)
+		}
+		parentUUID = parent.Parent
+	}
+	return nil
+}
+
+// TaskTree is a task with its subtasks found in the same task list.
+type TaskTree struct {
+	Task     *Task
+	Children []*TaskTree
+}
+
+type SubtasksProgress struct {
+	Completed int
+	Total     int
+}
+
+func (p SubtasksProgress) String() string {
+	return fmt.Sprintf("%d/%d", p.Completed, p.Total)
+}
+
+// Progress counts all not deleted descendants of the task.
+func (t *TaskTree) Progress() SubtasksProgress {
+	result := SubtasksProgress{}
+	for _, child := range t.Children {
+		if !child.Task.Status.IsRemoved() {
+			result.Total++
+			if child.Task.Status.IsClosed() {
+				result.Completed++
+			}
+		}
+		childProgress := child.Progress()
+		result.Total += childProgress.Total
+		result.Completed += childProgress.Completed
+	}
+	return result
+}
+
+// BuildTaskTrees keeps the order of tasks, tasks whose parent is not in the list become roots.
+func BuildTaskTrees(tasks []*Task) []*TaskTree {
+	nodes := map[uuid.UUID]*TaskTree{}
+	for _, task := range tasks {
+		nodes[task.UUID] = &TaskTree{Task: task}
+	}
+	roots := []*TaskTree{}
+	for _, task := range tasks {
+		node := nodes[task.UUID]
+		if task.Parent != nil {
+			if parent, ok := nodes[*task.Parent]; ok && !isTaskTreeAncestor(node, parent) {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+func isTaskTreeAncestor(ancestor *TaskTree, node *TaskTree) bool {
+	if ancestor == node {
+		return true
+	}
+	for _, child := range ancestor.Children {
+		if isTaskTreeAncestor(child, node) {
+			return true
+		}
+	}
+	return false
+}
+
+// Subtasks returns subtrees of the task children found in tasks.
+func Subtasks(tasks []*Task, UUID uuid.UUID) []*TaskTree {
+	for _, tree := range BuildTaskTrees(tasks) {
+		if found := findTaskTree(tree, UUID); found != nil {
+			return found.Children
+		}
+	}
+	return nil
+}
+
+func findTaskTree(tree *TaskTree, UUID uuid.UUID) *TaskTree {
+	if tree.Task.UUID == UUID {
+		return tree
+	}
+	for _, child := range tree.Children {
+		if found := findTaskTree(child, UUID); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// PendingSubtasks returns all open descendants of the task, including children of closed subtasks.
+func PendingSubtasks(ctx context.Context, repo Repository, UUID uuid.UUID) ([]*Task, error) {
+	result := []*Task{}
+	visited := map[uuid.UUID]struct{}{UUID: {}}
+	queue := []uuid.UUID{UUID}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		filter := NewDefaultListFilter()
+		filter.ShowCompleted = true
+		filter.ShowWaiting = true
+		filter.ShowArchived = true
+		filter.Parent = &parent
+		found, err := repo.Find(ctx, filter, Page{})
+		if err != nil {
+			return nil, fmt.Errorf("cant find subtasks: %w", err)
+		}
+		for _, task := range found.Tasks {
+			if _, ok := visited[task.UUID]; ok {
+				continue
+			}
+			visited[task.UUID] = struct{}{}
+			if task.Status.IsOpen() {
+				result = append(result, task)
+			}
+			queue = append(queue, task.UUID)
+		}
+	}
+	return result, nil
+}
+
+// CompleteSubtasks completes all open descendants of the task on behalf of task.ModifiedBy without saving them.
+// It returns the completed subtasks and the next occurrences of recurring ones, the caller saves them with the task.
+func CompleteSubtasks(ctx context.Context, repo Repository, task *Task) ([]*Task, []*Task, error) {
+	subtasks, err := PendingSubtasks(ctx, repo, task.UUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	next := []*Task{}
+	now := time.Now()
+	for _, subtask := range subtasks {
+		subtask.Status = Completed
+		subtask.ModifiedBy = task.ModifiedBy
+		occurrence, err := subtask.NextOccurrence(now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cant create next occurrence of subtask %s: %w", subtask.UUID, err)
+		}
+		if occurrence != nil {
+			next = append(next, occurrence)
+		}
+	}
+	return subtasks, next, nil
+}
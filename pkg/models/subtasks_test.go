@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"testing"
+)
+
+func TestCheckTaskParent(t *testing.T) {
+	release := NewTask()
+	build := NewTask()
+	build.Parent = &release.UUID
+	tasks := map[uuid.UUID]*Task{release.UUID: release, build.UUID: build}
+	getTask := func(UUID uuid.UUID) (*Task, error) {
+		return tasks[UUID], nil
+	}
+
+	test := NewTask()
+	test.Parent = &build.UUID
+	if err := CheckTaskParent(nil, test, getTask); err != nil {
+		t.Errorf("subtask of subtask should be allowed, have %v", err)
+	}
+
+	cycled := release.Clone(false)
+	cycled.Parent = &build.UUID
+	if err := CheckTaskParent(release, cycled, getTask); !errors.Is(err, TaskParentError) {
+		t.Errorf("cycle should be rejected, have %v", err)
+	}
+
+	missing := uuid.New()
+	orphan := NewTask()
+	orphan.Parent = &missing
+	if err := CheckTaskParent(nil, orphan, getTask); !errors.Is(err, TaskParentError) {
+		t.Errorf("not found parent should be rejected, have %v", err)
+	}
+}
+
+func TestBuildTaskTrees(t *testing.T) {
+	release := NewTask()
+	build := NewTask()
+	build.Parent = &release.UUID
+	build.Status = Completed
+	test := NewTask()
+	test.Parent = &build.UUID
+	docs := NewTask()
+	docs.Parent = &release.UUID
+	removed := NewTask()
+	removed.Parent = &release.UUID
+	removed.Status = Deleted
+	other := NewTask()
+
+	trees := BuildTaskTrees([]*Task{release, build, test, docs, removed, other})
+	if len(trees) != 2 || trees[0].Task != release || trees[1].Task != other {
+		t.Fatalf("roots should be release and other, have %+v", trees)
+	}
+	if len(trees[0].Children) != 3 {
+		t.Errorf("release should have 3 children, have %d", len(trees[0].Children))
+	}
+	if progress := trees[0].Progress(); progress != (SubtasksProgress{Completed: 1, Total: 3}) {
+		t.Errorf("release progress should be 1/3, have %s", progress)
+	}
+	if subtasks := Subtasks([]*Task{release, build, test}, build.UUID); len(subtasks) != 1 || subtasks[0].Task != test {
+		t.Errorf("build should have test subtask, have %+v", subtasks)
+	}
+}
@@ -3,9 +3,8 @@ package models
 import (
 	"fmt"
 	"github.com/google/uuid"
-	"html"
 	"html/template"
-	"regexp"
+	"maps"
 	"slices"
 	"sort"
 	"strings"
@@ -13,14 +12,33 @@ import (
 )
 
 type Task struct {
-	UUID        uuid.UUID  `json:"uuid"`
-	Description string     `json:"description"`
-	Project     string     `json:"project,omitempty"`
-	Tags        []string   `json:"tags,omitempty"`
-	Status      taskStatus `json:"status"`
-	CreatedAt   time.Time  `json:"created_at"`
-	Due         *time.Time `json:"due,omitempty"`
-	Notify      *time.Time `json:"notify,omitempty"`
+	UUID        uuid.UUID         `json:"uuid"`
+	Description string            `json:"description"`
+	Project     string            `json:"project,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Status      taskStatus        `json:"status"`
+	Priority    TaskPriority      `json:"priority,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ModifiedAt  *time.Time        `json:"modified_at,omitempty"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+	Due         *time.Time        `json:"due,omitempty"`
+	Notify      *time.Time        `json:"notify,omitempty"`
+	Wait        *time.Time        `json:"wait,omitempty"`
+	Scheduled   *time.Time        `json:"scheduled,omitempty"`
+	Recur       string            `json:"recur,omitempty"`
+	Parent      *uuid.UUID        `json:"parent,omitempty"`
+	Depends     []uuid.UUID       `json:"depends,omitempty"`
+	Annotations []TaskAnnotation  `json:"annotations,omitempty"`
+	Checklist   []ChecklistItem   `json:"checklist,omitempty"`
+	Attachments []TaskAttachment  `json:"attachments,omitempty"`
+	Intervals   []TimeInterval    `json:"intervals,omitempty"`
+	UDA         map[string]string `json:"uda,omitempty"`
+	ModifiedBy  string            `json:"modified_by,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Revision    int64             `json:"revision"`
+
+	// BlockedBy is filled by ResolveBlockers and is not stored.
+	BlockedBy []*Task `json:"-"`
 }
 
 func NewTask() *Task {
@@ -33,69 +51,29 @@ func NewTask() *Task {
 
 const ProjectSelectorEmpty = "__empty__"
 
-func NewTaskStatus(status string) (taskStatus, error) {
-	switch status {
-	case "completed":
-		return Completed, nil
-	case "deleted":
-		return Deleted, nil
-	case "pending":
-		return Pending, nil
-	}
-	return invalid, fmt.Errorf("invalid status")
+// HtmlDescription renders the markdown description for the web ui.
+func (t *Task) HtmlDescription() template.HTML {
+	return template.HTML(renderMarkdown(t.Description, markdownWeb))
 }
 
-type taskStatus string
-
-func (ts *taskStatus) String() string {
-	return string(*ts)
+// TelegramDescription renders the markdown description for telegram HTML parse mode.
+func (t *Task) TelegramDescription() template.HTML {
+	return template.HTML(renderMarkdown(t.Description, markdownTelegram))
 }
 
-func (ts *taskStatus) Emoji() string {
-	switch string(*ts) {
-	case "completed":
-		return "✅"
-	case "deleted":
-		return "🗑️"
-	case "pending":
-		return "⏳"
-	}
-	return string(*ts)
+// Summary is the first line of the description, it is used where a task takes one line.
+func (t *Task) Summary() string {
+	summary, _, _ := strings.Cut(strings.TrimSpace(t.Description), "\n")
+	return strings.TrimSpace(summary)
 }
 
-func (ts *taskStatus) UnmarshalJSON(data []byte) error {
-	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
-		return fmt.Errorf("expected string")
-	}
-	data = data[1 : len(data)-1]
-	switch string(data) {
-	case string(Pending):
-		*ts = Pending
-		return nil
-	case string(Completed):
-		*ts = Completed
-		return nil
-	case string(Deleted):
-		*ts = Deleted
-		return nil
-	default:
-		return fmt.Errorf("unknown status: %s", string(data))
-	}
+func (t *Task) TelegramSummary() template.HTML {
+	return template.HTML(renderMarkdownInline(t.Summary(), markdownTelegram))
 }
 
-const (
-	Pending   taskStatus = "pending"
-	Completed taskStatus = "completed"
-	Deleted   taskStatus = "deleted"
-
-	invalid taskStatus = "invalid"
-)
-
-var linkRegexp = regexp.MustCompile("(https?://([^/]+)\\S*)")
-
-func (t *Task) HtmlDescription() template.HTML {
-	result := linkRegexp.ReplaceAllString(html.EscapeString(t.Description), `<a href="$1" target="_blank">$2</a>`)
-	return template.HTML(result)
+// IsWaiting reports whether the task is hidden until its wait date.
+func (t *Task) IsWaiting(now time.Time) bool {
+	return t.Wait != nil && t.Wait.After(now)
 }
 
 func (t *Task) Validate() error {
@@ -105,16 +83,71 @@ func (t *Task) Validate() error {
 	if len(t.Description) == 0 {
 		return fmt.Errorf("description should not be empty")
 	}
-	if t.Status != Pending && t.Status != Deleted && t.Status != Completed {
-		return fmt.Errorf("unknown status: %s", t.Status)
+	if len(t.Status) == 0 {
+		return fmt.Errorf("status should not be empty")
 	}
 	if t.CreatedAt.IsZero() {
 		return fmt.Errorf("created at should not be zero")
 	}
+	if len(t.Recur) > 0 {
+		if _, err := ParseRecurrence(t.Recur); err != nil {
+			return fmt.Errorf("invalid recur: %w", err)
+		}
+		if t.Due == nil && t.Notify == nil {
+			return fmt.Errorf("recurring task should have due or notify")
+		}
+	}
+	if len(t.Priority) > 0 {
+		if priority, err := ParsePriority(string(t.Priority)); err != nil || priority != t.Priority {
+			return fmt.Errorf("unknown priority: %s", t.Priority)
+		}
+	}
+	if t.Parent != nil && *t.Parent == t.UUID {
+		return fmt.Errorf("task cant be parent of itself")
+	}
+	if slices.Contains(t.Depends, t.UUID) {
+		return fmt.Errorf("task cant depend on itself")
+	}
+	for _, annotation := range t.Annotations {
+		if err := annotation.validate(); err != nil {
+			return fmt.Errorf("invalid annotation: %w", err)
+		}
+	}
+	for _, item := range t.Checklist {
+		if err := item.validate(); err != nil {
+			return fmt.Errorf("invalid checklist: %w", err)
+		}
+	}
+	for _, attachment := range t.Attachments {
+		if err := attachment.validate(); err != nil {
+			return fmt.Errorf("invalid attachment: %w", err)
+		}
+	}
+	if err := validateIntervals(t.Intervals); err != nil {
+		return fmt.Errorf("invalid intervals: %w", err)
+	}
 
 	return nil
 }
 
+// ValidateChanges checks values changed since the previous revision against the config, it is called on write.
+// Validate does not check them, so stored tasks are loaded after the config change.
+func (t *Task) ValidateChanges(previous *Task) error {
+	var previousUDA map[string]string
+	if previous != nil {
+		previousUDA = previous.UDA
+	}
+	if previous == nil || previous.Status != t.Status {
+		if _, err := NewTaskStatus(string(t.Status)); err != nil {
+			return fmt.Errorf("%w: unknown status: %s", TaskChangesError, t.Status)
+		}
+	}
+	if err := validateUDA(previousUDA, t.UDA); err != nil {
+		return fmt.Errorf("%w: invalid uda: %w", TaskChangesError, err)
+	}
+	return nil
+}
+
 func (t *Task) Unify() {
 	t.Project = strings.TrimSpace(strings.ToLower(t.Project))
 
@@ -134,6 +167,34 @@ func (t *Task) Unify() {
 		notify := *t.Due
 		t.Notify = &notify
 	}
+	if priority, err := ParsePriority(string(t.Priority)); err == nil {
+		t.Priority = priority
+	}
+	if recurrence, err := ParseRecurrence(t.Recur); err == nil {
+		t.Recur = recurrence.String()
+	}
+	if len(t.Depends) > 0 {
+		depends := slices.Clone(t.Depends)
+		slices.SortFunc(depends, func(a, b uuid.UUID) int {
+			return strings.Compare(a.String(), b.String())
+		})
+		t.Depends = slices.Compact(depends)
+	} else {
+		t.Depends = nil
+	}
+	t.Annotations = unifyAnnotations(t.Annotations)
+	t.UDA = unifyUDA(t.UDA)
+	t.Checklist = unifyChecklist(t.Checklist)
+	if len(t.Attachments) == 0 {
+		t.Attachments = nil
+	}
+	if len(t.Intervals) == 0 {
+		t.Intervals = nil
+	}
+	// closed task is not tracked anymore
+	if !t.Status.IsOpen() && t.IsActive() {
+		_ = t.StopTracking(time.Now())
+	}
 }
 
 func (t *Task) Clone(newUuid bool) *Task {
@@ -145,16 +206,91 @@ func (t *Task) Clone(newUuid bool) *Task {
 	for _, t := range t.Tags {
 		tags = append(tags, t)
 	}
+	var depends []uuid.UUID
+	if len(t.Depends) > 0 {
+		depends = slices.Clone(t.Depends)
+	}
+	// tracked time and checklist progress belong to the task, copies start from scratch
+	var intervals []TimeInterval
+	checklist := slices.Clone(t.Checklist)
+	if !newUuid {
+		intervals = slices.Clone(t.Intervals)
+	} else {
+		for i := range checklist {
+			checklist[i].Checked = false
+		}
+	}
 	return &Task{
 		UUID:        UUID,
 		Description: t.Description,
 		Project:     t.Project,
 		Status:      t.Status,
+		Priority:    t.Priority,
 		Tags:        tags,
 		CreatedAt:   t.CreatedAt,
+		ModifiedAt:  t.ModifiedAt,
+		CompletedAt: t.CompletedAt,
 		Due:         t.Due,
 		Notify:      t.Notify,
+		Wait:        t.Wait,
+		Scheduled:   t.Scheduled,
+		Recur:       t.Recur,
+		Parent:      t.Parent,
+		Depends:     depends,
+		Annotations: slices.Clone(t.Annotations),
+		Checklist:   checklist,
+		Attachments: slices.Clone(t.Attachments),
+		Intervals:   intervals,
+		UDA:         maps.Clone(t.UDA),
+		ModifiedBy:  t.ModifiedBy,
+		Owner:       t.Owner,
+		Revision:    t.Revision,
+	}
+}
+
+// NextRevision checks that the task is based on the stored previous revision and bumps the revision.
+// previous is nil if the task is not stored yet.
+func (t *Task) NextRevision(previous *Task) error {
+	if previous == nil {
+		t.Revision = 1
+		return nil
+	}
+	if previous.Revision != t.Revision {
+		return &TaskRevisionConflictError{UUID: t.UUID, Expected: t.Revision, Actual: previous.Revision}
 	}
+	t.Revision++
+	return nil
+}
+
+// UpdateTimestamps is called by repositories on every insert: ModifiedAt is set when the task is changed
+// and CompletedAt when it becomes closed. Timestamps of a new task are kept if set, e.g. on import.
+func (t *Task) UpdateTimestamps(previous *Task, now time.Time) error {
+	if !t.Status.IsClosed() {
+		t.CompletedAt = nil
+	} else if previous != nil && previous.Status.IsClosed() {
+		// tasks completed before timestamps were recorded keep unknown completion time
+		if previous.CompletedAt != nil {
+			t.CompletedAt = previous.CompletedAt
+		}
+	} else if previous != nil || t.CompletedAt == nil {
+		t.CompletedAt = &now
+	}
+	if previous == nil {
+		if t.ModifiedAt == nil {
+			t.ModifiedAt = &now
+		}
+		return nil
+	}
+	changes, err := DiffTasks(previous, t)
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		t.ModifiedAt = &now
+	} else {
+		t.ModifiedAt = previous.ModifiedAt
+	}
+	return nil
 }
 
 type TaskGroup struct {
@@ -162,6 +298,7 @@ type TaskGroup struct {
 	Tasks []*Task
 }
 
+// GroupTasksByProject keeps the order of tasks inside each group.
 func GroupTasksByProject(tasks []*Task) []TaskGroup {
 	result := map[string][]*Task{}
 	for _, t := range tasks {
@@ -169,11 +306,10 @@ func GroupTasksByProject(tasks []*Task) []TaskGroup {
 		if len(t.Project) > 0 {
 			project = t.Project
 		}
-		result[project] = append(result[t.Project], t)
+		result[project] = append(result[project], t)
 	}
 	groups := []TaskGroup{}
 	for g, ts := range result {
-		SortTasks(ts)
 		groups = append(groups, TaskGroup{g, ts})
 	}
 	sort.Slice(groups, func(i, j int) bool {
@@ -186,59 +322,48 @@ func GroupTasksByProject(tasks []*Task) []TaskGroup {
 	return groups
 }
 
-func compareStatus(status taskStatus) int {
-	switch status {
-	case Pending:
-		return 0
-	case Completed:
-		return 1
-	case Deleted:
-		return -1
-
-	}
-	panic("invalid status")
+func SortTasks(tasks []*Task) {
+	slices.SortFunc(tasks, compareTasks)
 }
 
-func SortTasks(tasks []*Task) {
-	slices.SortFunc(tasks, func(a, b *Task) int {
-		if compareStatus(a.Status) < compareStatus(b.Status) {
-			return -1
-		}
-		if compareStatus(a.Status) > compareStatus(b.Status) {
-			return 1
-		}
-		if a.Due != nil && b.Due != nil {
-			if a.Due.Before(*b.Due) {
-				return -1
-			}
-			if b.Due.Before(*a.Due) {
-				return 1
-			}
-		} else if a.Due != nil {
-			return -1
-		} else if b.Due != nil {
-			return 1
-		}
-		if a.Project < b.Project {
-			return -1
-		}
-		if a.Project > b.Project {
-			return 1
-		}
-		if a.CreatedAt.Before(b.CreatedAt) {
-			return -1
-		}
-		if b.CreatedAt.Before(a.CreatedAt) {
-			return 1
-		}
-		if a.UUID.String() < b.UUID.String() {
+func compareTasks(a, b *Task) int {
+	if StatusRank(a.Status) < StatusRank(b.Status) {
+		return -1
+	}
+	if StatusRank(a.Status) > StatusRank(b.Status) {
+		return 1
+	}
+	if a.Due != nil && b.Due != nil {
+		if a.Due.Before(*b.Due) {
 			return -1
 		}
-		if a.UUID.String() > b.UUID.String() {
+		if b.Due.Before(*a.Due) {
 			return 1
 		}
-		return 0
-	})
+	} else if a.Due != nil {
+		return -1
+	} else if b.Due != nil {
+		return 1
+	}
+	if a.Project < b.Project {
+		return -1
+	}
+	if a.Project > b.Project {
+		return 1
+	}
+	if a.CreatedAt.Before(b.CreatedAt) {
+		return -1
+	}
+	if b.CreatedAt.Before(a.CreatedAt) {
+		return 1
+	}
+	if a.UUID.String() < b.UUID.String() {
+		return -1
+	}
+	if a.UUID.String() > b.UUID.String() {
+		return 1
+	}
+	return 0
 }
 
 func UniqProjects(tasks []*Task) map[string]int {
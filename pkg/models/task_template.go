@@ -0,0 +1,129 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TaskTemplate is a named bundle of tasks like onboarding or release checklist, templates are configured in the config file.
+type TaskTemplate struct {
+	Name        string   `yaml:"name,omitempty"`
+	Description string   `yaml:"description"`
+	Project     string   `yaml:"project,omitempty"`
+	Tags        []string `yaml:"tags,omitempty"`
+	Priority    string   `yaml:"priority,omitempty"`
+	// Due and Notify are offsets from the creation time: 4h, 3d, 2w, 1m.
+	Due       string   `yaml:"due,omitempty"`
+	Notify    string   `yaml:"notify,omitempty"`
+	Checklist []string `yaml:"checklist,omitempty"`
+	// Subtasks are created as children of the task, they inherit the project if it is not set.
+	Subtasks []TaskTemplate `yaml:"subtasks,omitempty"`
+}
+
+var taskTemplateNameRegexp = regexp.MustCompile("^[a-z0-9][a-z0-9_-]*$")
+
+var taskTemplates []TaskTemplate
+
+// SetTaskTemplates sets templates used by human input and the web ui, it is called once on start.
+func SetTaskTemplates(templates []TaskTemplate) error {
+	names := map[string]struct{}{}
+	for i := range templates {
+		if !taskTemplateNameRegexp.MatchString(templates[i].Name) {
+			return fmt.Errorf("invalid template name %q, it should match %s", templates[i].Name, taskTemplateNameRegexp.String())
+		}
+		if _, ok := names[templates[i].Name]; ok {
+			return fmt.Errorf("duplicated template: %s", templates[i].Name)
+		}
+		names[templates[i].Name] = struct{}{}
+		if err := templates[i].validate(); err != nil {
+			return fmt.Errorf("invalid template %s: %w", templates[i].Name, err)
+		}
+	}
+	taskTemplates = templates
+	return nil
+}
+
+func GetTaskTemplates() []TaskTemplate {
+	return taskTemplates
+}
+
+func FindTaskTemplate(name string) *TaskTemplate {
+	for i := range taskTemplates {
+		if taskTemplates[i].Name == name {
+			return &taskTemplates[i]
+		}
+	}
+	return nil
+}
+
+func (t *TaskTemplate) validate() error {
+	if len(strings.TrimSpace(t.Description)) == 0 {
+		return fmt.Errorf("description should not be empty")
+	}
+	if _, err := ParsePriority(t.Priority); err != nil {
+		return err
+	}
+	if _, err := parseTemplateOffset(t.Due, time.Now()); err != nil {
+		return fmt.Errorf("invalid due: %w", err)
+	}
+	if _, err := parseTemplateOffset(t.Notify, time.Now()); err != nil {
+		return fmt.Errorf("invalid notify: %w", err)
+	}
+	for i := range t.Subtasks {
+		if err := t.Subtasks[i].validate(); err != nil {
+			return fmt.Errorf("invalid subtask %q: %w", t.Subtasks[i].Description, err)
+		}
+	}
+	return nil
+}
+
+// parseTemplateOffset accepts durations (4h, 90m) and counted periods of recur (3d, 2w, 1m), nil for empty offset.
+func parseTemplateOffset(offset string, now time.Time) (*time.Time, error) {
+	if len(strings.TrimSpace(offset)) == 0 {
+		return nil, nil
+	}
+	if duration, err := time.ParseDuration(offset); err == nil {
+		result := now.Add(duration)
+		return &result, nil
+	}
+	recurrence, err := ParseRecurrence(offset)
+	if err != nil {
+		return nil, fmt.Errorf("cant parse offset: %s", offset)
+	}
+	result := recurrence.Next(now)
+	return &result, nil
+}
+
+// Instantiate creates tasks of the template, the first one is the root, others are its descendants.
+func (t *TaskTemplate) Instantiate(now time.Time) []*Task {
+	return t.instantiate(now, nil, "")
+}
+
+func (t *TaskTemplate) instantiate(now time.Time, parent *Task, parentProject string) []*Task {
+	task := NewTask()
+	task.CreatedAt = now
+	task.Description = t.Description
+	task.Project = t.Project
+	if len(task.Project) == 0 {
+		task.Project = parentProject
+	}
+	task.Tags = append([]string{}, t.Tags...)
+	// the template is validated by SetTaskTemplates
+	task.Priority, _ = ParsePriority(t.Priority)
+	task.Due, _ = parseTemplateOffset(t.Due, now)
+	task.Notify, _ = parseTemplateOffset(t.Notify, now)
+	for _, item := range t.Checklist {
+		task.AddChecklistItem(item)
+	}
+	if parent != nil {
+		parentUUID := parent.UUID
+		task.Parent = &parentUUID
+	}
+	result := []*Task{task}
+	for i := range t.Subtasks {
+		result = append(result, t.Subtasks[i].instantiate(now, task, task.Project)...)
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTaskTemplates(t *testing.T) {
+	t.Cleanup(func() { _ = SetTaskTemplates(nil) })
+	if err := SetTaskTemplates([]TaskTemplate{{Name: "Bad name", Description: "x"}}); err == nil {
+		t.Errorf("invalid name should be rejected")
+	}
+	if err := SetTaskTemplates([]TaskTemplate{{Name: "x", Description: "x", Due: "tomorrow"}}); err == nil {
+		t.Errorf("invalid offset should be rejected")
+	}
+	if err := SetTaskTemplates([]TaskTemplate{{Name: "x", Description: "x"}, {Name: "x", Description: "y"}}); err == nil {
+		t.Errorf("duplicated name should be rejected")
+	}
+	err := SetTaskTemplates([]TaskTemplate{{
+		Name:        "onboarding",
+		Description: "onboard",
+		Project:     "work",
+		Tags:        []string{"hiring"},
+		Due:         "2w",
+		Checklist:   []string{"accounts", "docs"},
+		Subtasks: []TaskTemplate{
+			{Description: "intro", Notify: "4h"},
+			{Description: "review", Project: "hr"},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("cant set templates: %s", err)
+	}
+
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	tasks := FindTaskTemplate("onboarding").Instantiate(now)
+	if len(tasks) != 3 {
+		t.Fatalf("unexpected tasks count: %d", len(tasks))
+	}
+	root := tasks[0]
+	if root.Parent != nil || root.Due == nil || !root.Due.Equal(now.AddDate(0, 0, 14)) || root.ChecklistProgress() != "0/2" {
+		t.Errorf("unexpected root task: %+v", root)
+	}
+	if *tasks[1].Parent != root.UUID || tasks[1].Project != "work" || !tasks[1].Notify.Equal(now.Add(4*time.Hour)) {
+		t.Errorf("unexpected subtask: %+v", tasks[1])
+	}
+	if tasks[2].Project != "hr" {
+		t.Errorf("subtask project should not be overridden: %s", tasks[2].Project)
+	}
+
+	parsed, err := ParseHumanInput("from-template onboarding +urgent welcome alice")
+	if err != nil {
+		t.Fatalf("cant parse input: %s", err)
+	}
+	tasks, err = parsed.Options.NewTasks(now)
+	if err != nil {
+		t.Fatalf("cant create tasks: %s", err)
+	}
+	if len(tasks) != 3 || tasks[0].Description != "welcome alice" || len(tasks[0].Tags) != 2 {
+		t.Errorf("unexpected root task: %+v", tasks[0])
+	}
+
+	if _, err := ParseHumanInput("add template:missing something"); err == nil {
+		t.Errorf("unknown template should be rejected")
+	}
+}
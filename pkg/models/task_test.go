@@ -1,9 +1,11 @@
 package models
 
 import (
+	"errors"
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 )
 
 func TestTask_Unify_tags(t1 *testing.T) {
@@ -44,3 +46,66 @@ func TestTask_Unify_tags(t1 *testing.T) {
 		})
 	}
 }
+
+func TestTask_NextRevision(t1 *testing.T) {
+	t := NewTask()
+	if err := t.NextRevision(nil); err != nil || t.Revision != 1 {
+		t1.Fatalf("new task should get first revision, have %d, %v", t.Revision, err)
+	}
+	stored := t.Clone(false)
+	if err := t.NextRevision(stored); err != nil || t.Revision != 2 {
+		t1.Fatalf("task based on stored revision should be accepted, have %d, %v", t.Revision, err)
+	}
+	stale := stored.Clone(false)
+	stored.Revision = 2
+	err := stale.NextRevision(stored)
+	if !errors.Is(err, TaskConflictError) {
+		t1.Fatalf("stale task should be rejected with conflict, have %v", err)
+	}
+}
+
+func TestTask_UpdateTimestamps(t *testing.T) {
+	created := time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC)
+	task := NewTask()
+	task.Description = "report"
+	if err := task.UpdateTimestamps(nil, created); err != nil {
+		t.Fatalf("UpdateTimestamps() error = %v", err)
+	}
+	if task.ModifiedAt == nil || !task.ModifiedAt.Equal(created) || task.CompletedAt != nil {
+		t.Errorf("new task should be modified at creation, have %v %v", task.ModifiedAt, task.CompletedAt)
+	}
+
+	previous := task.Clone(false)
+	unchanged := task.Clone(false)
+	if err := unchanged.UpdateTimestamps(previous, created.Add(time.Hour)); err != nil {
+		t.Fatalf("UpdateTimestamps() error = %v", err)
+	}
+	if !unchanged.ModifiedAt.Equal(created) {
+		t.Errorf("unchanged task should keep modification time, have %v", unchanged.ModifiedAt)
+	}
+
+	completed := created.Add(2 * time.Hour)
+	task.Status = Completed
+	if err := task.UpdateTimestamps(previous, completed); err != nil {
+		t.Fatalf("UpdateTimestamps() error = %v", err)
+	}
+	if !task.ModifiedAt.Equal(completed) || task.CompletedAt == nil || !task.CompletedAt.Equal(completed) {
+		t.Errorf("completed task should have completion time, have %v %v", task.ModifiedAt, task.CompletedAt)
+	}
+
+	filter := NewDefaultListFilter()
+	filter.ShowCompleted = true
+	filter.Completed = TimeRange{After: &created}
+	if tasks := filter.Apply([]*Task{task, previous}); len(tasks) != 1 || tasks[0] != task {
+		t.Errorf("only completed task should be in completed range, have %+v", tasks)
+	}
+
+	previous = task.Clone(false)
+	task.Status = Pending
+	if err := task.UpdateTimestamps(previous, completed.Add(time.Hour)); err != nil {
+		t.Fatalf("UpdateTimestamps() error = %v", err)
+	}
+	if task.CompletedAt != nil {
+		t.Errorf("reopened task should not have completion time, have %v", task.CompletedAt)
+	}
+}
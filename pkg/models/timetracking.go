@@ -0,0 +1,135 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// TimeInterval is a tracked period of work on the task, End is nil while the task is tracked.
+type TimeInterval struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"`
+}
+
+func (i TimeInterval) validate() error {
+	if i.Start.IsZero() {
+		return fmt.Errorf("interval start should not be zero")
+	}
+	if i.End != nil && i.End.Before(i.Start) {
+		return fmt.Errorf("interval end should not be before start")
+	}
+	return nil
+}
+
+// Duration returns the part of the interval within [from, to), the open interval lasts until now.
+func (i TimeInterval) Duration(from, to, now time.Time) time.Duration {
+	end := now
+	if i.End != nil {
+		end = *i.End
+	}
+	start := i.Start
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// ActiveSince returns start of the running interval or nil if the task is not tracked.
+func (t *Task) ActiveSince() *time.Time {
+	if len(t.Intervals) == 0 || t.Intervals[len(t.Intervals)-1].End != nil {
+		return nil
+	}
+	return &t.Intervals[len(t.Intervals)-1].Start
+}
+
+func (t *Task) IsActive() bool {
+	return t.ActiveSince() != nil
+}
+
+func (t *Task) StartTracking(now time.Time) error {
+	if t.IsActive() {
+		return fmt.Errorf("%w: task is already started", TaskTrackingError)
+	}
+	if !t.Status.IsOpen() {
+		return fmt.Errorf("%w: only open task can be started", TaskTrackingError)
+	}
+	t.Intervals = append(t.Intervals, TimeInterval{Start: now})
+	return nil
+}
+
+func (t *Task) StopTracking(now time.Time) error {
+	if !t.IsActive() {
+		return fmt.Errorf("%w: task is not started", TaskTrackingError)
+	}
+	last := &t.Intervals[len(t.Intervals)-1]
+	if now.Before(last.Start) {
+		now = last.Start
+	}
+	last.End = &now
+	return nil
+}
+
+// TrackedDuration sums tracked time of the task within [from, to).
+func (t *Task) TrackedDuration(from, to, now time.Time) time.Duration {
+	result := time.Duration(0)
+	for _, interval := range t.Intervals {
+		result += interval.Duration(from, to, now)
+	}
+	return result
+}
+
+// TrackedTime is the formatted all time tracked duration, empty if the task was never tracked.
+func (t *Task) TrackedTime() string {
+	if len(t.Intervals) == 0 {
+		return ""
+	}
+	now := time.Now()
+	return FormatTrackedDuration(t.TrackedDuration(t.Intervals[0].Start, now, now))
+}
+
+func validateIntervals(intervals []TimeInterval) error {
+	for i, interval := range intervals {
+		if err := interval.validate(); err != nil {
+			return err
+		}
+		if interval.End == nil && i != len(intervals)-1 {
+			return fmt.Errorf("only the last interval can be running")
+		}
+	}
+	return nil
+}
+
+// StartTracking starts the task and stops all other started tasks, only one task is tracked at a time.
+// All of them are saved at once, it returns the stopped tasks.
+func StartTracking(ctx context.Context, repo Repository, task *Task, now time.Time) ([]*Task, error) {
+	if err := task.StartTracking(now); err != nil {
+		return nil, err
+	}
+	filter := NewDefaultListFilter()
+	filter.ShowWaiting = true
+	filter.ShowArchived = true
+	result, err := repo.Find(ctx, filter, Page{})
+	if err != nil {
+		return nil, fmt.Errorf("cant find started tasks: %w", err)
+	}
+	stopped := []*Task{}
+	for _, active := range slices.DeleteFunc(result.Tasks, func(t *Task) bool { return !t.IsActive() || t.UUID == task.UUID }) {
+		if err := active.StopTracking(now); err != nil {
+			return nil, err
+		}
+		active.ModifiedBy = task.ModifiedBy
+		stopped = append(stopped, active)
+	}
+	if err := repo.Insert(ctx, append(stopped, task)...); err != nil {
+		return nil, err
+	}
+	return stopped, nil
+}
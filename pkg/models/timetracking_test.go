@@ -0,0 +1,43 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTask_Tracking(t *testing.T) {
+	start := time.Date(2024, 10, 15, 23, 0, 0, 0, time.Local)
+	task := NewTask()
+	task.Project = "client"
+	task.Tags = []string{"dev", "ops"}
+	if err := task.StartTracking(start); err != nil {
+		t.Fatalf("StartTracking() error = %v", err)
+	}
+	if err := task.StartTracking(start); !errors.Is(err, TaskTrackingError) {
+		t.Errorf("second start should be rejected, have %v", err)
+	}
+	if err := task.StopTracking(start.Add(2 * time.Hour)); err != nil {
+		t.Fatalf("StopTracking() error = %v", err)
+	}
+	if task.IsActive() {
+		t.Errorf("task should not be active after stop")
+	}
+
+	from := time.Date(2024, 10, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2024, 11, 1, 0, 0, 0, 0, time.Local)
+	byDay := BuildTimeReport([]*Task{task}, ReportByDay, from, to, to)
+	expected := []TimeReportRow{{Group: "2024-10-15", Duration: time.Hour}, {Group: "2024-10-16", Duration: time.Hour}}
+	if len(byDay.Rows) != 2 || byDay.Rows[0] != expected[0] || byDay.Rows[1] != expected[1] || byDay.Total != 2*time.Hour {
+		t.Errorf("interval should be split by days, have %+v", byDay)
+	}
+	byTag := BuildTimeReport([]*Task{task}, ReportByTag, from, to, to)
+	if len(byTag.Rows) != 2 || byTag.Total != 2*time.Hour {
+		t.Errorf("task should be counted in every tag, have %+v", byTag)
+	}
+
+	task.Status = Completed
+	if err := task.StartTracking(to); !errors.Is(err, TaskTrackingError) {
+		t.Errorf("completed task should not be started, have %v", err)
+	}
+}
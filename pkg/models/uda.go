@@ -0,0 +1,248 @@
+package models
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type UDAType string
+
+const (
+	UDAString UDAType = "string"
+	UDANumber UDAType = "number"
+	UDADate   UDAType = "date"
+	UDAEnum   UDAType = "enum"
+)
+
+// UDADefinition describes a user defined attribute, the schema is configured in the config file.
+type UDADefinition struct {
+	Name  string  `yaml:"name" json:"name"`
+	Label string  `yaml:"label,omitempty" json:"label,omitempty"`
+	Type  UDAType `yaml:"type" json:"type"`
+	// Values are allowed values of enum attribute.
+	Values []string `yaml:"values,omitempty" json:"values,omitempty"`
+}
+
+var udaNameRegexp = regexp.MustCompile("^[a-z][a-z0-9_]*$")
+
+// reservedUDANames are task fields and human input options, attributes cant shadow them.
+var reservedUDANames = []string{
+	"uuid", "description", "project", "tags", "status", "priority", "due", "notify", "wait", "scheduled",
+	"recur", "parent", "depends", "annotations", "checklist", "attachments", "intervals", "uda", "subtasks", "template", "sort", "completed", "modified",
+	"created_at", "modified_at", "completed_at", "modified_by", "revision",
+}
+
+func (d *UDADefinition) Title() string {
+	if len(d.Label) > 0 {
+		return d.Label
+	}
+	return d.Name
+}
+
+func (d *UDADefinition) validate() error {
+	if !udaNameRegexp.MatchString(d.Name) {
+		return fmt.Errorf("invalid name %q, it should match %s", d.Name, udaNameRegexp.String())
+	}
+	if slices.Contains(reservedUDANames, d.Name) {
+		return fmt.Errorf("name %s is reserved", d.Name)
+	}
+	switch d.Type {
+	case UDAString, UDANumber, UDADate:
+		if len(d.Values) > 0 {
+			return fmt.Errorf("values are allowed only for enum")
+		}
+	case UDAEnum:
+		if len(d.Values) == 0 {
+			return fmt.Errorf("enum should have values")
+		}
+		for _, value := range d.Values {
+			if len(strings.TrimSpace(value)) == 0 || strings.ContainsFunc(value, unicode.IsSpace) {
+				return fmt.Errorf("enum value %q should not be empty or contain spaces", value)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown type %q, expected string, number, date or enum", d.Type)
+	}
+	return nil
+}
+
+// Parse converts user input to the stored value: numbers are normalized, dates are stored in RFC3339
+// and accept the same formats as due.
+func (d *UDADefinition) Parse(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return "", fmt.Errorf("empty value")
+	}
+	switch d.Type {
+	case UDANumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("expected number: %s", value)
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	case UDADate:
+		date, err := parseHumanInputTime(value)
+		if err != nil {
+			return "", err
+		}
+		return date.Format(time.RFC3339), nil
+	case UDAEnum:
+		for _, allowed := range d.Values {
+			if strings.EqualFold(allowed, value) {
+				return allowed, nil
+			}
+		}
+		return "", fmt.Errorf("expected one of %s: %s", strings.Join(d.Values, ", "), value)
+	}
+	return value, nil
+}
+
+func (d *UDADefinition) check(value string) error {
+	if len(value) == 0 {
+		return fmt.Errorf("empty value")
+	}
+	switch d.Type {
+	case UDANumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("expected number: %s", value)
+		}
+	case UDADate:
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Errorf("expected RFC3339 date: %s", value)
+		}
+	case UDAEnum:
+		if !slices.Contains(d.Values, value) {
+			return fmt.Errorf("expected one of %s: %s", strings.Join(d.Values, ", "), value)
+		}
+	}
+	return nil
+}
+
+type UDASchema []UDADefinition
+
+func (s UDASchema) Lookup(name string) *UDADefinition {
+	for i := range s {
+		if s[i].Name == name {
+			return &s[i]
+		}
+	}
+	return nil
+}
+
+var udaSchema UDASchema
+
+// SetUDASchema sets the schema used by Validate, human input and templates, it is called once on start.
+func SetUDASchema(schema UDASchema) error {
+	names := map[string]struct{}{}
+	for i := range schema {
+		if err := schema[i].validate(); err != nil {
+			return fmt.Errorf("invalid uda %s: %w", schema[i].Name, err)
+		}
+		if _, ok := names[schema[i].Name]; ok {
+			return fmt.Errorf("duplicated uda: %s", schema[i].Name)
+		}
+		names[schema[i].Name] = struct{}{}
+	}
+	udaSchema = slices.Clone(schema)
+	return nil
+}
+
+func GetUDASchema() UDASchema {
+	return udaSchema
+}
+
+// validateUDA checks values of attributes from the schema changed since previous. Stored values and
+// attributes removed from the schema are kept as is, so the config change does not break stored tasks.
+func validateUDA(previous map[string]string, uda map[string]string) error {
+	for name, value := range uda {
+		definition := udaSchema.Lookup(name)
+		if definition == nil {
+			continue
+		}
+		if stored, ok := previous[name]; ok && stored == value {
+			continue
+		}
+		if err := definition.check(value); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func unifyUDA(uda map[string]string) map[string]string {
+	result := map[string]string{}
+	for name, value := range uda {
+		value = strings.TrimSpace(value)
+		if len(value) == 0 {
+			continue
+		}
+		if definition := udaSchema.Lookup(name); definition != nil {
+			if parsed, err := definition.Parse(value); err == nil {
+				value = parsed
+			}
+		}
+		result[name] = value
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// SetUDA sets the attribute value, empty value removes the attribute.
+func (t *Task) SetUDA(name string, value string) {
+	if len(value) == 0 {
+		delete(t.UDA, name)
+		return
+	}
+	if t.UDA == nil {
+		t.UDA = map[string]string{}
+	}
+	t.UDA[name] = value
+}
+
+type UDAValue struct {
+	Definition UDADefinition
+	Value      string
+}
+
+// Time returns the value of date attribute, nil for other types.
+func (v UDAValue) Time() *time.Time {
+	if v.Definition.Type != UDADate {
+		return nil
+	}
+	date, err := time.Parse(time.RFC3339, v.Value)
+	if err != nil {
+		return nil
+	}
+	return &date
+}
+
+func (v UDAValue) String() string {
+	if date := v.Time(); date != nil {
+		return date.Format("2006-01-02 15:04 MST")
+	}
+	return v.Value
+}
+
+// UDAValues returns attributes in the schema order, attributes unknown to the schema go last as strings.
+func (t *Task) UDAValues() []UDAValue {
+	result := []UDAValue{}
+	for _, definition := range udaSchema {
+		if value, ok := t.UDA[definition.Name]; ok {
+			result = append(result, UDAValue{Definition: definition, Value: value})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(t.UDA)) {
+		if udaSchema.Lookup(name) == nil {
+			result = append(result, UDAValue{Definition: UDADefinition{Name: name, Type: UDAString}, Value: t.UDA[name]})
+		}
+	}
+	return result
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"testing"
+)
+
+func setTestUDASchema(t *testing.T) {
+	t.Helper()
+	err := SetUDASchema(UDASchema{
+		{Name: "estimate", Type: UDANumber},
+		{Name: "size", Type: UDAEnum, Values: []string{"S", "M", "L"}},
+		{Name: "reviewed", Type: UDADate},
+	})
+	if err != nil {
+		t.Fatalf("cant set schema: %s", err)
+	}
+	t.Cleanup(func() {
+		_ = SetUDASchema(nil)
+	})
+}
+
+func TestSetUDASchema(t *testing.T) {
+	invalid := []UDASchema{
+		{{Name: "project", Type: UDAString}},
+		{{Name: "Size", Type: UDAString}},
+		{{Name: "size", Type: "bool"}},
+		{{Name: "size", Type: UDAEnum}},
+		{{Name: "size", Type: UDAString}, {Name: "size", Type: UDANumber}},
+	}
+	for _, schema := range invalid {
+		if err := SetUDASchema(schema); err == nil {
+			t.Errorf("schema %+v should be rejected", schema)
+		}
+	}
+}
+
+func TestTaskUDA(t *testing.T) {
+	setTestUDASchema(t)
+
+	parsed, err := ParseHumanInput("add estimate:2.50 size:m reviewed:2024-08-10 write report")
+	if err != nil {
+		t.Fatalf("cant parse input: %s", err)
+	}
+	task := NewTask()
+	parsed.Options.ModifyTask(task)
+	task.Unify()
+	if err := task.Validate(); err != nil {
+		t.Fatalf("task should be valid: %s", err)
+	}
+	if task.Description != "write report" || task.UDA["estimate"] != "2.5" || task.UDA["size"] != "M" {
+		t.Errorf("unexpected task: %+v", task)
+	}
+	values := task.UDAValues()
+	if len(values) != 3 || values[0].Definition.Name != "estimate" || values[2].Time() == nil {
+		t.Errorf("values should follow the schema, have %+v", values)
+	}
+
+	if _, err := ParseHumanInput("add size:XL write report"); err == nil {
+		t.Errorf("value out of enum should be rejected")
+	}
+
+	parsed, err = ParseHumanInput("modify " + task.UUID.String() + " size:")
+	if err != nil {
+		t.Fatalf("cant parse input: %s", err)
+	}
+	parsed.Options.ModifyTask(task)
+	if _, ok := task.UDA["size"]; ok {
+		t.Errorf("empty value should remove the attribute")
+	}
+
+	task.UDA["estimate"] = "a lot"
+	if err := task.ValidateChanges(nil); err == nil {
+		t.Errorf("invalid number should be rejected")
+	}
+	task.UDA["estimate"] = "3"
+	task.UDA["removed"] = "kept"
+	if err := task.ValidateChanges(nil); err != nil {
+		t.Errorf("attribute unknown to the schema should be kept: %s", err)
+	}
+
+	previous := task.Clone(false)
+	previous.UDA["size"] = "XL"
+	task.UDA["size"] = "XL"
+	if err := task.Validate(); err != nil {
+		t.Errorf("stored value out of enum should be loaded: %s", err)
+	}
+	if err := task.ValidateChanges(previous); err != nil {
+		t.Errorf("stored value out of enum should be kept on write: %s", err)
+	}
+	previous.UDA["size"] = "M"
+	if err := task.ValidateChanges(previous); err == nil {
+		t.Errorf("changed value out of enum should be rejected")
+	}
+}
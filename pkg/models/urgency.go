@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type TaskPriority string
+
+const (
+	PriorityHigh   TaskPriority = "H"
+	PriorityMedium TaskPriority = "M"
+	PriorityLow    TaskPriority = "L"
+)
+
+func ParsePriority(value string) (TaskPriority, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "":
+		return "", nil
+	case "h", "high":
+		return PriorityHigh, nil
+	case "m", "medium":
+		return PriorityMedium, nil
+	case "l", "low":
+		return PriorityLow, nil
+	}
+	return "", fmt.Errorf("unknown priority: %s", value)
+}
+
+// Urgency coefficients are the taskwarrior defaults, so imported tasks keep their order.
+const (
+	urgencyPriorityHigh   = 6.0
+	urgencyPriorityMedium = 3.9
+	urgencyPriorityLow    = 1.8
+	urgencyDue            = 12.0
+	urgencyAge            = 2.0
+	urgencyAgeMaxDays     = 365.0
+	urgencyTags           = 1.0
+)
+
+// Urgency combines priority, due proximity, age and tags, higher is more urgent.
+// The same formula is used by postgresqlUrgency, keep them in sync.
+func (t *Task) Urgency(now time.Time) float64 {
+	urgency := 0.0
+	switch t.Priority {
+	case PriorityHigh:
+		urgency += urgencyPriorityHigh
+	case PriorityMedium:
+		urgency += urgencyPriorityMedium
+	case PriorityLow:
+		urgency += urgencyPriorityLow
+	}
+	if t.Due != nil {
+		// from 0.2 for due in 14 days and later to 1.0 for overdue by 7 days and more
+		daysOverdue := now.Sub(*t.Due).Hours() / 24
+		urgency += urgencyDue * min(max((daysOverdue+14)*0.8/21+0.2, 0.2), 1.0)
+	}
+	ageDays := now.Sub(t.CreatedAt).Hours() / 24
+	urgency += urgencyAge * min(max(ageDays/urgencyAgeMaxDays, 0), 1)
+	switch len(t.Tags) {
+	case 0:
+	case 1:
+		urgency += urgencyTags * 0.8
+	case 2:
+		urgency += urgencyTags * 0.9
+	default:
+		urgency += urgencyTags
+	}
+	return urgency
+}
+
+// SortTasksByUrgency orders tasks by status and then by urgency, ties are ordered as in SortTasks.
+func SortTasksByUrgency(tasks []*Task, now time.Time) {
+	urgency := map[*Task]float64{}
+	for _, t := range tasks {
+		urgency[t] = t.Urgency(now)
+	}
+	slices.SortFunc(tasks, func(a, b *Task) int {
+		if StatusRank(a.Status) != StatusRank(b.Status) {
+			return StatusRank(a.Status) - StatusRank(b.Status)
+		}
+		if urgency[a] > urgency[b] {
+			return -1
+		}
+		if urgency[a] < urgency[b] {
+			return 1
+		}
+		return compareTasks(a, b)
+	})
+}
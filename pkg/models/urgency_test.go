@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUrgency(t *testing.T) {
+	now := time.Now()
+	overdue := now.Add(-8 * 24 * time.Hour)
+	later := now.Add(30 * 24 * time.Hour)
+
+	plain := NewTask()
+	plain.CreatedAt = now
+	if urgency := plain.Urgency(now); urgency != 0 {
+		t.Errorf("new task without attributes should have zero urgency, have %f", urgency)
+	}
+
+	high := NewTask()
+	high.CreatedAt = now
+	high.Priority = PriorityHigh
+	high.Due = &later
+	if urgency := high.Urgency(now); urgency != 6.0+12.0*0.2 {
+		t.Errorf("unexpected urgency of high priority task, have %f", urgency)
+	}
+
+	late := NewTask()
+	late.CreatedAt = now.Add(-2 * 365 * 24 * time.Hour)
+	late.Due = &overdue
+	late.Tags = []string{"a", "b", "c", "d"}
+	if urgency := late.Urgency(now); urgency != 12.0+2.0+1.0 {
+		t.Errorf("unexpected urgency of overdue task, have %f", urgency)
+	}
+
+	tasks := []*Task{plain, high, late}
+	SortTasksByUrgency(tasks, now)
+	if tasks[0] != late || tasks[1] != high || tasks[2] != plain {
+		t.Errorf("tasks should be ordered by urgency, have %+v", tasks)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	for input, expected := range map[string]TaskPriority{"": "", "h": PriorityHigh, "Medium": PriorityMedium, "L": PriorityLow} {
+		if priority, err := ParsePriority(input); err != nil || priority != expected {
+			t.Errorf("ParsePriority(%q) = %q, %v, expected %q", input, priority, err, expected)
+		}
+	}
+	if _, err := ParsePriority("urgent"); err == nil {
+		t.Errorf("ParsePriority should fail on unknown priority")
+	}
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// User is an account of the deployment, every auth method resolves the request to a user and tasks belong to users.
+// The user with empty name is the default account of single user configs, tasks created before accounts belong to it.
+type User struct {
+	Name       string   `yaml:"name"`
+	TelegramId int64    `yaml:"telegram_id,omitempty"`
+	Emails     []string `yaml:"emails,omitempty"`
+	Login      string   `yaml:"login,omitempty"`
+	Password   string   `yaml:"password,omitempty"`
+	Token      string   `yaml:"token,omitempty"`
+}
+
+func (u *User) String() string {
+	if len(u.Name) == 0 {
+		return "default"
+	}
+	return u.Name
+}
+
+// TelegramActor is ModifiedBy of changes made with telegram.
+func (u *User) TelegramActor() string {
+	return "telegram:" + strconv.FormatInt(u.TelegramId, 10)
+}
+
+var userNameRegexp = regexp.MustCompile("^[a-z][a-z0-9_.-]*$")
+
+type Users []*User
+
+// Validate checks names and that credentials are not shared by users.
+func (users Users) Validate() error {
+	names := map[string]struct{}{}
+	telegramIds := map[int64]struct{}{}
+	emails := map[string]struct{}{}
+	logins := map[string]struct{}{}
+	tokens := map[string]struct{}{}
+	for i, user := range users {
+		if len(user.Name) > 0 && !userNameRegexp.MatchString(user.Name) {
+			return fmt.Errorf("invalid user name %q, it should match %s", user.Name, userNameRegexp.String())
+		}
+		if len(user.Name) == 0 && i > 0 {
+			return fmt.Errorf("user name should not be empty")
+		}
+		if _, ok := names[user.Name]; ok {
+			return fmt.Errorf("duplicated user: %s", user.Name)
+		}
+		names[user.Name] = struct{}{}
+		if len(user.Login) > 0 && len(user.Password) == 0 {
+			return fmt.Errorf("user %s: password should not be empty", user)
+		}
+		if err := checkUnique(telegramIds, user.TelegramId, 0); err != nil {
+			return fmt.Errorf("user %s: telegram id %w", user, err)
+		}
+		if err := checkUnique(logins, user.Login, ""); err != nil {
+			return fmt.Errorf("user %s: login %w", user, err)
+		}
+		if err := checkUnique(tokens, user.Token, ""); err != nil {
+			return fmt.Errorf("user %s: token %w", user, err)
+		}
+		for _, email := range user.Emails {
+			if err := checkUnique(emails, email, ""); err != nil {
+				return fmt.Errorf("user %s: email %w", user, err)
+			}
+		}
+	}
+	return nil
+}
+
+func checkUnique[T comparable](seen map[T]struct{}, value T, empty T) error {
+	if value == empty {
+		return nil
+	}
+	if _, ok := seen[value]; ok {
+		return fmt.Errorf("is used by another user")
+	}
+	seen[value] = struct{}{}
+	return nil
+}
+
+func (users Users) ByName(name string) *User {
+	return users.find(func(user *User) bool { return user.Name == name })
+}
+
+func (users Users) ByTelegramId(id int64) *User {
+	return users.find(func(user *User) bool { return id != 0 && user.TelegramId == id })
+}
+
+func (users Users) ByEmail(email string) *User {
+	return users.find(func(user *User) bool { return len(email) > 0 && slices.Contains(user.Emails, email) })
+}
+
+func (users Users) ByToken(token string) *User {
+	return users.find(func(user *User) bool { return len(token) > 0 && user.Token == token })
+}
+
+func (users Users) ByLogin(login string, password string) *User {
+	return users.find(func(user *User) bool {
+		return len(login) > 0 && user.Login == login && user.Password == password
+	})
+}
+
+func (users Users) find(match func(user *User) bool) *User {
+	for _, user := range users {
+		if match(user) {
+			return user
+		}
+	}
+	return nil
+}
+
+type userContextKey struct{}
+
+// WithUser scopes repository queries made with the context to the user, see db.NewUserScopedRepository.
+func WithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+func UserFromContext(ctx context.Context) *User {
+	user, _ := ctx.Value(userContextKey{}).(*User)
+	return user
+}
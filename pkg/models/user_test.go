@@ -0,0 +1,58 @@
+package models
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUsers(t *testing.T) {
+	users := Users{
+		{Token: "default_token"},
+		{Name: "alice", TelegramId: 1, Emails: []string{"alice@example.com"}, Login: "alice", Password: "secret", Token: "alice_token"},
+		{Name: "bob", TelegramId: 2},
+	}
+	if err := users.Validate(); err != nil {
+		t.Fatalf("valid users are rejected: %s", err)
+	}
+	for _, invalid := range []Users{
+		{{}, {Name: "alice"}, {Name: "alice"}},
+		{{}, {Name: ""}},
+		{{Token: "token"}, {Name: "alice", Token: "token"}},
+		{{}, {Name: "alice", Login: "alice"}},
+		{{}, {Name: "Alice Smith"}},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("invalid users should be rejected: %+v", invalid)
+		}
+	}
+
+	if user := users.ByToken("alice_token"); user == nil || user.Name != "alice" {
+		t.Errorf("unexpected user by token: %v", user)
+	}
+	if user := users.ByToken(""); user != nil {
+		t.Errorf("empty token should not match: %v", user)
+	}
+	if user := users.ByLogin("alice", "wrong"); user != nil {
+		t.Errorf("wrong password should not match: %v", user)
+	}
+	if user := users.ByEmail("alice@example.com"); user == nil || user.TelegramActor() != "telegram:1" {
+		t.Errorf("unexpected user by email: %v", user)
+	}
+	if user := users.ByTelegramId(0); user != nil {
+		t.Errorf("users without telegram should not match: %v", user)
+	}
+
+	ctx := WithUser(context.Background(), users[2])
+	if user := UserFromContext(ctx); user != users[2] || UserFromContext(context.Background()) != nil {
+		t.Errorf("unexpected user from context: %v", user)
+	}
+
+	own := NewTask()
+	own.Owner = "bob"
+	other := NewTask()
+	filter := NewDefaultListFilter()
+	filter.Scopes = []TaskScope{{Owner: own.Owner}}
+	if tasks := filter.Apply([]*Task{own, other}); len(tasks) != 1 || tasks[0] != own {
+		t.Errorf("only tasks of the owner should be shown: %v", tasks)
+	}
+}
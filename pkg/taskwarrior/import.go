@@ -19,7 +19,7 @@ type ImportConfig struct {
 	SkipRecur     bool
 }
 
-func Import(cfg *ImportConfig) ([]*models.Task, error) {
+func Import(ctx context.Context, cfg *ImportConfig) ([]*models.Task, error) {
 	tasks := []*twTask{}
 	var err error
 	if len(cfg.Filepath) > 0 {
@@ -31,7 +31,7 @@ func Import(cfg *ImportConfig) ([]*models.Task, error) {
 			return nil, fmt.Errorf("cant unmarshal: %w", err)
 		}
 	} else {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+		ctx, cancel := context.WithTimeout(ctx, time.Second*30)
 		defer cancel()
 		tasks, err = runTaskExport(ctx)
 		if err != nil {
@@ -48,8 +48,12 @@ func Import(cfg *ImportConfig) ([]*models.Task, error) {
 		if cfg.SkipRecur && len(t.Recur) > 0 {
 			return true
 		}
+		if t.Status == "recurring" {
+			return true
+		}
 		return false
 	})
+	tasks = keepNextRecurInstances(tasks)
 	result := []*models.Task{}
 	for _, task := range tasks {
 		appTask, err := task.toApplicationTask()
@@ -60,6 +64,27 @@ func Import(cfg *ImportConfig) ([]*models.Task, error) {
 	}
 	return result, nil
 }
+
+// keepNextRecurInstances leaves only the earliest pending instance for every taskwarrior recurring parent,
+// later occurrences will be created by the todolist itself.
+func keepNextRecurInstances(tasks []*twTask) []*twTask {
+	next := map[string]*twTask{}
+	for _, t := range tasks {
+		if len(t.Parent) == 0 || t.Status != "pending" {
+			continue
+		}
+		if current, ok := next[t.Parent]; !ok || t.Due < current.Due {
+			next[t.Parent] = t
+		}
+	}
+	return slices.DeleteFunc(tasks, func(t *twTask) bool {
+		if len(t.Parent) == 0 || t.Status != "pending" {
+			return false
+		}
+		return next[t.Parent] != t
+	})
+}
+
 func runTaskExport(ctx context.Context) ([]*twTask, error) {
 	stdout := bytes.NewBuffer(nil)
 	stderr := bytes.NewBuffer(nil)
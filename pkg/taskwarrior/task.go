@@ -1,27 +1,97 @@
 package taskwarrior
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/paragor/todo/pkg/models"
+	"log"
+	"strings"
 	"time"
 )
 
 type twTask struct {
-	Id          int      `json:"id"`
-	Description string   `json:"description"`
-	Due         string   `json:"due,omitempty"`
-	Notify      string   `json:"notify,omitempty"`
-	End         string   `json:"end,omitempty"`
-	Entry       string   `json:"entry"`
-	Modified    string   `json:"modified"`
-	Status      string   `json:"status"`
-	Uuid        string   `json:"uuid"`
-	Urgency     float64  `json:"urgency"`
-	Tags        []string `json:"tags,omitempty"`
-	Project     string   `json:"project,omitempty"`
-	Wait        string   `json:"wait,omitempty"`
-	Recur       string   `json:"recur,omitempty"`
+	Id          int            `json:"id"`
+	Description string         `json:"description"`
+	Due         string         `json:"due,omitempty"`
+	Notify      string         `json:"notify,omitempty"`
+	End         string         `json:"end,omitempty"`
+	Entry       string         `json:"entry"`
+	Modified    string         `json:"modified"`
+	Status      string         `json:"status"`
+	Uuid        string         `json:"uuid"`
+	Urgency     float64        `json:"urgency"`
+	Tags        []string       `json:"tags,omitempty"`
+	Project     string         `json:"project,omitempty"`
+	Wait        string         `json:"wait,omitempty"`
+	Scheduled   string         `json:"scheduled,omitempty"`
+	Recur       string         `json:"recur,omitempty"`
+	Priority    string         `json:"priority,omitempty"`
+	Parent      string         `json:"parent,omitempty"`
+	Depends     twDepends      `json:"depends,omitempty"`
+	Annotations []twAnnotation `json:"annotations,omitempty"`
+
+	// Fields are all exported fields, taskwarrior exports UDAs as top level fields.
+	Fields map[string]json.RawMessage `json:"-"`
+}
+
+func (t *twTask) UnmarshalJSON(data []byte) error {
+	type plainTask twTask
+	if err := json.Unmarshal(data, (*plainTask)(t)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &t.Fields)
+}
+
+// uda converts taskwarrior UDA to the stored value, taskwarrior exports numbers as json numbers
+// and dates in its own format.
+func (t *twTask) uda(definition *models.UDADefinition) (string, error) {
+	raw, ok := t.Fields[definition.Name]
+	if !ok {
+		return "", nil
+	}
+	value := ""
+	if err := json.Unmarshal(raw, &value); err != nil {
+		value = string(raw)
+	}
+	if len(value) == 0 {
+		return "", nil
+	}
+	if definition.Type == models.UDADate {
+		date := formatDate(value)
+		if date == nil {
+			return "", fmt.Errorf("invalid date: %s", value)
+		}
+		return date.Format(time.RFC3339), nil
+	}
+	return definition.Parse(value)
+}
+
+type twAnnotation struct {
+	Entry       string `json:"entry"`
+	Description string `json:"description"`
+}
+
+// twDepends is a list of uuids, old taskwarrior versions export it as comma separated string.
+type twDepends []string
+
+func (d *twDepends) UnmarshalJSON(data []byte) error {
+	list := []string{}
+	if err := json.Unmarshal(data, &list); err == nil {
+		*d = list
+		return nil
+	}
+	value := ""
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("depends should be list or string: %w", err)
+	}
+	*d = nil
+	for _, dependency := range strings.Split(value, ",") {
+		if dependency = strings.TrimSpace(dependency); len(dependency) > 0 {
+			*d = append(*d, dependency)
+		}
+	}
+	return nil
 }
 
 var (
@@ -47,8 +117,54 @@ func (t *twTask) toApplicationTask() (*models.Task, error) {
 		Status:      parsedStatus,
 		Due:         formatDate(t.Due),
 		Notify:      formatDate(t.Notify),
+		Wait:        formatDate(t.Wait),
+		Scheduled:   formatDate(t.Scheduled),
 		CreatedAt:   time.Now(),
 	}
+	if len(t.Recur) > 0 {
+		// the task is imported without recurrence, so a single unsupported rule does not fail the import
+		if recurrence, err := models.ParseRecurrence(t.Recur); err != nil {
+			log.Printf("taskwarrior: task %s is imported without unsupported recur %s: %s", t.Uuid, t.Recur, err)
+		} else if result.Due == nil && result.Notify == nil {
+			log.Printf("taskwarrior: task %s is imported without recur %s: no due or notify", t.Uuid, t.Recur)
+		} else {
+			result.Recur = recurrence.String()
+		}
+	}
+	priority, err := models.ParsePriority(t.Priority)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported priority %s: %w", t.Priority, err)
+	}
+	result.Priority = priority
+	for _, dependency := range t.Depends {
+		dependencyUUID, err := uuid.Parse(dependency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: depends: %w", uuidError, err)
+		}
+		result.Depends = append(result.Depends, dependencyUUID)
+	}
+	for _, annotation := range t.Annotations {
+		if len(strings.TrimSpace(annotation.Description)) == 0 {
+			continue
+		}
+		at := formatDate(annotation.Entry)
+		if at == nil {
+			return nil, fmt.Errorf("invalid annotation entry: %s", annotation.Entry)
+		}
+		result.Annotate(annotation.Description, *at)
+	}
+	schema := models.GetUDASchema()
+	for i := range schema {
+		value, err := t.uda(&schema[i])
+		if err != nil {
+			return nil, fmt.Errorf("unsupported %s: %w", schema[i].Name, err)
+		}
+		result.SetUDA(schema[i].Name, value)
+	}
+	result.ModifiedAt = formatDate(t.Modified)
+	if parsedStatus.IsClosed() {
+		result.CompletedAt = formatDate(t.End)
+	}
 	parsedCreatedAt := formatDate(t.Entry)
 	if parsedCreatedAt != nil {
 		result.CreatedAt = *parsedCreatedAt
@@ -1,25 +1,56 @@
 package telegram
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/paragor/todo/pkg/models"
 )
 
-func (t *TelegramServer) TriggerAgenda() error {
+// TriggerEverydayAgenda sends agenda to every user of the bot.
+func (t *TelegramServer) TriggerEverydayAgenda(ctx context.Context) error {
+	var errs []error
+	for _, user := range t.users {
+		if user.TelegramId == 0 {
+			continue
+		}
+		if err := t.TriggerAgenda(models.WithUser(ctx, user)); err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", user, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// TriggerAgenda sends agenda to the user from the context within the context of saved filters.
+func (t *TelegramServer) TriggerAgenda(ctx context.Context) error {
+	return t.sendAgenda(ctx, &models.HumanInputOptions{})
+}
+
+func (t *TelegramServer) sendAgenda(ctx context.Context, options *models.HumanInputOptions) error {
 	if t.bot == nil {
 		return fmt.Errorf("server is not started")
 	}
-	tasks, err := t.db.All()
+	filters, err := t.db.Filters(ctx)
+	if err != nil {
+		return fmt.Errorf("cant get filters: %w", err)
+	}
+	filter, err := options.ContextListFilter(filters)
+	if err != nil {
+		return err
+	}
+	result, err := t.db.Find(ctx, filter, models.Page{})
 	if err != nil {
 		return fmt.Errorf("cant get tasks list: %w", err)
 	}
-	tasks = models.NewDefaultListFilter().Apply(tasks)
-	agenda := models.Agenda(tasks)
+	if err := models.ResolveBlockers(ctx, t.db, result.Tasks...); err != nil {
+		return fmt.Errorf("cant resolve dependencies: %w", err)
+	}
+	agenda := models.Agenda(result.Tasks)
 	msg, err := renderTemplate("message/agenda", agenda)
 	if err != nil {
 		return fmt.Errorf("cant render template: %w", err)
 	}
-	if err := t.sendMessageHtml(msg, t.withAgendaWebApp(), t.withEnableNotifications()); err != nil {
+	if err := t.sendMessageHtml(ctx, msg, t.withAgendaWebApp(), t.withEnableNotifications()); err != nil {
 		return fmt.Errorf("cant send telegram msg: %w", err)
 	}
 	return nil
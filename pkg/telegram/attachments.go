@@ -0,0 +1,67 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/paragor/todo/pkg/models"
+	tele "gopkg.in/telebot.v3"
+	"io"
+	"regexp"
+	"time"
+)
+
+var uuidRegexp = regexp.MustCompile("[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}")
+
+// replyTaskUUID returns the task of the replied message/task, its own uuid is the last one in the message.
+func replyTaskUUID(message *tele.Message) (uuid.UUID, error) {
+	if message.ReplyTo == nil {
+		return uuid.Nil, fmt.Errorf("send the file in reply to a task message")
+	}
+	found := uuidRegexp.FindAllString(message.ReplyTo.Text, -1)
+	if len(found) == 0 {
+		return uuid.Nil, fmt.Errorf("replied message has no task uuid")
+	}
+	return uuid.Parse(found[len(found)-1])
+}
+
+func (t *TelegramServer) attachFile(ctx context.Context, message *tele.Message, file *tele.File, name string, contentType string) error {
+	UUID, err := replyTaskUUID(message)
+	if err != nil {
+		return err
+	}
+	task, err := t.db.Get(ctx, UUID)
+	if err != nil {
+		return fmt.Errorf("cant fetch task: %w", err)
+	}
+	if task == nil {
+		return fmt.Errorf("task not found: %s", UUID)
+	}
+	if file.FileSize > models.MaxAttachmentSize {
+		return fmt.Errorf("file is larger than %d MB", models.MaxAttachmentSize>>20)
+	}
+	reader, err := t.bot.File(file)
+	if err != nil {
+		return fmt.Errorf("cant download file: %w", err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(io.LimitReader(reader, models.MaxAttachmentSize+1))
+	if err != nil {
+		return fmt.Errorf("cant download file: %w", err)
+	}
+	if _, err := models.Attach(ctx, t.attachments, task, name, contentType, content, time.Now()); err != nil {
+		return err
+	}
+	task.ModifiedBy = t.actor(ctx)
+	if err := t.db.Insert(ctx, task); err != nil {
+		return fmt.Errorf("cant insert task: %w", err)
+	}
+	msg, err := renderTemplate("message/task", task)
+	if err != nil {
+		return fmt.Errorf("cant render template: %w", err)
+	}
+	if err := t.sendMessageHtml(ctx, msg, t.withTaskWebApp(task.UUID)); err != nil {
+		return fmt.Errorf("cant send response (%s): %w", task.UUID, err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"github.com/paragor/todo/pkg/models"
+	tele "gopkg.in/telebot.v3"
+	"slices"
+	"strings"
+)
+
+// botCommands are commands of every chat, saved filters of the user are added to the user chat by updateCommands.
+var botCommands = []tele.Command{
+	{
+		Text:        "help",
+		Description: "Help",
+	},
+	{
+		Text:        "start",
+		Description: "Web app link",
+	},
+	{
+		Text:        "agenda",
+		Description: "Show agenda",
+	},
+	{
+		Text:        "filters",
+		Description: "Show saved filters",
+	},
+}
+
+// updateCommands shows saved filters of the user from the context as commands of the user chat.
+// Commands are updated on start and on changes of saved filters by the bot.
+func (t *TelegramServer) updateCommands(ctx context.Context) error {
+	user := models.UserFromContext(ctx)
+	if user == nil || user.TelegramId == 0 {
+		return nil
+	}
+	filters, err := t.db.Filters(ctx)
+	if err != nil {
+		return fmt.Errorf("cant get filters: %w", err)
+	}
+	commands := slices.Clone(botCommands)
+	for _, filter := range filters {
+		if slices.ContainsFunc(botCommands, func(command tele.Command) bool { return command.Text == filter.Command() }) {
+			continue
+		}
+		description := filter.Query
+		if len(description) == 0 {
+			description = "all tasks"
+		}
+		if filter.Active {
+			description = "(context) " + description
+		}
+		commands = append(commands, tele.Command{Text: filter.Command(), Description: description[:min(len(description), 256)]})
+	}
+	if err := t.bot.SetCommands(commands, tele.CommandScope{Type: tele.CommandScopeChat, ChatID: user.TelegramId}); err != nil {
+		return fmt.Errorf("cant set commands: %w", err)
+	}
+	return nil
+}
+
+// savedFilterCommand turns the command of the saved filter into list action with the saved filter.
+func (t *TelegramServer) savedFilterCommand(ctx context.Context, input string) (string, error) {
+	command, rest, _ := strings.Cut(strings.TrimSpace(input), " ")
+	if !strings.HasPrefix(command, "/") {
+		return input, nil
+	}
+	filters, err := t.db.Filters(ctx)
+	if err != nil {
+		return "", fmt.Errorf("cant get filters: %w", err)
+	}
+	filter := models.FindSavedFilter(filters, command)
+	if filter == nil {
+		return input, nil
+	}
+	return "list context:" + filter.Name + " " + rest, nil
+}
+
+func (t *TelegramServer) filtersInput(ctx context.Context, parsedInput *models.HumanInputParserResult) error {
+	filters, err := t.db.Filters(ctx)
+	if err != nil {
+		return fmt.Errorf("cant get filters: %w", err)
+	}
+	switch parsedInput.Action {
+	case models.HumanActionSaveFilter:
+		filter := parsedInput.Options.SavedFilter
+		if existing := models.FindSavedFilter(filters, filter.Name); existing != nil {
+			filter.Active = existing.Active
+		}
+		if err := t.db.SaveFilter(ctx, filter); err != nil {
+			return fmt.Errorf("cant save filter: %w", err)
+		}
+	case models.HumanActionDeleteFilter:
+		if err := t.db.DeleteFilter(ctx, parsedInput.Options.SavedFilter); err != nil {
+			return fmt.Errorf("cant delete filter: %w", err)
+		}
+	case models.HumanActionContext:
+		filter, err := models.SwitchContext(filters, parsedInput.Options.Context)
+		if err != nil {
+			return err
+		}
+		if filter != nil {
+			if err := t.db.SaveFilter(ctx, filter); err != nil {
+				return fmt.Errorf("cant save filter: %w", err)
+			}
+		}
+	}
+	if parsedInput.Action != models.HumanActionFilters {
+		if filters, err = t.db.Filters(ctx); err != nil {
+			return fmt.Errorf("cant get filters: %w", err)
+		}
+	}
+	if err := t.updateCommands(ctx); err != nil {
+		return err
+	}
+	msg, err := renderTemplate("message/filters", filters)
+	if err != nil {
+		return fmt.Errorf("cant render template: %w", err)
+	}
+	if err := t.sendMessageHtml(ctx, msg); err != nil {
+		return fmt.Errorf("cant send response filters: %w", err)
+	}
+	return nil
+}
@@ -1,11 +1,21 @@
 package telegram
 
 import (
+	"context"
 	"fmt"
 	"github.com/paragor/todo/pkg/models"
+	"html"
+	"time"
 )
 
-func (t *TelegramServer) humanInput(input string) error {
+// shortlistLimit keeps the list response within the telegram message size limit.
+const shortlistLimit = 50
+
+func (t *TelegramServer) humanInput(ctx context.Context, input string) error {
+	input, err := t.savedFilterCommand(ctx, input)
+	if err != nil {
+		return err
+	}
 	parsedInput, err := models.ParseHumanInput(input)
 	if err != nil {
 		return fmt.Errorf("cant parse command: %w", err)
@@ -13,96 +23,224 @@ func (t *TelegramServer) humanInput(input string) error {
 
 	switch parsedInput.Action {
 	case models.HumanActionInfo:
-		task, err := t.db.Get(*parsedInput.ActionUUID)
+		task, err := t.db.Get(ctx, *parsedInput.ActionUUID)
 		if err != nil {
 			return fmt.Errorf("cant fetch task: %w", err)
 		}
+		if task == nil {
+			return fmt.Errorf("task not found: %s", *parsedInput.ActionUUID)
+		}
 		msg, err := renderTemplate("message/task", task)
 		if err != nil {
 			return fmt.Errorf("cant render template: %w", err)
 		}
-		err = t.sendMessageHtml(msg, t.withTaskWebApp(task.UUID))
+		err = t.sendMessageHtml(ctx, msg, t.withTaskWebApp(task.UUID))
 		if err != nil {
 			return fmt.Errorf("cant send response (%s): %w", task.UUID, err)
 		}
 		return nil
-	case models.HumanActionAdd:
-		task := models.NewTask()
-		parsedInput.Options.ModifyTask(task)
-		if err := t.db.Insert(task); err != nil {
-			return fmt.Errorf("cant insert task: %w", err)
+	case models.HumanActionAdd, models.HumanActionFromTemplate:
+		tasks, err := parsedInput.Options.NewTasks(time.Now())
+		if err != nil {
+			return fmt.Errorf("cant create task: %w", err)
+		}
+		for _, task := range tasks {
+			task.ModifiedBy = t.actor(ctx)
 		}
+		if err := t.db.Insert(ctx, tasks...); err != nil {
+			return fmt.Errorf("cant insert tasks: %w", err)
+		}
+		task := tasks[0]
 		msg, err := renderTemplate("message/task", task)
 		if err != nil {
 			return fmt.Errorf("cant render template: %w", err)
 		}
-		err = t.sendMessageHtml(msg, t.withTaskWebApp(task.UUID))
+		err = t.sendMessageHtml(ctx, msg, t.withTaskWebApp(task.UUID))
 		if err != nil {
 			return fmt.Errorf("cant send response (%s): %w", task.UUID, err)
 		}
+		if len(tasks) > 1 {
+			msg, err := renderTemplate("message/tasks_shortlist", tasks[1:])
+			if err != nil {
+				return fmt.Errorf("cant render template: %w", err)
+			}
+			if err := t.sendMessageHtml(ctx, "Subtasks:"+msg); err != nil {
+				return fmt.Errorf("cant send response (%s): %w", task.UUID, err)
+			}
+		}
 		return nil
-	case models.HumanActionModify, models.HumanActionDone:
-		task, err := t.db.Get(*parsedInput.ActionUUID)
+	case models.HumanActionModify, models.HumanActionDone, models.HumanActionAnnotate:
+		task, err := t.db.Get(ctx, *parsedInput.ActionUUID)
 		if err != nil {
 			return fmt.Errorf("cant fetch task: %w", err)
 		}
+		if task == nil {
+			return fmt.Errorf("task not found: %s", *parsedInput.ActionUUID)
+		}
+		previousStatus := task.Status
 		parsedInput.Options.ModifyTask(task)
-		if err := t.db.Insert(task); err != nil {
+		task.ModifiedBy = t.actor(ctx)
+		isClosing := models.IsClosing(previousStatus, task.Status)
+		completeSubtasks := isClosing && parsedInput.Options.CompleteSubtasks
+		var subtasks, subtasksNext []*models.Task
+		if completeSubtasks {
+			subtasks, subtasksNext, err = models.CompleteSubtasks(ctx, t.db, task)
+			if err != nil {
+				return fmt.Errorf("cant complete subtasks: %w", err)
+			}
+		}
+		next, err := models.InsertTask(ctx, t.db, previousStatus, task, append(subtasks, subtasksNext...)...)
+		if err != nil {
 			return fmt.Errorf("cant insert task: %w", err)
 		}
 		msg, err := renderTemplate("message/task", task)
 		if err != nil {
 			return fmt.Errorf("cant render template: %w", err)
 		}
-		err = t.sendMessageHtml(msg, t.withTaskWebApp(task.UUID))
+		err = t.sendMessageHtml(ctx, msg, t.withTaskWebApp(task.UUID))
 		if err != nil {
 			return fmt.Errorf("cant send response (%s): %w", task.UUID, err)
 		}
+		if next != nil {
+			msg, err := renderTemplate("message/task", next)
+			if err != nil {
+				return fmt.Errorf("cant render template: %w", err)
+			}
+			err = t.sendMessageHtml(ctx, "Next occurrence:\n"+msg, t.withTaskWebApp(next.UUID))
+			if err != nil {
+				return fmt.Errorf("cant send response (%s): %w", next.UUID, err)
+			}
+		}
+		if isClosing {
+			return t.handleSubtasksOnComplete(ctx, task, subtasks, completeSubtasks)
+		}
 		return nil
 	case models.HumanActionCopy:
-		task, err := t.db.Get(*parsedInput.ActionUUID)
+		task, err := t.db.Get(ctx, *parsedInput.ActionUUID)
 		if err != nil {
 			return fmt.Errorf("cant fetch task: %w", err)
 		}
+		if task == nil {
+			return fmt.Errorf("task not found: %s", *parsedInput.ActionUUID)
+		}
 		parsedInput.Options.ModifyTask(task)
 		task = task.Clone(true)
 		if task.Status != models.Pending && parsedInput.Options.Status == nil {
 			task.Status = models.Pending
 		}
-		if err := t.db.Insert(task); err != nil {
+		task.ModifiedBy = t.actor(ctx)
+		if err := t.db.Insert(ctx, task); err != nil {
 			return fmt.Errorf("cant insert task: %w", err)
 		}
 		msg, err := renderTemplate("message/task", task)
 		if err != nil {
 			return fmt.Errorf("cant render template: %w", err)
 		}
-		err = t.sendMessageHtml(msg, t.withTaskWebApp(task.UUID))
+		err = t.sendMessageHtml(ctx, msg, t.withTaskWebApp(task.UUID))
 		if err != nil {
 			return fmt.Errorf("cant send response (%s): %w", task.UUID, err)
 		}
 		return nil
 	case models.HumanActionAgenda:
-		if err := t.TriggerAgenda(); err != nil {
+		if err := t.sendAgenda(ctx, &parsedInput.Options); err != nil {
 			return fmt.Errorf("cant send agenda: %w", err)
 		}
 		return nil
+	case models.HumanActionStart, models.HumanActionStop:
+		task, err := t.db.Get(ctx, *parsedInput.ActionUUID)
+		if err != nil {
+			return fmt.Errorf("cant fetch task: %w", err)
+		}
+		if task == nil {
+			return fmt.Errorf("task not found: %s", *parsedInput.ActionUUID)
+		}
+		task.ModifiedBy = t.actor(ctx)
+		msg := ""
+		if parsedInput.Action == models.HumanActionStart {
+			stopped, err := models.StartTracking(ctx, t.db, task, time.Now())
+			if err != nil {
+				return fmt.Errorf("cant start task: %w", err)
+			}
+			for _, stoppedTask := range stopped {
+				msg += fmt.Sprintf("Stopped: %s\n", html.EscapeString(stoppedTask.Summary()))
+			}
+		} else {
+			if err := task.StopTracking(time.Now()); err != nil {
+				return fmt.Errorf("cant stop task: %w", err)
+			}
+			if err := t.db.Insert(ctx, task); err != nil {
+				return fmt.Errorf("cant insert task: %w", err)
+			}
+		}
+		taskMsg, err := renderTemplate("message/task", task)
+		if err != nil {
+			return fmt.Errorf("cant render template: %w", err)
+		}
+		err = t.sendMessageHtml(ctx, msg+taskMsg, t.withTaskWebApp(task.UUID))
+		if err != nil {
+			return fmt.Errorf("cant send response (%s): %w", task.UUID, err)
+		}
+		return nil
+	case models.HumanActionHistory:
+		history, err := t.db.History(ctx, *parsedInput.ActionUUID)
+		if err != nil {
+			return fmt.Errorf("cant get task history: %w", err)
+		}
+		msg, err := renderTemplate("message/history", history)
+		if err != nil {
+			return fmt.Errorf("cant render template: %w", err)
+		}
+		err = t.sendMessageHtml(ctx, msg, t.withTaskWebApp(*parsedInput.ActionUUID))
+		if err != nil {
+			return fmt.Errorf("cant send response history: %w", err)
+		}
+		return nil
+	case models.HumanActionRenameProject, models.HumanActionRenameTag:
+		rename := parsedInput.Options.Rename
+		rename.ModifiedBy = t.actor(ctx)
+		preview, err := t.db.Rename(ctx, rename)
+		if err != nil {
+			return fmt.Errorf("cant rename: %w", err)
+		}
+		msg, err := renderTemplate("message/rename", preview)
+		if err != nil {
+			return fmt.Errorf("cant render template: %w", err)
+		}
+		err = t.sendMessageHtml(ctx, msg)
+		if err != nil {
+			return fmt.Errorf("cant send response rename: %w", err)
+		}
+		return nil
+	case models.HumanActionFilters, models.HumanActionSaveFilter, models.HumanActionDeleteFilter, models.HumanActionContext:
+		return t.filtersInput(ctx, parsedInput)
 	case models.HumanActionList:
-		tasks, err := t.db.All()
+		filters, err := t.db.Filters(ctx)
+		if err != nil {
+			return fmt.Errorf("cant get filters: %w", err)
+		}
+		filter, err := parsedInput.Options.ContextListFilter(filters)
+		if err != nil {
+			return err
+		}
+		result, err := t.db.Find(ctx, filter, parsedInput.Options.ToPage(models.OrderUrgency, shortlistLimit))
 		if err != nil {
 			return fmt.Errorf("cant get tasks: %w", err)
 		}
-		tasks = parsedInput.Options.ToListFilter().Apply(tasks)
-		if len(tasks) == 0 {
-			err = t.sendMessageHtml("Nothing...", t.withTaskFilterWebApp(parsedInput.Options.ToListFilter()))
+		if len(result.Tasks) == 0 {
+			err = t.sendMessageHtml(ctx, "Nothing...", t.withTaskFilterWebApp(filter))
 			if err != nil {
 				return fmt.Errorf("cant send response list: %w", err)
 			}
+			return nil
 		}
-		msg, err := renderTemplate("message/tasks_shortlist", tasks)
+		msg, err := renderTemplate("message/tasks_shortlist", result.Tasks)
 		if err != nil {
 			return fmt.Errorf("cant render template: %w", err)
 		}
-		err = t.sendMessageHtml(msg, t.withTaskFilterWebApp(parsedInput.Options.ToListFilter()))
+		if result.Total > len(result.Tasks) {
+			msg += fmt.Sprintf("\n... and %d more", result.Total-len(result.Tasks))
+		}
+		err = t.sendMessageHtml(ctx, msg, t.withTaskFilterWebApp(filter))
 		if err != nil {
 			return fmt.Errorf("cant send response list: %w", err)
 		}
@@ -111,3 +249,28 @@ func (t *TelegramServer) humanInput(input string) error {
 		return fmt.Errorf("unkown action: %s", parsedInput.Action)
 	}
 }
+
+// handleSubtasksOnComplete reports the subtasks completed together with the task or the pending ones.
+func (t *TelegramServer) handleSubtasksOnComplete(ctx context.Context, task *models.Task, completed []*models.Task, completeSubtasks bool) error {
+	header := "Completed subtasks:"
+	subtasks := completed
+	if !completeSubtasks {
+		var err error
+		subtasks, err = models.PendingSubtasks(ctx, t.db, task.UUID)
+		if err != nil {
+			return fmt.Errorf("cant get subtasks: %w", err)
+		}
+		header = fmt.Sprintf("Task still has pending subtasks, use <code>done %s subtasks:complete</code> to complete them:", task.UUID)
+	}
+	if len(subtasks) == 0 {
+		return nil
+	}
+	msg, err := renderTemplate("message/tasks_shortlist", subtasks)
+	if err != nil {
+		return fmt.Errorf("cant render template: %w", err)
+	}
+	if err := t.sendMessageHtml(ctx, header+msg, t.withTaskWebApp(task.UUID)); err != nil {
+		return fmt.Errorf("cant send response subtasks: %w", err)
+	}
+	return nil
+}
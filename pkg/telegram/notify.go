@@ -14,17 +14,21 @@ import (
 )
 
 type Notifier struct {
-	notifyState    map[uuid.UUID]*cron.Cron
+	notifyState map[uuid.UUID]*cron.Cron
+	// deferred are blocked tasks whose notify time has passed, they are notified when blockers are completed
+	deferred       map[uuid.UUID]struct{}
 	notifyErrChan  chan error
 	refreshErrChan chan error
 	db             models.Repository
 	telegram       *TelegramServer
+	ctx            context.Context
 	m              sync.Mutex
 }
 
 func newNotifier(db models.Repository, telegram *TelegramServer) *Notifier {
 	return &Notifier{
 		notifyState:    map[uuid.UUID]*cron.Cron{},
+		deferred:       map[uuid.UUID]struct{}{},
 		notifyErrChan:  make(chan error, 1000),
 		refreshErrChan: make(chan error, 1),
 		db:             db,
@@ -33,7 +37,11 @@ func newNotifier(db models.Repository, telegram *TelegramServer) *Notifier {
 }
 
 func (n *Notifier) Start(ctx context.Context) error {
-	err := n.refreshState()
+	n.ctx = ctx
+	err := n.restoreDeferred()
+	if err == nil {
+		err = n.refreshState()
+	}
 	if err != nil {
 		n.close()
 		return err
@@ -63,14 +71,55 @@ func (n *Notifier) OnDatabaseChange() {
 	}
 }
 
+// notifyListFilter includes waiting tasks and archived projects, they are hidden from lists but not notified.
+func notifyListFilter() *models.ListFilter {
+	filter := models.NewDefaultListFilter()
+	filter.ShowWaiting = true
+	filter.ShowArchived = true
+	return filter
+}
+
+// findTasks returns tasks of all users of the bot, blockers are resolved within tasks of the same user.
+func (n *Notifier) findTasks(resolveBlockers bool) ([]*models.Task, error) {
+	tasks := []*models.Task{}
+	for _, user := range n.telegram.users {
+		if user.TelegramId == 0 {
+			continue
+		}
+		ctx := models.WithUser(n.ctx, user)
+		result, err := n.db.Find(ctx, notifyListFilter(), models.Page{})
+		if err != nil {
+			return nil, fmt.Errorf("cant get task list of user %s: %w", user, err)
+		}
+		if resolveBlockers {
+			if err := models.ResolveBlockers(ctx, n.db, result.Tasks...); err != nil {
+				return nil, fmt.Errorf("cant resolve dependencies: %w", err)
+			}
+		}
+		tasks = append(tasks, result.Tasks...)
+	}
+	return tasks, nil
+}
+
+// userContext scopes the context to the owner of the task.
+func (n *Notifier) userContext(ctx context.Context, task *models.Task) (context.Context, error) {
+	user := n.telegram.users.ByName(task.Owner)
+	if user == nil {
+		return nil, fmt.Errorf("user %q of task %s not found", task.Owner, task.UUID)
+	}
+	return models.WithUser(ctx, user), nil
+}
+
 func (n *Notifier) refreshState() error {
 	n.m.Lock()
 	defer n.m.Unlock()
-	tasks, err := n.db.All()
+	tasks, err := n.findTasks(len(n.deferred) > 0)
 	if err != nil {
-		return fmt.Errorf("cant get task list: %w", err)
+		return err
+	}
+	if err := n.notifyUnblocked(tasks); err != nil {
+		return err
 	}
-	tasks = models.NewDefaultListFilter().Apply(tasks)
 	newState := n.createNotifyState(tasks)
 	for UUID, oldCron := range n.notifyState {
 		newCron, ok := newState[UUID]
@@ -104,8 +153,56 @@ func (n *Notifier) refreshState() error {
 	return nil
 }
 
+// restoreDeferred defers blocked tasks whose notify time has passed while the notifier was not running.
+func (n *Notifier) restoreDeferred() error {
+	n.m.Lock()
+	defer n.m.Unlock()
+	tasks, err := n.findTasks(true)
+	if err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		if t.Notify != nil && time.Now().After(*t.Notify) && t.IsBlocked() {
+			n.deferred[t.UUID] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// notifyUnblocked sends deferred notifications of tasks whose blockers are completed, blockers should be resolved.
+func (n *Notifier) notifyUnblocked(tasks []*models.Task) error {
+	if len(n.deferred) == 0 {
+		return nil
+	}
+	pending := map[uuid.UUID]*models.Task{}
+	for _, t := range tasks {
+		if t.Status.IsOpen() {
+			pending[t.UUID] = t
+		}
+	}
+	for UUID := range n.deferred {
+		task, ok := pending[UUID]
+		if !ok {
+			delete(n.deferred, UUID)
+			continue
+		}
+		if task.IsBlocked() {
+			continue
+		}
+		delete(n.deferred, UUID)
+		ctx, err := n.userContext(n.ctx, task)
+		if err != nil {
+			return err
+		}
+		if err := n.sendNotify(ctx, task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (n *Notifier) runCron(c *cron.Cron) error {
-	errChan, err := c.GoRun()
+	errChan, err := c.GoRun(n.ctx)
 	if err != nil {
 		return err
 	}
@@ -134,19 +231,26 @@ func (n *Notifier) createNotifyState(tasks []*models.Task) map[uuid.UUID]*cron.C
 		if notifyDate == nil || time.Now().After(*notifyDate) {
 			continue
 		}
-		if t.Status != models.Pending {
+		if !t.Status.IsOpen() {
 			continue
 		}
 		UUID := t.UUID
-		result[UUID] = cron.NewCron(*notifyDate, func() error {
-			return n.triggerNotify(UUID)
+		owner := t.Owner
+		result[UUID] = cron.NewCron(*notifyDate, func(ctx context.Context) error {
+			return n.triggerNotify(ctx, owner, UUID)
 		})
 	}
 	return result
 }
 
-func (n *Notifier) triggerNotify(UUID uuid.UUID) error {
-	task, err := n.db.Get(UUID)
+func (n *Notifier) triggerNotify(ctx context.Context, owner string, UUID uuid.UUID) error {
+	user := n.telegram.users.ByName(owner)
+	if user == nil {
+		log.Printf("try to notify about task %s, but its user %q is not found", UUID, owner)
+		return nil
+	}
+	ctx = models.WithUser(ctx, user)
+	task, err := n.db.Get(ctx, UUID)
 	if err != nil {
 		return fmt.Errorf("on search task (%s): %w", UUID, err)
 	}
@@ -154,18 +258,32 @@ func (n *Notifier) triggerNotify(UUID uuid.UUID) error {
 		log.Printf("try to notify about task %s, but it is not found", UUID)
 		return nil
 	}
-	if task.Status != models.Pending {
-		log.Printf("try to notify about task %s, but it is has not pendig status: %s", UUID, task.Status)
+	if !task.Status.IsOpen() {
+		log.Printf("try to notify about task %s, but it is has not open status: %s", UUID, task.Status)
+		return nil
+	}
+	if err := models.ResolveBlockers(ctx, n.db, task); err != nil {
+		return fmt.Errorf("cant resolve dependencies of task (%s): %w", UUID, err)
+	}
+	if task.IsBlocked() {
+		log.Printf("defer notify about task %s until its dependencies are completed", UUID)
+		n.m.Lock()
+		n.deferred[UUID] = struct{}{}
+		n.m.Unlock()
 		return nil
 	}
+	return n.sendNotify(ctx, task)
+}
 
+func (n *Notifier) sendNotify(ctx context.Context, task *models.Task) error {
+	UUID := task.UUID
 	log.Printf("notify %s task (%s)", UUID, task.Description)
 
 	msg, err := renderTemplate("message/task", task)
 	if err != nil {
 		return fmt.Errorf("cant render template: %w", err)
 	}
-	err = n.telegram.sendMessageHtml(msg, n.telegram.withEnableNotifications(), n.telegram.withTaskWebApp(UUID))
+	err = n.telegram.sendMessageHtml(ctx, msg, n.telegram.withEnableNotifications(), n.telegram.withTaskWebApp(UUID))
 	if err != nil {
 		return fmt.Errorf("cant send notify (%s): %w", UUID, err)
 	}
@@ -1,9 +1,9 @@
 package telegram
 
 import (
+	"context"
 	"fmt"
 	"github.com/google/uuid"
-	"github.com/paragor/todo/pkg/httpserver"
 	"github.com/paragor/todo/pkg/models"
 	tele "gopkg.in/telebot.v3"
 )
@@ -70,7 +70,7 @@ func (t *TelegramServer) withTaskFilterWebApp(filter *models.ListFilter) sendOpt
 				[]tele.Btn{
 					reply.WebApp(
 						"List in webapp",
-						&tele.WebApp{URL: t.serverPublicUrl + "/?" + httpserver.ListFilterToQuery(filter).Encode()},
+						&tele.WebApp{URL: t.serverPublicUrl + "/?" + models.ListFilterToQuery(filter).Encode()},
 					),
 				},
 			)...,
@@ -85,7 +85,12 @@ func (t *TelegramServer) withEnableNotifications() sendOption {
 	}
 }
 
-func (t *TelegramServer) sendMessageHtml(msg string, options ...sendOption) error {
+// sendMessageHtml sends the message to the chat of the user from the context.
+func (t *TelegramServer) sendMessageHtml(ctx context.Context, msg string, options ...sendOption) error {
+	user := models.UserFromContext(ctx)
+	if user == nil || user.TelegramId == 0 {
+		return fmt.Errorf("telegram send message: no chat of the user")
+	}
 	sendOptions := &tele.SendOptions{
 		DisableNotification:   true,
 		DisableWebPagePreview: true,
@@ -94,7 +99,7 @@ func (t *TelegramServer) sendMessageHtml(msg string, options ...sendOption) erro
 	for _, option := range options {
 		option(sendOptions)
 	}
-	_, err := t.bot.Send(t.chat, msg, sendOptions)
+	_, err := t.bot.Send(tele.ChatID(user.TelegramId), msg, sendOptions)
 	if err != nil {
 		return fmt.Errorf("telegram send message: %w", err)
 	}
@@ -13,19 +13,20 @@ import (
 )
 
 type TelegramServer struct {
-	token           string
-	userId          int64
+	token string
+	// users with telegram id can use the bot, each one in its own chat.
+	users           models.Users
 	db              models.Repository
+	attachments     models.AttachmentStorage
 	serverPublicUrl string
 
-	bot  *tele.Bot
-	chat *tele.Chat
+	bot *tele.Bot
 
 	cancel func()
 }
 
-func NewTelegramServer(token string, userId int64, serverPublicUrl string, db models.Repository) *TelegramServer {
-	telegramServer := &TelegramServer{token: token, userId: userId, db: db, serverPublicUrl: serverPublicUrl}
+func NewTelegramServer(token string, users models.Users, serverPublicUrl string, db models.Repository, attachments models.AttachmentStorage) *TelegramServer {
+	telegramServer := &TelegramServer{token: token, users: users, db: db, attachments: attachments, serverPublicUrl: serverPublicUrl}
 	return telegramServer
 }
 
@@ -55,52 +56,55 @@ func (t *TelegramServer) Start(ctx context.Context, stopper chan<- error) error
 			if err == nil {
 				log.Printf("telegram get msg: %s", string(data))
 			}
-			if c.Sender().ID != t.userId {
+			user := t.users.ByTelegramId(c.Sender().ID)
+			if user == nil {
 				log.Printf("telegram 403: %v", c.Message().Sender)
 				return nil
 			}
 			_ = c.Notify(tele.Typing)
+			updateCtx, cancel := context.WithTimeout(models.WithUser(ctx, user), updateTimeout)
+			defer cancel()
+			c.Set(updateContextKey, updateCtx)
 			if err := next(c); err != nil {
-				_ = t.sendMessageHtml("error: " + err.Error())
+				_ = t.sendMessageHtml(updateCtx, "error: "+err.Error())
 				log.Printf("telegram ERROR: %s", err)
 			}
 			return err
 		}
 	})
 	b.Handle("/start", func(c tele.Context) error {
-		return t.sendMessageHtml(fmt.Sprintf(`<a href="%s">Welcome!</a>`, t.serverPublicUrl), t.withMainPageWebApp())
+		return t.sendMessageHtml(updateContext(c), fmt.Sprintf(`<a href="%s">Welcome!</a>`, t.serverPublicUrl), t.withMainPageWebApp())
 	})
 	b.Handle("/agenda", func(c tele.Context) error {
-		return t.TriggerAgenda()
+		return t.TriggerAgenda(updateContext(c))
+	})
+	b.Handle("/filters", func(c tele.Context) error {
+		return t.humanInput(updateContext(c), string(models.HumanActionFilters))
 	})
 	b.Handle("/help", func(c tele.Context) error {
-		return t.sendMessageHtml(models.HumanInputHelp)
+		return t.sendMessageHtml(updateContext(c), models.HumanInputHelp)
 	})
 	b.Handle(tele.OnText, func(c tele.Context) error {
-		return t.humanInput(c.Message().Text)
+		return t.humanInput(updateContext(c), c.Message().Text)
 	})
-	commands := []tele.Command{
-		{
-			Text:        "help",
-			Description: "Help",
-		},
-		{
-			Text:        "start",
-			Description: "Web app link",
-		},
-		{
-			Text:        "agenda",
-			Description: "Show agenda",
-		},
-	}
-	if err := b.SetCommands(commands); err != nil {
+	b.Handle(tele.OnDocument, func(c tele.Context) error {
+		document := c.Message().Document
+		return t.attachFile(updateContext(c), c.Message(), &document.File, document.FileName, document.MIME)
+	})
+	b.Handle(tele.OnPhoto, func(c tele.Context) error {
+		photo := c.Message().Photo
+		name := "photo_" + c.Message().Time().Format("2006-01-02_15-04-05") + ".jpg"
+		return t.attachFile(updateContext(c), c.Message(), &photo.File, name, "image/jpeg")
+	})
+	if err := b.SetCommands(botCommands); err != nil {
 		return fmt.Errorf("cant set commands: %w", err)
 	}
-
-	t.chat, err = b.ChatByID(t.userId)
-	if err != nil {
-		return fmt.Errorf("cant get chat: %w", err)
+	for _, user := range t.users {
+		if err := t.updateCommands(models.WithUser(ctx, user)); err != nil {
+			log.Printf("cant update telegram commands of %s: %s", user, err)
+		}
 	}
+
 	go func() {
 		t.bot.Start()
 		stopper <- fmt.Errorf("stop telegram")
@@ -119,6 +123,25 @@ func (t *TelegramServer) Start(ctx context.Context, stopper chan<- error) error
 	return nil
 }
 
+const (
+	updateContextKey = "update_context"
+	updateTimeout    = time.Minute
+)
+
+func updateContext(c tele.Context) context.Context {
+	if ctx, ok := c.Get(updateContextKey).(context.Context); ok {
+		return ctx
+	}
+	return context.Background()
+}
+
+func (t *TelegramServer) actor(ctx context.Context) string {
+	if user := models.UserFromContext(ctx); user != nil {
+		return user.TelegramActor()
+	}
+	return ""
+}
+
 func (t *TelegramServer) Stop() {
 	if t.cancel != nil {
 		t.cancel()